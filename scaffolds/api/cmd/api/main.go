@@ -2,37 +2,55 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/certs"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/config"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/database"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/encryption"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/health"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/lifecycle"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/metrics"
+	apiMiddleware "github.com/captechconsulting/go-microservice-templates/api/internal/middleware"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/ratelimit"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/redact"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/reporting"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/routes"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/services"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/swagger"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/telemetry"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
-	"github.com/go-chi/httplog/v2"
+	"github.com/redis/go-redis/v9"
 )
 
+// @securityDefinitions.apikey	BearerAuth
+// @in							header
+// @name						Authorization
+// @description				Bearer token issued by the configured identity provider, e.g. "Bearer <jwt>"
+// @securityDefinitions.apikey	APIKeyAuth
+// @in							header
+// @name						X-API-Key
+// @description				API key created through the admin endpoints, e.g. "ak_<prefix>_<secret>"
 func main() {
 	ctx := context.Background()
 	if err := run(ctx); err != nil {
-		log.Fatalf("Startup failed. err: %v", err)
+		log.Fatalf("Exited with error. err: %v", err)
 	}
 }
 
 // run is the main function that initializes the configuration, sets up logging, connects to the
 // database, initializes the user service, and starts the API with the necessary middleware. It
-// also has graceful stop logic implemented.It returns an error if any step in this initialization
-// process fails.
+// blocks until the process is signaled to stop, then shuts the components down gracefully, see
+// lifecycle.Manager. It returns an error if any step in this initialization process fails or the
+// components do not stop cleanly.
 func run(ctx context.Context) error {
 	// Setup
 	cfg, err := config.New()
@@ -40,101 +58,259 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("[in run]: %w", err)
 	}
 
-	logger := httplog.NewLogger("user-microservice", httplog.Options{
-		LogLevel:        cfg.LogLevel,
-		JSON:            false,
-		Concise:         true,
-		ResponseHeaders: false,
+	// sensitive attributes are masked before they are written, and every line logged with a
+	// request's context is tagged with its request ID, trace ID and route
+	// the level can be changed at runtime on the admin port, see routes.WithAdminRoutes
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(cfg.LogLevel)
+	logHandler, err := logging.NewHandler(os.Stdout, logging.Options{
+		Format:    cfg.LogFormat,
+		Level:     logLevel,
+		AddSource: cfg.LogAddSource,
 	})
+	if err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
+	redactPolicy := redact.NewPolicy(cfg.LogRedactKeys, cfg.LogRedactAllow)
+	logger := slog.New(redact.NewHandler(logHandler, redactPolicy))
+
+	// handlers and middleware log with logging.FromContext, which falls back to the default logger
+	slog.SetDefault(logger)
+
+	// components are stopped in reverse order of registration once the readiness probe fails and
+	// load balancers had SHUTDOWN_DRAIN_DELAY_SECONDS to stop routing requests to the service. If
+	// setup fails, the components registered so far are stopped.
+	app := lifecycle.New(
+		logger,
+		lifecycle.WithStopTimeout(time.Duration(cfg.ShutdownTimeout)*time.Second),
+		lifecycle.WithDrainDelay(time.Duration(cfg.ShutdownDrainDelay)*time.Second),
+	)
+	defer func() {
+		_ = app.Shutdown()
+	}()
+
+	// spans are exported as configured by TRACING_EXPORTER, see telemetry.NewTracerProvider
+	tracerProvider, err := telemetry.NewTracerProvider(ctx, cfg.TracingExporter, cfg.TracingServiceName)
+	if err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
+	// spans still buffered are flushed on shutdown
+	app.Append(lifecycle.Hook{Name: "tracer provider", OnStop: tracerProvider.Shutdown})
+
+	// panics are logged with their stack, and sent to Sentry when ERROR_REPORTING_DSN is set
+	var reporter reporting.ErrorReporter = reporting.NewLogReporter()
+	if cfg.ErrorReportingDSN != "" {
+		sentry, err := reporting.NewSentryReporter(
+			&http.Client{Timeout: time.Duration(cfg.ErrorReportingTimeout) * time.Second},
+			cfg.ErrorReportingDSN,
+			cfg.Env,
+		)
+		if err != nil {
+			return fmt.Errorf("[in run]: %w", err)
+		}
+		reporter = reporting.Multi(reporter, sentry)
+	}
 
+	// queries slower than DATABASE_SLOW_QUERY_MILLISECONDS are logged, and every query is counted
+	// in queryStats, served on the admin port
+	queryStats := database.NewQueryStats()
 	db, err := database.New(
 		ctx,
-		fmt.Sprintf(
-			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-			cfg.DBHost,
-			cfg.DBUser,
-			cfg.DBPassword,
-			cfg.DBName,
-			cfg.DBPort,
-		),
+		database.DSN{
+			Host:        cfg.DBHost,
+			Port:        cfg.DBPort,
+			Name:        cfg.DBName,
+			SSLMode:     cfg.DBSSLMode,
+			SSLRootCert: cfg.DBSSLRootCert,
+		},
+		cfg.DBCredentials,
 		logger,
 		time.Duration(cfg.DBRetryDuration)*time.Second,
+		database.WithSlowQueryThreshold(time.Duration(cfg.DBSlowQueryThreshold)*time.Millisecond),
+		database.WithQueryStats(queryStats),
 	)
 	if err != nil {
 		return fmt.Errorf("[in run]: %w", err)
 	}
-
-	defer func() {
-		if err = db.Close(); err != nil {
-			logger.Error("Error closing db connection", "err", err)
-		}
-	}()
+	app.Append(lifecycle.Hook{Name: "database", OnStop: lifecycle.Closer(db.Close)})
 
 	router := chi.NewRouter()
 
-	router.Use(httplog.RequestLogger(logger))
-	router.Use(middleware.Recoverer)
+	router.Use(apiMiddleware.Tracing(tracerProvider))
+	router.Use(apiMiddleware.RequestID())
+	router.Use(apiMiddleware.Logger(logger))
+	// with LOG_LEVEL=debug bodies are logged too, with their sensitive fields masked
+	router.Use(apiMiddleware.LogBodies())
+	router.Use(apiMiddleware.Recovery(reporter))
+	router.Use(apiMiddleware.Security(apiMiddleware.SecurityHeaders{
+		HSTSMaxAge:            time.Duration(cfg.SecurityHSTSMaxAge) * time.Second,
+		ContentSecurityPolicy: cfg.SecurityCSP,
+		ReferrerPolicy:        cfg.SecurityReferrer,
+	}))
+	// the tenant and API key headers are always allowed, as every user route needs them
 	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "PUT", "POST", "DELETE"},
-		MaxAge:         300,
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   cfg.CORSAllowedMethods,
+		AllowedHeaders:   append(cfg.CORSAllowedHeaders, cfg.APIKeyHeader),
+		ExposedHeaders:   []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "Deprecation", "Sunset", "Link", apiMiddleware.RequestIDHeader},
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
 	}))
 
-	svs := services.NewUserService(db)
-	routes.RegisterRoutes(router, logger, svs, routes.WithRegisterHealthRoute(true))
+	verifier := auth.NewVerifier(
+		auth.NewJWKS(cfg.AuthJWKSURL, time.Duration(cfg.AuthJWKSRefresh)*time.Second),
+		cfg.AuthIssuer,
+		cfg.AuthAudience,
+		time.Duration(cfg.AuthClockSkew)*time.Second,
+	)
+
+	// the readiness probe fails while the database is unreachable. Redis and the JWKS endpoint only
+	// degrade it, as rate limits fail open and verification keys are cached.
+	checks := health.New(
+		time.Duration(cfg.HealthCheckTimeout)*time.Second,
+		time.Duration(cfg.HealthCacheTTL)*time.Second,
+	)
+	checks.Register("database", health.DB(db))
+	checks.Register("jwks", health.HTTP(http.DefaultClient, cfg.AuthJWKSURL), health.NonCritical())
+	app.OnDrain(checks.Shutdown)
+
+	// validated API keys are cached, so revocations and expiry take effect within the cache TTL
+	apiKeyService := services.NewAPIKeyService(db)
+	apiKeys := auth.NewAPIKeyCache(apiKeyService, time.Duration(cfg.APIKeyCacheTTL)*time.Second)
+
+	rateLimits, err := ratelimit.ParseLimits(cfg.RateLimitDefault, cfg.RateLimitRoutes)
+	if err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
+	ipRateLimit, err := ratelimit.ParseLimit(cfg.RateLimitIP)
+	if err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
+
+	// buckets are kept in memory unless Redis is configured, in which case all instances share them
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimitRedisURL != "" {
+		redisOptions, err := redis.ParseURL(cfg.RateLimitRedisURL)
+		if err != nil {
+			return fmt.Errorf("[in run] invalid rate limit redis url: %w", err)
+		}
+		redisClient := redis.NewClient(redisOptions)
+		app.Append(lifecycle.Hook{Name: "redis", OnStop: lifecycle.Closer(redisClient.Close)})
+		rateLimitStore = ratelimit.NewRedisStore(redisClient)
+		checks.Register("redis", health.CheckerFunc(func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}), health.NonCritical())
+	}
+
+	// user names are encrypted at rest under data keys wrapped by the configured key provider
+	keys, err := encryption.NewKeyProvider(ctx, cfg.EncryptionKeyProvider, cfg.EncryptionKeyFile, cfg.EncryptionKMSKeyID)
+	if err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
+	envelope, err := encryption.NewEnvelope(keys, cfg.EncryptionIndexKey)
+	if err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
+
+	// deprecated versions of the API announce their sunset in every response
+	deprecations, err := apiMiddleware.ParseDeprecations(cfg.HTTPDeprecations, cfg.HTTPSunsets)
+	if err != nil {
+		return fmt.Errorf("[in run]: %w", err)
+	}
+
+	// metrics and the debug routes are served on the admin port when one is configured, so they are
+	// not exposed alongside the public routes. Without one, the debug routes are not served at all.
+	var adminRouter chi.Router
+	if cfg.HTTPAdminPort != "" {
+		adminRouter = chi.NewRouter()
+	}
+
+	svs := services.NewUserService(db, envelope)
+	routes.RegisterRoutes(
+		router,
+		svs,
+		routes.WithRegisterHealthRoute(checks),
+		routes.WithBasePath(cfg.HTTPBasePath),
+		routes.WithDeprecations(deprecations),
+		routes.WithVerifier(verifier),
+		routes.WithAPIKeys(apiKeys, cfg.APIKeyHeader),
+		routes.WithAPIKeyAdmin(apiKeyService),
+		// the tenant comes from the API key or a claim of the verified token, never from the
+		// request itself, so callers cannot choose their tenant
+		routes.WithTenantSources(apiMiddleware.TenantFromPrincipal(), apiMiddleware.TenantFromClaim(cfg.TenantClaim)),
+		// every IP is limited before authentication, so invalid credentials cannot flood the database
+		routes.WithIPRateLimit(rateLimitStore, ipRateLimit),
+		routes.WithRateLimit(rateLimitStore, rateLimits),
+		routes.WithMetrics(metrics.New(db), adminRouter),
+		routes.WithAdminRoutes(adminRouter, logLevel, cfg, redactPolicy),
+		routes.WithQueryStats(queryStats),
+		routes.WithBodyLimits(apiMiddleware.BodyLimits{
+			Default: cfg.HTTPMaxBodyBytes,
+			Routes:  cfg.HTTPMaxBodyRoutes,
+		}),
+	)
+
+	scheme := "http"
+	if cfg.HTTPTLSCertFile != "" {
+		scheme = "https"
+	}
 
 	if cfg.HTTPUseSwagger {
-		swagger.RunSwagger(router, logger, cfg.HTTPDomain+cfg.HTTPPort)
+		swagger.RunSwagger(router, logger, scheme, cfg.HTTPDomain+cfg.HTTPPort, cfg.HTTPBasePath)
 	}
 
 	serverInstance := &http.Server{
 		Addr:              cfg.HTTPDomain + cfg.HTTPPort,
-		IdleTimeout:       time.Minute,
-		ReadHeaderTimeout: 500 * time.Millisecond,
-		ReadTimeout:       500 * time.Millisecond,
-		WriteTimeout:      500 * time.Millisecond,
+		IdleTimeout:       time.Duration(cfg.HTTPIdleTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(cfg.HTTPReadHeaderTimeout) * time.Second,
+		ReadTimeout:       time.Duration(cfg.HTTPReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(cfg.HTTPWriteTimeout) * time.Second,
+		MaxHeaderBytes:    cfg.HTTPMaxHeaderBytes,
 		Handler:           router,
 	}
 
-	// Graceful shutdown
-	serverCtx, serverStopCtx := context.WithCancel(context.Background())
-
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-	go func() {
-		<-sig
-
-		fmt.Println()
-		logger.Info("Shutdown signal received")
-
-		shutdownCtx, err := context.WithTimeout(
-			serverCtx, time.Duration(cfg.HTTPShutdownDuration)*time.Second,
+	// certificates are reloaded when they change on disk, so they can be rotated without a restart
+	if cfg.HTTPTLSCertFile != "" {
+		reloader, err := certs.NewReloader(
+			cfg.HTTPTLSCertFile,
+			cfg.HTTPTLSKeyFile,
+			cfg.HTTPTLSClientCAFile,
+			time.Duration(cfg.HTTPTLSReload)*time.Second,
+			logger,
 		)
 		if err != nil {
-			log.Fatalf("Error creating context.WithTimeout. err: %v", err)
+			return fmt.Errorf("[in run]: %w", err)
 		}
+		serverInstance.TLSConfig = reloader.TLSConfig()
+	}
 
-		go func() {
-			<-shutdownCtx.Done()
-			if errors.Is(shutdownCtx.Err(), context.DeadlineExceeded) {
-				log.Fatal("graceful shutdown timed out.. forcing exit.")
-			}
-		}()
+	var adminServer *http.Server
+	if adminRouter != nil {
+		adminServer = &http.Server{
+			Addr:              cfg.HTTPDomain + cfg.HTTPAdminPort,
+			ReadHeaderTimeout: time.Duration(cfg.HTTPReadHeaderTimeout) * time.Second,
+			MaxHeaderBytes:    cfg.HTTPMaxHeaderBytes,
+			// CPU profiles and traces are collected for 30 seconds by default
+			WriteTimeout: 2 * time.Minute,
+			Handler:      adminRouter,
+		}
+		app.AppendServer("admin server", adminServer, func() error {
+			logger.Info(fmt.Sprintf("Admin server is listening on http://%s", adminServer.Addr))
+			return adminServer.ListenAndServe()
+		}, time.Duration(cfg.HTTPShutdownDuration)*time.Second)
+	}
 
-		if err := serverInstance.Shutdown(shutdownCtx); err != nil {
-			log.Fatalf("Error shutting down server. err: %v", err)
+	// the server is stopped first, so the admin server keeps serving metrics while it drains
+	app.AppendServer("server", serverInstance, func() error {
+		logger.Info(fmt.Sprintf("Server is listening on %s://%s", scheme, serverInstance.Addr))
+		if serverInstance.TLSConfig != nil {
+			return serverInstance.ListenAndServeTLS("", "")
 		}
-		serverStopCtx()
-	}()
+		return serverInstance.ListenAndServe()
+	}, time.Duration(cfg.HTTPShutdownDuration)*time.Second)
 
-	// Run
-	logger.Info(fmt.Sprintf("Server is listening on %s", serverInstance.Addr))
-	err = serverInstance.ListenAndServe()
-	if err != nil && !errors.Is(err, http.ErrServerClosed) {
-		return err
+	if err := app.Run(ctx); err != nil {
+		return fmt.Errorf("[in run]: %w", err)
 	}
-
-	<-serverCtx.Done()
-	logger.Info("Shutdown complete")
 	return nil
 }
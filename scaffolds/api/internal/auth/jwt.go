@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned when a token fails verification. The wrapped message describes the
+// reason and is intended for logs, not for callers.
+var ErrInvalidToken = errors.New("invalid token")
+
+type keySource interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// Verifier verifies RS256 and ES256 signed JWTs and validates their registered claims.
+type Verifier struct {
+	keys     keySource
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// NewVerifier returns a Verifier that checks signatures against keys, requires the `iss` claim to
+// equal issuer and the `aud` claim to contain audience. leeway is the allowed clock skew when
+// checking `exp` and `nbf`.
+func NewVerifier(keys keySource, issuer string, audience string, leeway time.Duration) *Verifier {
+	return &Verifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		leeway:   leeway,
+		now:      time.Now,
+	}
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify verifies the token signature and claims, and returns the claims.
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, fmt.Errorf("[in auth.Verify] malformed token: %w", ErrInvalidToken)
+	}
+
+	// header
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, fmt.Errorf("[in auth.Verify] header: %v: %w", err, ErrInvalidToken)
+	}
+
+	// signature
+	key, err := v.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return Claims{}, fmt.Errorf("[in auth.Verify] %v: %w", err, ErrInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, fmt.Errorf("[in auth.Verify] signature: %v: %w", err, ErrInvalidToken)
+	}
+	if err = verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return Claims{}, fmt.Errorf("[in auth.Verify] %v: %w", err, ErrInvalidToken)
+	}
+
+	// claims
+	var raw map[string]any
+	if err = decodeSegment(parts[1], &raw); err != nil {
+		return Claims{}, fmt.Errorf("[in auth.Verify] payload: %v: %w", err, ErrInvalidToken)
+	}
+	claims, err := NewClaims(raw)
+	if err != nil {
+		return Claims{}, fmt.Errorf("[in auth.Verify] %v: %w", err, ErrInvalidToken)
+	}
+	if err = v.validate(claims); err != nil {
+		return Claims{}, fmt.Errorf("[in auth.Verify] %v: %w", err, ErrInvalidToken)
+	}
+
+	return claims, nil
+}
+
+// validate checks the registered claims against the verifier configuration.
+func (v *Verifier) validate(claims Claims) error {
+	now := v.now()
+
+	if claims.ExpiresAt.IsZero() {
+		return errors.New("missing exp claim")
+	}
+	if !now.Before(claims.ExpiresAt.Add(v.leeway)) {
+		return fmt.Errorf("token expired at %s", claims.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if !claims.NotBefore.IsZero() && now.Add(v.leeway).Before(claims.NotBefore) {
+		return fmt.Errorf("token not valid before %s", claims.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if claims.Issuer != v.issuer {
+		return fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if !slices.Contains(claims.Audience, v.audience) {
+		return fmt.Errorf("audience %v does not contain %q", claims.Audience, v.audience)
+	}
+
+	return nil
+}
+
+// verifySignature checks signature over signingInput. Only asymmetric algorithms are accepted,
+// and the key type must match the algorithm to prevent algorithm confusion.
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("RS256 token signed with non RSA key")
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("ES256 token signed with non EC key")
+		}
+		if len(signature) != 64 {
+			return errors.New("ES256 signature must be 64 bytes")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, digest[:], r, s) {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported alg %q", alg)
+	}
+}
+
+// decodeSegment base64url decodes a JWT segment and unmarshals the JSON into v. Numbers are kept
+// as json.Number so large claim values are not rounded.
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("decode base64: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(v); err != nil {
+		return fmt.Errorf("decode json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyAuthorize(t *testing.T) {
+	claims := func(raw map[string]any) context.Context {
+		c, err := NewClaims(raw)
+		if err != nil {
+			t.Fatalf("building claims: %v", err)
+		}
+		return WithClaims(context.Background(), c)
+	}
+
+	employee := claims(map[string]any{
+		"roles":   []any{"Employee"},
+		"scope":   "users:read users:write",
+		"user_id": json.Number("1002"),
+	})
+	customer := claims(map[string]any{
+		"roles":   []any{"Customer"},
+		"scope":   "users:read",
+		"user_id": json.Number("1001"),
+	})
+	customerStringID := claims(map[string]any{
+		"roles":   []any{"Customer"},
+		"scope":   "users:read",
+		"user_id": "1001",
+	})
+	customerNoID := claims(map[string]any{
+		"roles": []any{"Customer"},
+		"scope": "users:read",
+	})
+	noRoles := claims(map[string]any{
+		"scope":   "users:read users:write",
+		"user_id": json.Number("1001"),
+	})
+
+	listUsers := Policy{Roles: []string{"Employee"}, Scopes: []string{"users:read"}}
+	getUser := Policy{
+		Roles:      []string{"Employee"},
+		Scopes:     []string{"users:read"},
+		OwnerRoles: []string{"Customer"},
+		OwnerParam: "ID",
+	}
+	updateUser := Policy{Roles: []string{"Employee"}, Scopes: []string{"users:write"}}
+	scopeOnly := Policy{Scopes: []string{"users:read"}}
+
+	// row 7 is owned by user 1001, row 8 by user 1002
+	errLookup := errors.New("db down")
+	lookup := getUser
+	lookup.Owner = func(ctx context.Context, resourceID string) (string, error) {
+		switch resourceID {
+		case "7":
+			return "1001", nil
+		case "8":
+			return "1002", nil
+		case "9":
+			return "", errLookup
+		default:
+			return "", nil
+		}
+	}
+
+	tests := map[string]struct {
+		policy      Policy
+		ctx         context.Context
+		owner       string
+		expectedErr error
+	}{
+		"no claims": {
+			policy:      listUsers,
+			ctx:         context.Background(),
+			expectedErr: ErrUnauthenticated,
+		},
+		"employee lists users": {
+			policy: listUsers,
+			ctx:    employee,
+		},
+		"customer lists users": {
+			policy:      listUsers,
+			ctx:         customer,
+			expectedErr: ErrForbidden,
+		},
+		"employee reads other user": {
+			policy: getUser,
+			ctx:    employee,
+			owner:  "1001",
+		},
+		"customer reads self": {
+			policy: getUser,
+			ctx:    customer,
+			owner:  "1001",
+		},
+		"customer reads self with string claim": {
+			policy: getUser,
+			ctx:    customerStringID,
+			owner:  "1001",
+		},
+		"customer reads other user": {
+			policy:      getUser,
+			ctx:         customer,
+			owner:       "1002",
+			expectedErr: ErrForbidden,
+		},
+		"customer without user_id claim": {
+			policy:      getUser,
+			ctx:         customerNoID,
+			owner:       "1001",
+			expectedErr: ErrForbidden,
+		},
+		"customer without owner": {
+			policy:      getUser,
+			ctx:         customerNoID,
+			owner:       "",
+			expectedErr: ErrForbidden,
+		},
+		"customer reads own row": {
+			policy: lookup,
+			ctx:    customer,
+			owner:  "7",
+		},
+		"customer reads other row": {
+			policy:      lookup,
+			ctx:         customer,
+			owner:       "8",
+			expectedErr: ErrForbidden,
+		},
+		"customer reads missing row": {
+			policy:      lookup,
+			ctx:         customer,
+			owner:       "10",
+			expectedErr: ErrForbidden,
+		},
+		"owner lookup fails": {
+			policy:      lookup,
+			ctx:         customer,
+			owner:       "9",
+			expectedErr: errLookup,
+		},
+		"employee skips owner lookup": {
+			policy: lookup,
+			ctx:    employee,
+			owner:  "9",
+		},
+		"caller without roles reads self": {
+			policy:      getUser,
+			ctx:         noRoles,
+			owner:       "1001",
+			expectedErr: ErrForbidden,
+		},
+		"employee updates user": {
+			policy: updateUser,
+			ctx:    employee,
+			owner:  "1001",
+		},
+		"customer updates self": {
+			policy:      updateUser,
+			ctx:         customer,
+			owner:       "1001",
+			expectedErr: ErrForbidden,
+		},
+		"employee missing scope": {
+			policy:      Policy{Roles: []string{"Employee"}, Scopes: []string{"users:delete"}},
+			ctx:         employee,
+			expectedErr: ErrForbidden,
+		},
+		"scope only policy": {
+			policy: scopeOnly,
+			ctx:    noRoles,
+		},
+		"empty policy": {
+			policy: Policy{},
+			ctx:    noRoles,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.policy.Authorize(tc.ctx, tc.owner)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tc.expectedErr), "expected %v, got %v", tc.expectedErr, err)
+		})
+	}
+}
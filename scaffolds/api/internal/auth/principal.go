@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// Methods a Principal can have been authenticated with.
+const (
+	MethodBearer = "bearer"
+	MethodAPIKey = "api_key"
+)
+
+// Principal is the authenticated caller of a request. ExpiresAt is when its credential stops
+// authenticating, it is zero if the credential does not expire or its expiry is unknown.
+type Principal struct {
+	Subject   string
+	UserID    string
+	Tenant    string
+	Roles     []string
+	Scopes    []string
+	Method    string
+	KeyPrefix string
+	ExpiresAt time.Time
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx that carries the principal.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the caller of the request and whether one is known. The principal
+// is the one stored with WithPrincipal or, failing that, is derived from the verified claims in
+// ctx. A principal derived from claims has no tenant, see the tenant package instead.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	if principal, ok := ctx.Value(principalKey{}).(Principal); ok {
+		return principal, true
+	}
+
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return Principal{}, false
+	}
+	return Principal{
+		Subject: claims.Subject,
+		UserID:  claims.String(OwnerClaim),
+		Roles:   claims.Roles,
+		Scopes:  claims.Scopes,
+		Method:  MethodBearer,
+	}, true
+}
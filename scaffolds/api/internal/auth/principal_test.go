@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalFromContext(t *testing.T) {
+	claims, err := NewClaims(map[string]any{
+		"sub":     "user-1",
+		"roles":   []any{"Customer"},
+		"scope":   "users:read",
+		"user_id": json.Number("1001"),
+	})
+	assert.NoError(t, err)
+
+	stored := Principal{Subject: "svc-reporting", Tenant: "tenant-b", Method: MethodAPIKey}
+
+	tests := map[string]struct {
+		ctx        context.Context
+		expected   Principal
+		expectedOK bool
+	}{
+		"stored principal": {
+			ctx:        WithPrincipal(context.Background(), stored),
+			expected:   stored,
+			expectedOK: true,
+		},
+		"stored principal wins over claims": {
+			ctx:        WithPrincipal(WithClaims(context.Background(), claims), stored),
+			expected:   stored,
+			expectedOK: true,
+		},
+		"derived from claims": {
+			ctx: WithClaims(context.Background(), claims),
+			expected: Principal{
+				Subject: "user-1",
+				UserID:  "1001",
+				Roles:   []string{"Customer"},
+				Scopes:  []string{"users:read"},
+				Method:  MethodBearer,
+			},
+			expectedOK: true,
+		},
+		"nothing in context": {
+			ctx:        context.Background(),
+			expectedOK: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := PrincipalFromContext(tc.ctx)
+
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
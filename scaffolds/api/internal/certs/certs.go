@@ -0,0 +1,190 @@
+// Package certs loads the TLS certificates of the server and reloads them when they change on
+// disk, so rotated certificates are picked up without a restart.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader holds the server certificate and, for mutual TLS, the CA bundle client certificates
+// are verified against. It checks the files for changes at most once per interval, during TLS
+// handshakes, and keeps serving the previous certificates if a reload fails.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	interval     time.Duration
+	logger       *slog.Logger
+	now          func() time.Time
+
+	mu        sync.Mutex
+	checked   time.Time
+	modTimes  []time.Time
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// NewReloader loads the certificate and key in certFile and keyFile, and the PEM encoded CA bundle
+// in clientCAFile unless it is empty.
+func NewReloader(certFile string, keyFile string, clientCAFile string, interval time.Duration, logger *slog.Logger) (*Reloader, error) {
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		interval:     interval,
+		logger:       logger,
+		now:          time.Now,
+	}
+
+	modTimes, err := r.modTimesOf()
+	if err != nil {
+		return nil, fmt.Errorf("[in certs.NewReloader]: %w", err)
+	}
+	if err := r.load(modTimes); err != nil {
+		return nil, fmt.Errorf("[in certs.NewReloader]: %w", err)
+	}
+	r.checked = r.now()
+
+	return r, nil
+}
+
+// TLSConfig returns a server TLS config that serves the current certificate. Client certificates
+// are required and verified against the current CA bundle if one was given. Only the certificate
+// and its verification are resolved per handshake, so settings added to the config later, like the
+// ALPN protocols http.Server sets for HTTP/2, apply to every connection.
+func (r *Reloader) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			return cert, nil
+		},
+	}
+	if r.clientCAFile != "" {
+		// the chain is verified by verifyClient, as ClientCAs could not follow reloads
+		config.ClientAuth = tls.RequireAnyClientCert
+		config.VerifyPeerCertificate = r.verifyClient
+	}
+	return config
+}
+
+// verifyClient verifies the client certificate chain in rawCerts against the current client CA
+// bundle.
+func (r *Reloader) verifyClient(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse client certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return errors.New("no client certificate")
+	}
+
+	_, clientCAs := r.current()
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         clientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify client certificate: %w", err)
+	}
+	return nil
+}
+
+// current returns the current certificates, reloading them first if the interval has passed and
+// the files changed.
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.checked) < r.interval {
+		return r.cert, r.clientCAs
+	}
+	r.checked = now
+
+	modTimes, err := r.modTimesOf()
+	if err != nil {
+		r.logger.Error("Failed to check TLS certificates for changes", "err", err)
+		return r.cert, r.clientCAs
+	}
+	if !changed(r.modTimes, modTimes) {
+		return r.cert, r.clientCAs
+	}
+
+	if err := r.load(modTimes); err != nil {
+		r.logger.Error("Failed to reload TLS certificates, serving previous ones", "err", err)
+		return r.cert, r.clientCAs
+	}
+	r.logger.Info("Reloaded TLS certificates", "cert", r.certFile)
+
+	return r.cert, r.clientCAs
+}
+
+// load reads the certificates from disk and records modTimes as their modification times.
+func (r *Reloader) load(modTimes []time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		bundle, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA bundle: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(bundle) {
+			return errors.New("client CA bundle contains no certificates")
+		}
+	}
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return nil
+}
+
+// modTimesOf returns the modification times of the certificate files.
+func (r *Reloader) modTimesOf() ([]time.Time, error) {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+
+	modTimes := make([]time.Time, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+	return modTimes, nil
+}
+
+// changed reports whether any modification time differs.
+func changed(before []time.Time, after []time.Time) bool {
+	for i := range after {
+		if !before[i].Equal(after[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,276 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fatalIf stops the test if err is not nil.
+func fatalIf(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testCert is a certificate generated for a test, signed by parent or self-signed.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	fatalIf(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	fatalIf(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	fatalIf(t, err)
+	cert, err := x509.ParseCertificate(der)
+	fatalIf(t, err)
+
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// write writes the certificate and key as PEM files into dir and returns their paths.
+func (c *testCert) write(t *testing.T, dir string, name string) (string, string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	fatalIf(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	fatalIf(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))
+	fatalIf(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// touch moves the modification time of files forward, so changes are seen even on file systems
+// with coarse timestamps.
+func touch(t *testing.T, at time.Time, files ...string) {
+	t.Helper()
+	for _, file := range files {
+		fatalIf(t, os.Chtimes(file, at, at))
+	}
+}
+
+// servedCommonName returns the common name of the certificate the config serves.
+func servedCommonName(t *testing.T, config *tls.Config) string {
+	t.Helper()
+
+	cert, err := config.GetCertificate(&tls.ClientHelloInfo{})
+	fatalIf(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	fatalIf(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ca := newTestCert(t, "test-ca", nil, true)
+
+	t.Run("reloads changed certificates after the interval", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := newTestCert(t, "first", ca, false).write(t, dir, "server")
+
+		reloader, err := NewReloader(certFile, keyFile, "", time.Minute, logger)
+		fatalIf(t, err)
+		now := time.Now()
+		reloader.now = func() time.Time { return now }
+		config := reloader.TLSConfig()
+		assert.Equal(t, "first", servedCommonName(t, config))
+
+		newTestCert(t, "second", ca, false).write(t, dir, "server")
+		touch(t, now.Add(time.Hour), certFile, keyFile)
+		assert.Equal(t, "first", servedCommonName(t, config), "reloaded before the interval passed")
+
+		now = now.Add(2 * time.Minute)
+		assert.Equal(t, "second", servedCommonName(t, config))
+	})
+
+	t.Run("keeps serving previous certificates if reload fails", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := newTestCert(t, "first", ca, false).write(t, dir, "server")
+
+		reloader, err := NewReloader(certFile, keyFile, "", 0, logger)
+		fatalIf(t, err)
+
+		fatalIf(t, os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+		touch(t, time.Now().Add(time.Hour), certFile)
+		assert.Equal(t, "first", servedCommonName(t, reloader.TLSConfig()))
+	})
+
+	t.Run("invalid files rejected at startup", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := newTestCert(t, "server", ca, false).write(t, dir, "server")
+		emptyCA := filepath.Join(dir, "empty.pem")
+		fatalIf(t, os.WriteFile(emptyCA, nil, 0o600))
+
+		_, err := NewReloader(filepath.Join(dir, "missing.crt"), keyFile, "", time.Minute, logger)
+		assert.Error(t, err)
+		_, err = NewReloader(certFile, keyFile, emptyCA, time.Minute, logger)
+		assert.Error(t, err)
+	})
+}
+
+func TestReloaderMutualTLS(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+
+	ca := newTestCert(t, "test-ca", nil, true)
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := newTestCert(t, "localhost", ca, false).write(t, dir, "server")
+
+	reloader, err := NewReloader(certFile, keyFile, caFile, time.Minute, logger)
+	fatalIf(t, err)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", reloader.TLSConfig())
+	fatalIf(t, err)
+	server := &http.Server{
+		Handler:           http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }),
+		ReadHeaderTimeout: time.Second,
+	}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	tests := map[string]struct {
+		clientCerts []tls.Certificate
+		expectedErr bool
+	}{
+		"client certificate signed by the CA": {
+			clientCerts: []tls.Certificate{newTestCert(t, "client", ca, false).tlsCertificate()},
+			expectedErr: false,
+		},
+		"no client certificate": {
+			clientCerts: nil,
+			expectedErr: true,
+		},
+		"client certificate from another CA": {
+			clientCerts: []tls.Certificate{newTestCert(t, "client", newTestCert(t, "other-ca", nil, true), false).tlsCertificate()},
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &http.Client{
+				Timeout: 5 * time.Second,
+				Transport: &http.Transport{TLSClientConfig: &tls.Config{
+					RootCAs:      roots,
+					Certificates: tc.clientCerts,
+				}},
+			}
+
+			response, err := client.Get("https://" + listener.Addr().String())
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			fatalIf(t, err)
+			_ = response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		})
+	}
+}
+
+func TestReloaderHTTP2(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+
+	ca := newTestCert(t, "test-ca", nil, true)
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := newTestCert(t, "localhost", ca, false).write(t, dir, "server")
+
+	tests := map[string]struct {
+		clientCAFile string
+		clientCerts  []tls.Certificate
+	}{
+		"server certificate only": {},
+		"mutual tls": {
+			clientCAFile: caFile,
+			clientCerts:  []tls.Certificate{newTestCert(t, "client", ca, false).tlsCertificate()},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			reloader, err := NewReloader(certFile, keyFile, tc.clientCAFile, time.Minute, logger)
+			fatalIf(t, err)
+
+			// like main, the server is given the config and serves TLS with ListenAndServeTLS, which
+			// adds the h2 ALPN protocol to it
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			fatalIf(t, err)
+			server := &http.Server{
+				Handler:           http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }),
+				ReadHeaderTimeout: time.Second,
+				TLSConfig:         reloader.TLSConfig(),
+			}
+			go func() { _ = server.ServeTLS(listener, "", "") }()
+			t.Cleanup(func() { _ = server.Close() })
+
+			roots := x509.NewCertPool()
+			roots.AddCert(ca.cert)
+			client := &http.Client{
+				Timeout: 5 * time.Second,
+				Transport: &http.Transport{
+					ForceAttemptHTTP2: true,
+					TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: tc.clientCerts},
+				},
+			}
+
+			response, err := client.Get("https://" + listener.Addr().String())
+			fatalIf(t, err)
+			_ = response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(t, "HTTP/2.0", response.Proto)
+			assert.Equal(t, "h2", response.TLS.NegotiatedProtocol)
+		})
+	}
+}
@@ -1,9 +1,18 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
 )
@@ -11,29 +20,152 @@ import (
 // Configuration holds the application configuration settings. The configuration is loaded from
 // environment variables.
 type Configuration struct {
-	Env                  string     `env:"ENV,required,required"`
-	LogLevel             slog.Level `env:"LOG_LEVEL,required,required"`
-	DBName               string     `env:"DATABASE_NAME,required"`
-	DBUser               string     `env:"DATABASE_USER,required"`
-	DBPassword           string     `env:"DATABASE_PASSWORD,required"`
-	DBHost               string     `env:"DATABASE_HOST,required"`
-	DBPort               string     `env:"DATABASE_PORT,required"`
-	DBRetryDuration      int        `env:"DATABASE_RETRY_DURATION_SECONDS,required"`
-	HTTPPort             string     `env:"HTTP_PORT,required"`
-	HTTPDomain           string     `env:"HTTP_DOMAIN,required"`
-	HTTPUseSwagger       bool       `env:"HTTP_USE_SWAGGER,required"`
-	HTTPShutdownDuration int        `env:"HTTP_SHUTDOWN_DURATION,required"`
+	Env                   string            `env:"ENV,required,required"`
+	LogLevel              slog.Level        `env:"LOG_LEVEL,required,required"`
+	LogFormat             string            `env:"LOG_FORMAT" envDefault:"json"`
+	LogAddSource          bool              `env:"LOG_ADD_SOURCE" envDefault:"false"`
+	LogRedactKeys         []string          `env:"LOG_REDACT_KEYS" envDefault:"password,secret,token,authorization,cookie,api_key,first_name,last_name"`
+	LogRedactAllow        []string          `env:"LOG_REDACT_ALLOW"`
+	TracingExporter       string            `env:"TRACING_EXPORTER" envDefault:"none"`
+	TracingServiceName    string            `env:"TRACING_SERVICE_NAME" envDefault:"user-microservice"`
+	ErrorReportingDSN     string            `env:"ERROR_REPORTING_DSN" log:"sensitive"`
+	ErrorReportingTimeout int               `env:"ERROR_REPORTING_TIMEOUT_SECONDS" envDefault:"2"`
+	DBName                string            `env:"DATABASE_NAME,required"`
+	DBUser                string            `env:"DATABASE_USER,required"`
+	DBPassword            string            `env:"DATABASE_PASSWORD,required" log:"sensitive"`
+	DBHost                string            `env:"DATABASE_HOST,required"`
+	DBPort                string            `env:"DATABASE_PORT,required"`
+	DBRetryDuration       int               `env:"DATABASE_RETRY_DURATION_SECONDS,required"`
+	DBSlowQueryThreshold  int               `env:"DATABASE_SLOW_QUERY_MILLISECONDS" envDefault:"200"`
+	DBSSLMode             string            `env:"DATABASE_SSL_MODE" envDefault:"disable"`
+	DBSSLRootCert         string            `env:"DATABASE_SSL_ROOT_CERT"`
+	HTTPPort              string            `env:"HTTP_PORT,required"`
+	HTTPDomain            string            `env:"HTTP_DOMAIN,required"`
+	HTTPAdminPort         string            `env:"HTTP_ADMIN_PORT"`
+	HTTPUseSwagger        bool              `env:"HTTP_USE_SWAGGER,required"`
+	HTTPBasePath          string            `env:"HTTP_BASE_PATH"`
+	HTTPDeprecations      map[string]string `env:"HTTP_DEPRECATED_VERSIONS" envKeyValSeparator:"="`
+	HTTPSunsets           map[string]string `env:"HTTP_SUNSET_VERSIONS" envKeyValSeparator:"="`
+	HTTPShutdownDuration  int               `env:"HTTP_SHUTDOWN_DURATION,required"`
+	ShutdownDrainDelay    int               `env:"SHUTDOWN_DRAIN_DELAY_SECONDS" envDefault:"5"`
+	ShutdownTimeout       int               `env:"SHUTDOWN_TIMEOUT_SECONDS" envDefault:"5"`
+	HTTPTLSCertFile       string            `env:"HTTP_TLS_CERT_FILE"`
+	HTTPTLSKeyFile        string            `env:"HTTP_TLS_KEY_FILE"`
+	HTTPTLSClientCAFile   string            `env:"HTTP_TLS_CLIENT_CA_FILE"`
+	HTTPTLSReload         int               `env:"HTTP_TLS_RELOAD_SECONDS" envDefault:"10"`
+	HTTPReadTimeout       int               `env:"HTTP_READ_TIMEOUT_SECONDS" envDefault:"15"`
+	HTTPReadHeaderTimeout int               `env:"HTTP_READ_HEADER_TIMEOUT_SECONDS" envDefault:"5"`
+	HTTPWriteTimeout      int               `env:"HTTP_WRITE_TIMEOUT_SECONDS" envDefault:"30"`
+	HTTPIdleTimeout       int               `env:"HTTP_IDLE_TIMEOUT_SECONDS" envDefault:"120"`
+	HTTPMaxHeaderBytes    int               `env:"HTTP_MAX_HEADER_BYTES" envDefault:"1048576"`
+	HTTPMaxBodyBytes      int64             `env:"HTTP_MAX_BODY_BYTES" envDefault:"1048576"`
+	HTTPMaxBodyRoutes     map[string]int64  `env:"HTTP_MAX_BODY_BYTES_ROUTES" envKeyValSeparator:"="`
+	HealthCheckTimeout    int               `env:"HEALTH_CHECK_TIMEOUT_SECONDS" envDefault:"2"`
+	HealthCacheTTL        int               `env:"HEALTH_CACHE_TTL_SECONDS" envDefault:"5"`
+	TenantClaim           string            `env:"TENANT_CLAIM" envDefault:"tenant_id"`
+	AuthIssuer            string            `env:"AUTH_ISSUER,required"`
+	AuthAudience          string            `env:"AUTH_AUDIENCE,required"`
+	AuthJWKSURL           string            `env:"AUTH_JWKS_URL,required"`
+	AuthJWKSRefresh       int               `env:"AUTH_JWKS_REFRESH_SECONDS" envDefault:"900"`
+	AuthClockSkew         int               `env:"AUTH_CLOCK_SKEW_SECONDS" envDefault:"30"`
+	APIKeyHeader          string            `env:"API_KEY_HEADER" envDefault:"X-API-Key"`
+	APIKeyCacheTTL        int               `env:"API_KEY_CACHE_TTL_SECONDS" envDefault:"60"`
+	RateLimitDefault      string            `env:"RATE_LIMIT_DEFAULT" envDefault:"100/1m"`
+	RateLimitRoutes       map[string]string `env:"RATE_LIMIT_ROUTES" envKeyValSeparator:"="`
+	RateLimitIP           string            `env:"RATE_LIMIT_IP" envDefault:"1000/1m"`
+	RateLimitRedisURL     string            `env:"RATE_LIMIT_REDIS_URL" log:"sensitive"`
+	CORSAllowedOrigins    []string          `env:"CORS_ALLOWED_ORIGINS" envDefault:"*"`
+	CORSAllowedMethods    []string          `env:"CORS_ALLOWED_METHODS" envDefault:"GET,PUT,POST,DELETE"`
+	CORSAllowedHeaders    []string          `env:"CORS_ALLOWED_HEADERS" envDefault:"Accept,Authorization,Content-Type"`
+	CORSAllowCredentials  bool              `env:"CORS_ALLOW_CREDENTIALS" envDefault:"false"`
+	CORSMaxAge            int               `env:"CORS_MAX_AGE_SECONDS" envDefault:"300"`
+	SecurityHSTSMaxAge    int               `env:"SECURITY_HSTS_MAX_AGE_SECONDS" envDefault:"31536000"`
+	SecurityCSP           string            `env:"SECURITY_CSP" envDefault:"default-src 'none'; frame-ancestors 'none'"`
+	SecurityReferrer      string            `env:"SECURITY_REFERRER_POLICY" envDefault:"no-referrer"`
+	EncryptionKeyProvider string            `env:"ENCRYPTION_KEY_PROVIDER" envDefault:"file"`
+	EncryptionKeyFile     string            `env:"ENCRYPTION_KEY_FILE" envDefault:"keys.local.json"`
+	EncryptionKMSKeyID    string            `env:"ENCRYPTION_KMS_KEY_ID"`
+	EncryptionIndexKey    string            `env:"ENCRYPTION_INDEX_KEY" log:"sensitive"`
+
+	// DBCredentials resolves DBUser and DBPassword again when the database rejects them, so
+	// rotated secrets are picked up. It is set by New.
+	DBCredentials *DBCredentials `env:"-" json:"-"`
+}
+
+// secretSettings selects the SecretProvider secret references are resolved with. They are read
+// before, and cannot themselves be, secret references.
+type secretSettings struct {
+	Provider string `env:"SECRETS_PROVIDER"`
+	File     string `env:"SECRETS_FILE" envDefault:"secrets.local.json"`
+	CacheTTL int    `env:"SECRETS_CACHE_TTL_SECONDS" envDefault:"300"`
 }
 
 // New loads the configuration settings from environment variables and .env file, and returns a
-// Configuration struct.
+// Configuration struct. Values of the form `secret://<name>#<key>` are resolved, see
+// ResolveSecret, with the provider named by `SECRETS_PROVIDER`: `secretsmanager`, `ssm` or `file`.
 func New() (Configuration, error) {
 	_ = godotenv.Load()
+	ctx := context.Background()
 
-	cfg, err := env.ParseAs[Configuration]()
+	settings, err := env.ParseAs[secretSettings]()
+	if err != nil {
+		return Configuration{}, fmt.Errorf("[in config.New] failed to parse secret settings: %w", err)
+	}
+	provider, err := newSecretProvider(ctx, settings)
+	if err != nil {
+		return Configuration{}, fmt.Errorf("[in config.New]: %w", err)
+	}
+	rawEnvironment := env.ToMap(os.Environ())
+	environment, err := resolveSecrets(ctx, provider, rawEnvironment)
+	if err != nil {
+		return Configuration{}, fmt.Errorf("[in config.New] failed to resolve secrets: %w", err)
+	}
+
+	cfg, err := env.ParseAsWithOptions[Configuration](env.Options{Environment: environment})
 	if err != nil {
 		return Configuration{}, fmt.Errorf("[in config.New] failed to parse config: %w", err)
 	}
 
+	if (cfg.HTTPTLSCertFile == "") != (cfg.HTTPTLSKeyFile == "") {
+		return Configuration{}, errors.New("[in config.New] HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE must be set together")
+	}
+	if cfg.HTTPTLSClientCAFile != "" && cfg.HTTPTLSCertFile == "" {
+		return Configuration{}, errors.New("[in config.New] HTTP_TLS_CLIENT_CA_FILE requires HTTP_TLS_CERT_FILE")
+	}
+
+	if cfg.HTTPBasePath != "" && (!strings.HasPrefix(cfg.HTTPBasePath, "/") || strings.HasSuffix(cfg.HTTPBasePath, "/")) {
+		return Configuration{}, errors.New("[in config.New] HTTP_BASE_PATH must start with a / and not end with one")
+	}
+	if cfg.CORSAllowCredentials && slices.Contains(cfg.CORSAllowedOrigins, "*") {
+		return Configuration{}, errors.New("[in config.New] CORS_ALLOW_CREDENTIALS cannot be set when CORS_ALLOWED_ORIGINS allows any origin")
+	}
+
+	cfg.DBCredentials = NewDBCredentials(provider, rawEnvironment["DATABASE_USER"], rawEnvironment["DATABASE_PASSWORD"])
+
 	return cfg, nil
 }
+
+// newSecretProvider returns the cached provider selected by settings, or nil if none is selected.
+func newSecretProvider(ctx context.Context, settings secretSettings) (SecretProvider, error) {
+	var provider SecretProvider
+	switch settings.Provider {
+	case "":
+		return nil, nil
+	case "file":
+		provider = NewFileSecretProvider(settings.File)
+	case "secretsmanager", "ssm":
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("[in config.newSecretProvider] failed to load AWS config: %w", err)
+		}
+		if settings.Provider == "ssm" {
+			provider = NewSSMProvider(ssm.NewFromConfig(awsCfg))
+		} else {
+			provider = NewSecretsManagerProvider(secretsmanager.NewFromConfig(awsCfg))
+		}
+	default:
+		return nil, fmt.Errorf("[in config.newSecretProvider] unknown secret provider %q", settings.Provider)
+	}
+
+	return NewSecretCache(provider, time.Duration(settings.CacheTTL)*time.Second), nil
+}
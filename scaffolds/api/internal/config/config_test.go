@@ -3,12 +3,19 @@ package config
 import (
 	"log/slog"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNewConfiguration(t *testing.T) {
+	secretsFile := filepath.Join(t.TempDir(), "secrets.json")
+	err := os.WriteFile(secretsFile, []byte(`{"test/db": {"username": "test_user", "password": "test_password"}}`), 0o600)
+	if err != nil {
+		t.Fatalf("writing secrets file: %v", err)
+	}
+
 	tests := map[string]struct {
 		envVars       map[string]string
 		expectedCfg   Configuration
@@ -24,19 +31,252 @@ func TestNewConfiguration(t *testing.T) {
 				"DATABASE_HOST":                   "localhost",
 				"DATABASE_PORT":                   "5432",
 				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"HTTP_PORT":                       ":8080",
+				"HTTP_DOMAIN":                     "localhost",
+				"HTTP_USE_SWAGGER":                "true",
+				"HTTP_SHUTDOWN_DURATION":          "10",
+				"AUTH_ISSUER":                     "https://issuer.test",
+				"AUTH_AUDIENCE":                   "users-api",
+				"AUTH_JWKS_URL":                   "https://issuer.test/.well-known/jwks.json",
+				"RATE_LIMIT_ROUTES":               "GET /lambda/user=10/1s,PUT /lambda/user/{ID}=5/1m",
+				"CORS_ALLOWED_ORIGINS":            "https://app.example.com,https://admin.example.com",
+				"CORS_ALLOW_CREDENTIALS":          "true",
+				"DATABASE_SSL_MODE":               "verify-full",
+				"DATABASE_SSL_ROOT_CERT":          "/etc/ssl/db-ca.pem",
+				"HTTP_TLS_CERT_FILE":              "/etc/tls/server.crt",
+				"HTTP_TLS_KEY_FILE":               "/etc/tls/server.key",
+				"HTTP_TLS_CLIENT_CA_FILE":         "/etc/tls/clients-ca.pem",
 			},
 			expectedCfg: Configuration{
-				Env:             "development",
-				LogLevel:        slog.LevelInfo,
-				DBName:          "test_db",
-				DBUser:          "test_user",
-				DBPassword:      "test_password",
-				DBHost:          "localhost",
-				DBPort:          "5432",
-				DBRetryDuration: 10,
+				Env:                   "development",
+				LogLevel:              slog.LevelInfo,
+				LogFormat:             "json",
+				LogRedactKeys:         []string{"password", "secret", "token", "authorization", "cookie", "api_key", "first_name", "last_name"},
+				TracingExporter:       "none",
+				TracingServiceName:    "user-microservice",
+				ErrorReportingTimeout: 2,
+				DBName:                "test_db",
+				DBUser:                "test_user",
+				DBPassword:            "test_password",
+				DBHost:                "localhost",
+				DBPort:                "5432",
+				DBRetryDuration:       10,
+				DBSlowQueryThreshold:  200,
+				DBSSLMode:             "verify-full",
+				DBSSLRootCert:         "/etc/ssl/db-ca.pem",
+				HTTPPort:              ":8080",
+				HTTPDomain:            "localhost",
+				HTTPUseSwagger:        true,
+				HTTPShutdownDuration:  10,
+				HTTPTLSCertFile:       "/etc/tls/server.crt",
+				HTTPTLSKeyFile:        "/etc/tls/server.key",
+				HTTPTLSClientCAFile:   "/etc/tls/clients-ca.pem",
+				HTTPTLSReload:         10,
+				ShutdownDrainDelay:    5,
+				ShutdownTimeout:       5,
+				HTTPReadTimeout:       15,
+				HTTPReadHeaderTimeout: 5,
+				HTTPWriteTimeout:      30,
+				HTTPIdleTimeout:       120,
+				HTTPMaxHeaderBytes:    1048576,
+				HTTPMaxBodyBytes:      1048576,
+				HealthCheckTimeout:    2,
+				HealthCacheTTL:        5,
+				TenantClaim:           "tenant_id",
+				AuthIssuer:            "https://issuer.test",
+				AuthAudience:          "users-api",
+				AuthJWKSURL:           "https://issuer.test/.well-known/jwks.json",
+				AuthJWKSRefresh:       900,
+				AuthClockSkew:         30,
+				APIKeyHeader:          "X-API-Key",
+				APIKeyCacheTTL:        60,
+				RateLimitDefault:      "100/1m",
+				RateLimitIP:           "1000/1m",
+				RateLimitRoutes: map[string]string{
+					"GET /lambda/user":      "10/1s",
+					"PUT /lambda/user/{ID}": "5/1m",
+				},
+				CORSAllowedOrigins:    []string{"https://app.example.com", "https://admin.example.com"},
+				CORSAllowedMethods:    []string{"GET", "PUT", "POST", "DELETE"},
+				CORSAllowedHeaders:    []string{"Accept", "Authorization", "Content-Type"},
+				CORSAllowCredentials:  true,
+				CORSMaxAge:            300,
+				SecurityHSTSMaxAge:    31536000,
+				SecurityCSP:           "default-src 'none'; frame-ancestors 'none'",
+				SecurityReferrer:      "no-referrer",
+				EncryptionKeyProvider: "file",
+				EncryptionKeyFile:     "keys.local.json",
 			},
 			expectedError: false,
 		},
+		"secret references resolved": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "secret://test/db#username",
+				"DATABASE_PASSWORD":               "secret://test/db#password",
+				"SECRETS_PROVIDER":                "file",
+				"SECRETS_FILE":                    secretsFile,
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"HTTP_PORT":                       ":8080",
+				"HTTP_DOMAIN":                     "localhost",
+				"HTTP_USE_SWAGGER":                "true",
+				"HTTP_SHUTDOWN_DURATION":          "10",
+				"AUTH_ISSUER":                     "https://issuer.test",
+				"AUTH_AUDIENCE":                   "users-api",
+				"AUTH_JWKS_URL":                   "https://issuer.test/.well-known/jwks.json",
+				"RATE_LIMIT_ROUTES":               "GET /lambda/user=10/1s,PUT /lambda/user/{ID}=5/1m",
+				"CORS_ALLOWED_ORIGINS":            "https://app.example.com,https://admin.example.com",
+				"CORS_ALLOW_CREDENTIALS":          "true",
+				"DATABASE_SSL_MODE":               "verify-full",
+				"DATABASE_SSL_ROOT_CERT":          "/etc/ssl/db-ca.pem",
+				"HTTP_TLS_CERT_FILE":              "/etc/tls/server.crt",
+				"HTTP_TLS_KEY_FILE":               "/etc/tls/server.key",
+				"HTTP_TLS_CLIENT_CA_FILE":         "/etc/tls/clients-ca.pem",
+			},
+			expectedCfg: Configuration{
+				Env:                   "development",
+				LogLevel:              slog.LevelInfo,
+				LogFormat:             "json",
+				LogRedactKeys:         []string{"password", "secret", "token", "authorization", "cookie", "api_key", "first_name", "last_name"},
+				TracingExporter:       "none",
+				TracingServiceName:    "user-microservice",
+				ErrorReportingTimeout: 2,
+				DBName:                "test_db",
+				DBUser:                "test_user",
+				DBPassword:            "test_password",
+				DBHost:                "localhost",
+				DBPort:                "5432",
+				DBRetryDuration:       10,
+				DBSlowQueryThreshold:  200,
+				DBSSLMode:             "verify-full",
+				DBSSLRootCert:         "/etc/ssl/db-ca.pem",
+				HTTPPort:              ":8080",
+				HTTPDomain:            "localhost",
+				HTTPUseSwagger:        true,
+				HTTPShutdownDuration:  10,
+				HTTPTLSCertFile:       "/etc/tls/server.crt",
+				HTTPTLSKeyFile:        "/etc/tls/server.key",
+				HTTPTLSClientCAFile:   "/etc/tls/clients-ca.pem",
+				HTTPTLSReload:         10,
+				ShutdownDrainDelay:    5,
+				ShutdownTimeout:       5,
+				HTTPReadTimeout:       15,
+				HTTPReadHeaderTimeout: 5,
+				HTTPWriteTimeout:      30,
+				HTTPIdleTimeout:       120,
+				HTTPMaxHeaderBytes:    1048576,
+				HTTPMaxBodyBytes:      1048576,
+				HealthCheckTimeout:    2,
+				HealthCacheTTL:        5,
+				TenantClaim:           "tenant_id",
+				AuthIssuer:            "https://issuer.test",
+				AuthAudience:          "users-api",
+				AuthJWKSURL:           "https://issuer.test/.well-known/jwks.json",
+				AuthJWKSRefresh:       900,
+				AuthClockSkew:         30,
+				APIKeyHeader:          "X-API-Key",
+				APIKeyCacheTTL:        60,
+				RateLimitDefault:      "100/1m",
+				RateLimitIP:           "1000/1m",
+				RateLimitRoutes: map[string]string{
+					"GET /lambda/user":      "10/1s",
+					"PUT /lambda/user/{ID}": "5/1m",
+				},
+				CORSAllowedOrigins:    []string{"https://app.example.com", "https://admin.example.com"},
+				CORSAllowedMethods:    []string{"GET", "PUT", "POST", "DELETE"},
+				CORSAllowedHeaders:    []string{"Accept", "Authorization", "Content-Type"},
+				CORSAllowCredentials:  true,
+				CORSMaxAge:            300,
+				SecurityHSTSMaxAge:    31536000,
+				SecurityCSP:           "default-src 'none'; frame-ancestors 'none'",
+				SecurityReferrer:      "no-referrer",
+				EncryptionKeyProvider: "file",
+				EncryptionKeyFile:     "keys.local.json",
+			},
+			expectedError: false,
+		},
+		"unknown secret": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_PASSWORD":               "secret://test/missing#password",
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"SECRETS_PROVIDER":                "file",
+				"SECRETS_FILE":                    secretsFile,
+			},
+			expectedCfg:   Configuration{},
+			expectedError: true,
+		},
+		"tls key without certificate": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_PASSWORD":               "test_password",
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"HTTP_PORT":                       ":8080",
+				"HTTP_DOMAIN":                     "localhost",
+				"HTTP_USE_SWAGGER":                "true",
+				"HTTP_SHUTDOWN_DURATION":          "10",
+				"AUTH_ISSUER":                     "https://issuer.test",
+				"AUTH_AUDIENCE":                   "users-api",
+				"AUTH_JWKS_URL":                   "https://issuer.test/.well-known/jwks.json",
+				"HTTP_TLS_KEY_FILE":               "/etc/tls/server.key",
+			},
+			expectedCfg:   Configuration{},
+			expectedError: true,
+		},
+		"base path with trailing slash": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_PASSWORD":               "test_password",
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"HTTP_PORT":                       ":8080",
+				"HTTP_DOMAIN":                     "localhost",
+				"HTTP_USE_SWAGGER":                "true",
+				"HTTP_SHUTDOWN_DURATION":          "10",
+				"AUTH_ISSUER":                     "https://issuer.test",
+				"AUTH_AUDIENCE":                   "users-api",
+				"AUTH_JWKS_URL":                   "https://issuer.test/.well-known/jwks.json",
+				"HTTP_BASE_PATH":                  "/api/",
+			},
+			expectedCfg:   Configuration{},
+			expectedError: true,
+		},
+		"credentials with any origin": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_PASSWORD":               "test_password",
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"AUTH_ISSUER":                     "https://issuer.test",
+				"AUTH_AUDIENCE":                   "users-api",
+				"AUTH_JWKS_URL":                   "https://issuer.test/.well-known/jwks.json",
+				"CORS_ALLOW_CREDENTIALS":          "true",
+			},
+			expectedCfg:   Configuration{},
+			expectedError: true,
+		},
 		"missing required env": {
 			envVars: map[string]string{
 				"DATABASE_NAME":                   "test_db",
@@ -87,6 +327,8 @@ func TestNewConfiguration(t *testing.T) {
 				assert.Equal(t, tc.expectedCfg, cfg)
 			} else {
 				assert.NoError(t, err)
+				assert.NotNil(t, cfg.DBCredentials)
+				cfg.DBCredentials = nil
 				assert.Equal(t, tc.expectedCfg, cfg)
 			}
 		})
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBCredentials(t *testing.T) {
+	t.Run("plain values", func(t *testing.T) {
+		credentials := NewDBCredentials(nil, "db-user", "db-password")
+
+		user, password, err := credentials.Get(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "db-user", user)
+		assert.Equal(t, "db-password", password)
+
+		user, password, err = credentials.Refresh(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "db-user", user)
+		assert.Equal(t, "db-password", password)
+	})
+
+	t.Run("refresh picks up rotated secrets", func(t *testing.T) {
+		next := &stubSecrets{secrets: map[string]string{"db": `{"username": "db-user", "password": "old"}`}}
+		credentials := NewDBCredentials(NewSecretCache(next, time.Hour), "secret://db#username", "secret://db#password")
+
+		_, password, err := credentials.Get(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "old", password)
+
+		next.secrets["db"] = `{"username": "db-user", "password": "new"}`
+		_, password, err = credentials.Get(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "old", password, "cached secret not used")
+
+		user, password, err := credentials.Refresh(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "db-user", user)
+		assert.Equal(t, "new", password)
+		assert.Equal(t, 2, next.calls)
+	})
+
+	t.Run("unresolvable secret", func(t *testing.T) {
+		credentials := NewDBCredentials(&stubSecrets{}, "db-user", "secret://db#password")
+
+		_, _, err := credentials.Get(context.Background())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// SecretsManagerClient is the part of the Secrets Manager client SecretsManagerProvider uses.
+type SecretsManagerClient interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// SecretsManagerProvider reads secrets from AWS Secrets Manager. Names are secret names or ARNs.
+type SecretsManagerProvider struct {
+	client SecretsManagerClient
+}
+
+// NewSecretsManagerProvider returns a SecretsManagerProvider using client.
+func NewSecretsManagerProvider(client SecretsManagerClient) *SecretsManagerProvider {
+	return &SecretsManagerProvider{client: client}
+}
+
+// GetSecret returns the current string value of the named secret.
+func (p *SecretsManagerProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+	if err != nil {
+		var notFound *smtypes.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", fmt.Errorf("[in config.SecretsManagerProvider.GetSecret] %q: %w", name, ErrSecretNotFound)
+		}
+		return "", fmt.Errorf("[in config.SecretsManagerProvider.GetSecret] failed to get %q: %w", name, err)
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("[in config.SecretsManagerProvider.GetSecret] %q has no string value", name)
+	}
+
+	return *out.SecretString, nil
+}
+
+// SSMClient is the part of the SSM client SSMProvider uses.
+type SSMClient interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// SSMProvider reads secrets from SSM Parameter Store. Names are parameter names, e.g.
+// `/user-microservice/db`. SecureString parameters are decrypted.
+type SSMProvider struct {
+	client SSMClient
+}
+
+// NewSSMProvider returns an SSMProvider using client.
+func NewSSMProvider(client SSMClient) *SSMProvider {
+	return &SSMProvider{client: client}
+}
+
+// GetSecret returns the value of the named parameter.
+func (p *SSMProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	out, err := p.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		var notFound *ssmtypes.ParameterNotFound
+		if errors.As(err, &notFound) {
+			return "", fmt.Errorf("[in config.SSMProvider.GetSecret] %q: %w", name, ErrSecretNotFound)
+		}
+		return "", fmt.Errorf("[in config.SSMProvider.GetSecret] failed to get %q: %w", name, err)
+	}
+	if out.Parameter == nil || out.Parameter.Value == nil {
+		return "", fmt.Errorf("[in config.SSMProvider.GetSecret] %q has no value", name)
+	}
+
+	return *out.Parameter.Value, nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// FileSecretProvider reads secrets from a local JSON file mapping secret names to values, for local
+// development. A value is either a string or an object whose keys are referenced with
+// `secret://<name>#<key>`.
+type FileSecretProvider struct {
+	path string
+}
+
+// NewFileSecretProvider returns a FileSecretProvider reading the file at path.
+func NewFileSecretProvider(path string) FileSecretProvider {
+	return FileSecretProvider{path: path}
+}
+
+// GetSecret returns the named secret from the file. The file is read on every call.
+func (p FileSecretProvider) GetSecret(_ context.Context, name string) (string, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return "", fmt.Errorf("[in config.FileSecretProvider.GetSecret] failed to read secrets file: %w", err)
+	}
+
+	var secrets map[string]json.RawMessage
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return "", fmt.Errorf("[in config.FileSecretProvider.GetSecret] failed to parse secrets file: %w", err)
+	}
+
+	secret, ok := secrets[name]
+	if !ok {
+		return "", fmt.Errorf("[in config.FileSecretProvider.GetSecret] %q: %w", name, ErrSecretNotFound)
+	}
+
+	return jsonValue(secret), nil
+}
@@ -5,38 +5,43 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 	"math/rand"
 	"time"
 
-	"github.com/go-chi/httplog/v2"
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 // New establishes a database connection, tests that connection with `ping()`, and returns the connection.
-func New(ctx context.Context, connectionString string, logger *httplog.Logger, retryDuration time.Duration) (*sql.DB, error) {
-	logger.Info("Attempting to connect to database")
-	retryCount := 0
-	db, err := retryResult(ctx, retryDuration, func() (*sql.DB, error) {
-		retryCount++
-		return sql.Open("postgres", connectionString)
-	})
-	if err != nil {
-		return nil, fmt.Errorf(
-			"[in database.New] Failed to connect to database with retry duration of %s and %d attempts: %w",
-			retryDuration,
-			retryCount,
-			err,
-		)
+// Connections authenticate with credentials, which are refreshed once when Postgres rejects them,
+// see Credentials. If credentials is nil, the user and password in dsn are used. Queries are traced
+// with the global TracerProvider, and logged and aggregated as configured by opts, see
+// WithSlowQueryThreshold and WithQueryStats.
+func New(ctx context.Context, dsn DSN, credentials Credentials, logger *slog.Logger, retryDuration time.Duration, opts ...Option) (*sql.DB, error) {
+	if credentials == nil {
+		credentials = staticCredentials{user: dsn.User, password: dsn.Password}
 	}
-	if db == nil {
-		return nil, fmt.Errorf("[in database.New] db is nil")
+
+	connector := newConnector(dsn, credentials, logger)
+	if len(opts) > 0 {
+		connector.queries = &queryLog{}
+		for _, opt := range opts {
+			opt(connector.queries)
+		}
 	}
-	logger.Info("Successfully connected to database", "retry count", retryCount)
+
+	db := otelsql.OpenDB(
+		connector,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
 
 	logger.Info("Attempting to ping database")
-	retryCount = 0
-	err = retry(ctx, retryDuration, func() error {
+	retryCount := 0
+	err := retry(ctx, retryDuration, func() error {
 		retryCount++
 		return db.Ping()
 	})
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDSNConnectionString(t *testing.T) {
+	tests := map[string]struct {
+		dsn      DSN
+		expected string
+	}{
+		"ssl disabled": {
+			dsn: DSN{
+				Host:     "localhost",
+				Port:     "5432",
+				User:     "db-user",
+				Password: "db-password",
+				Name:     "db-name",
+				SSLMode:  "disable",
+			},
+			expected: `host='localhost' port='5432' user='db-user' password='db-password' dbname='db-name' sslmode='disable'`,
+		},
+		"verified ssl": {
+			dsn: DSN{
+				Host:        "db.internal",
+				Port:        "5432",
+				User:        "db-user",
+				Password:    "db-password",
+				Name:        "db-name",
+				SSLMode:     "verify-full",
+				SSLRootCert: "/etc/ssl/rds-ca.pem",
+			},
+			expected: `host='db.internal' port='5432' user='db-user' password='db-password' dbname='db-name' sslmode='verify-full' sslrootcert='/etc/ssl/rds-ca.pem'`,
+		},
+		"special characters quoted": {
+			dsn: DSN{
+				Host:     "localhost",
+				User:     "db-user",
+				Password: `p@ss word'\`,
+				Name:     "db-name",
+			},
+			expected: `host='localhost' user='db-user' password='p@ss word\'\\' dbname='db-name'`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.dsn.ConnectionString())
+		})
+	}
+}
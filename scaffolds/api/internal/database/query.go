@@ -0,0 +1,300 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+)
+
+// Option configures New.
+type Option func(*queryLog)
+
+// WithSlowQueryThreshold logs queries that take threshold or longer at the warn level, with their
+// normalized statement, duration and row count. Faster queries are logged at the debug level. A
+// threshold of zero disables the log.
+func WithSlowQueryThreshold(threshold time.Duration) Option {
+	return func(l *queryLog) {
+		l.slowThreshold = threshold
+	}
+}
+
+// WithQueryStats records every query in stats.
+func WithQueryStats(stats *QueryStats) Option {
+	return func(l *queryLog) {
+		l.stats = stats
+	}
+}
+
+// queryLog observes the queries run on the connections of a connector. Queries are observed once
+// their rows are closed, so the duration and row count include reading the results. Statements
+// prepared explicitly are not observed.
+type queryLog struct {
+	slowThreshold time.Duration
+	stats         *QueryStats
+}
+
+// observe logs and records a query that started at start.
+func (l *queryLog) observe(ctx context.Context, query string, start time.Time, rows int64, err error) {
+	duration := time.Since(start)
+	statement := Normalize(query)
+	if l.stats != nil {
+		l.stats.Record(statement, duration, rows, err)
+	}
+	if l.slowThreshold <= 0 {
+		return
+	}
+
+	logger := logging.FromContext(ctx)
+	attrs := []any{"statement", statement, "duration_ms", float64(duration.Microseconds()) / 1000, "rows", rows}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	if duration >= l.slowThreshold {
+		logger.WarnContext(ctx, "Slow query", attrs...)
+		return
+	}
+	logger.DebugContext(ctx, "Query", attrs...)
+}
+
+// wrap returns conn with its queries observed.
+func (l *queryLog) wrap(conn driver.Conn) driver.Conn {
+	return &observedConn{Conn: conn, log: l}
+}
+
+// observedConn observes the queries of the wrapped connection. The optional interfaces lib/pq
+// connections implement are passed through, so database/sql uses them as it would without it.
+type observedConn struct {
+	driver.Conn
+	log *queryLog
+}
+
+func (c *observedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		if !errors.Is(err, driver.ErrSkip) {
+			c.log.observe(ctx, query, start, 0, err)
+		}
+		return nil, err
+	}
+	return &observedRows{Rows: rows, ctx: ctx, query: query, start: start, log: c.log}, nil
+}
+
+func (c *observedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	var rows int64
+	if err == nil {
+		rows, _ = result.RowsAffected()
+	}
+	c.log.observe(ctx, query, start, rows, err)
+	return result, err
+}
+
+func (c *observedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *observedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *observedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *observedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *observedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// observedRows counts the rows read and observes the query when they are closed.
+type observedRows struct {
+	driver.Rows
+	ctx   context.Context
+	query string
+	start time.Time
+	log   *queryLog
+
+	count  int64
+	err    error
+	closed bool
+}
+
+func (r *observedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	switch {
+	case err == nil:
+		r.count++
+	case !errors.Is(err, io.EOF):
+		r.err = err
+	}
+	return err
+}
+
+func (r *observedRows) Close() error {
+	err := r.Rows.Close()
+	if !r.closed {
+		r.closed = true
+		r.log.observe(r.ctx, r.query, r.start, r.count, r.err)
+	}
+	return err
+}
+
+func (r *observedRows) HasNextResultSet() bool {
+	if sets, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return sets.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *observedRows) NextResultSet() error {
+	if sets, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return sets.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *observedRows) ColumnTypeScanType(index int) reflect.Type {
+	if types, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return types.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(any)).Elem()
+}
+
+func (r *observedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if types, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return types.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *observedRows) ColumnTypeLength(index int) (int64, bool) {
+	if types, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return types.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *observedRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if types, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return types.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
+// Normalize returns the fingerprint of query: literals are replaced with `?`, comments are removed
+// and whitespace is collapsed, so queries that only differ in their values share a fingerprint and
+// no values are logged. Placeholders like `$1` are kept.
+func Normalize(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	space := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			space = true
+			continue
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end + 3
+			}
+			space = true
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			continue
+		}
+
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+
+		switch {
+		case c == '\'':
+			// '' is an escaped quote inside a string literal
+			for i++; i < len(query); i++ {
+				if query[i] == '\'' {
+					if i+1 < len(query) && query[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteByte('?')
+		case isDigit(c) && !isIdentifier(previous(query, i)):
+			for i+1 < len(query) && (isDigit(query[i+1]) || query[i+1] == '.') {
+				i++
+			}
+			b.WriteByte('?')
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// previous returns the byte before query[i], or 0 if there is none.
+func previous(query string, i int) byte {
+	if i == 0 {
+		return 0
+	}
+	return query[i-1]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentifier reports whether c can precede a digit within an identifier or a placeholder.
+func isIdentifier(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// statsSamples is the number of recent durations percentiles are computed from, per statement.
+	statsSamples = 1024
+	// statsStatements bounds the number of statements recorded. Queries with other statements are
+	// recorded under OtherStatement.
+	statsStatements = 500
+)
+
+// OtherStatement is the statement queries are recorded under once QueryStats holds the maximum
+// number of statements.
+const OtherStatement = "other"
+
+// StatementStats are the aggregated stats of the queries with a normalized statement, see
+// Normalize. Durations are in milliseconds, and percentiles cover the most recent queries.
+type StatementStats struct {
+	Statement string  `json:"statement"`
+	Count     int64   `json:"count"`
+	Errors    int64   `json:"errors"`
+	Rows      int64   `json:"rows"`
+	Total     float64 `json:"total_ms"`
+	P50       float64 `json:"p50_ms"`
+	P95       float64 `json:"p95_ms"`
+	Max       float64 `json:"max_ms"`
+}
+
+// QueryStats aggregates the queries run by the process per normalized statement. It is safe for
+// concurrent use.
+type QueryStats struct {
+	mu         sync.Mutex
+	statements map[string]*statementStats
+}
+
+type statementStats struct {
+	count  int64
+	errors int64
+	rows   int64
+	total  time.Duration
+	max    time.Duration
+
+	// samples is a ring of the most recent durations, next is the index the next one is written to
+	samples []time.Duration
+	next    int
+}
+
+// NewQueryStats returns empty QueryStats.
+func NewQueryStats() *QueryStats {
+	return &QueryStats{statements: make(map[string]*statementStats)}
+}
+
+// Record adds a query with the normalized statement that took duration, read or affected rows and
+// failed with err, if not nil.
+func (s *QueryStats) Record(statement string, duration time.Duration, rows int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, ok := s.statements[statement]
+	if !ok {
+		if len(s.statements) >= statsStatements {
+			statement = OtherStatement
+		}
+		if stats, ok = s.statements[statement]; !ok {
+			stats = &statementStats{}
+			s.statements[statement] = stats
+		}
+	}
+
+	stats.count++
+	stats.rows += rows
+	stats.total += duration
+	stats.max = max(stats.max, duration)
+	if err != nil {
+		stats.errors++
+	}
+	if len(stats.samples) < statsSamples {
+		stats.samples = append(stats.samples, duration)
+	} else {
+		stats.samples[stats.next] = duration
+		stats.next = (stats.next + 1) % statsSamples
+	}
+}
+
+// Snapshot returns the stats of every statement, the statements the most time was spent on first.
+func (s *QueryStats) Snapshot() []StatementStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := make([]StatementStats, 0, len(s.statements))
+	for statement, stats := range s.statements {
+		samples := slices.Clone(stats.samples)
+		slices.Sort(samples)
+		snapshot = append(snapshot, StatementStats{
+			Statement: statement,
+			Count:     stats.count,
+			Errors:    stats.errors,
+			Rows:      stats.rows,
+			Total:     milliseconds(stats.total),
+			P50:       milliseconds(percentile(samples, 0.50)),
+			P95:       milliseconds(percentile(samples, 0.95)),
+			Max:       milliseconds(stats.max),
+		})
+	}
+
+	slices.SortFunc(snapshot, func(a, b StatementStats) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), cmp.Compare(a.Statement, b.Statement))
+	})
+	return snapshot
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
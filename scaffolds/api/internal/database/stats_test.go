@@ -0,0 +1,59 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryStats(t *testing.T) {
+	stats := NewQueryStats()
+	for i := 1; i <= 100; i++ {
+		stats.Record("SELECT id FROM users", time.Duration(i)*time.Millisecond, 2, nil)
+	}
+	stats.Record("UPDATE users SET role = $1", 500*time.Millisecond, 0, errors.New("deadlock detected"))
+
+	assert.Equal(t, []StatementStats{
+		{
+			Statement: "SELECT id FROM users",
+			Count:     100,
+			Rows:      200,
+			Total:     5050,
+			P50:       50,
+			P95:       95,
+			Max:       100,
+		},
+		{
+			Statement: "UPDATE users SET role = $1",
+			Count:     1,
+			Errors:    1,
+			Total:     500,
+			P50:       500,
+			P95:       500,
+			Max:       500,
+		},
+	}, stats.Snapshot())
+}
+
+func TestQueryStatsBounds(t *testing.T) {
+	stats := NewQueryStats()
+	for i := range statsStatements + 10 {
+		stats.Record(fmt.Sprintf("SELECT id FROM users_%d", i), time.Millisecond, 0, nil)
+	}
+	for range statsSamples + 10 {
+		stats.Record("SELECT id FROM users_0", time.Second, 0, nil)
+	}
+
+	snapshot := stats.Snapshot()
+	assert.Len(t, snapshot, statsStatements+1)
+
+	// the oldest samples were overwritten, so the percentiles only cover the slow queries
+	assert.Equal(t, "SELECT id FROM users_0", snapshot[0].Statement)
+	assert.Equal(t, 1000.0, snapshot[0].P50)
+	assert.Contains(t, snapshot, StatementStats{
+		Statement: OtherStatement, Count: 10, Total: 10, P50: 1, P95: 1, Max: 1,
+	})
+}
@@ -0,0 +1,190 @@
+package encryption
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// envelopePrefix marks encrypted values and the version of their format.
+	envelopePrefix = "enc:v1:"
+	// dataKeyLifetime is how long a data key encrypts values before a new one is generated, so a
+	// KeyProvider backed by KMS is not called for every value.
+	dataKeyLifetime = 5 * time.Minute
+	// maxUnwrappedKeys bounds the number of unwrapped data keys kept for decryption.
+	maxUnwrappedKeys = 1024
+	// minIndexKeySize is the minimum size of the blind index key.
+	minIndexKeySize = 32
+)
+
+// encoding encodes the parts of an encrypted value. It does not use ':', which separates them.
+var encoding = base64.RawURLEncoding
+
+// Envelope encrypts values with envelope encryption: each value is encrypted with AES-GCM under a
+// data key, and the data key, wrapped by a master key of the KeyProvider, is stored alongside the
+// ciphertext together with the ID of that master key. Encrypted values have the form
+// `enc:v1:<key ID>:<wrapped data key>:<nonce and ciphertext>`, so values written under a previous
+// master key stay readable after the master key is rotated.
+type Envelope struct {
+	keys     KeyProvider
+	indexKey []byte
+	now      func() time.Time
+
+	mu        sync.Mutex
+	current   *dataKey
+	unwrapped map[string]cipher.AEAD
+}
+
+// dataKey is the data key new values are encrypted with.
+type dataKey struct {
+	keyID   string
+	wrapped []byte
+	aead    cipher.AEAD
+	created time.Time
+}
+
+// NewEnvelope returns an Envelope wrapping data keys with keys. indexKey is the base64 encoded key,
+// of at least 32 bytes, blind indexes are computed with.
+func NewEnvelope(keys KeyProvider, indexKey string) (*Envelope, error) {
+	key, err := base64.StdEncoding.DecodeString(indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("[in encryption.NewEnvelope] failed to decode index key: %w", err)
+	}
+	if len(key) < minIndexKeySize {
+		return nil, fmt.Errorf("[in encryption.NewEnvelope] index key must be at least %d bytes", minIndexKeySize)
+	}
+
+	return &Envelope{
+		keys:      keys,
+		indexKey:  key,
+		now:       time.Now,
+		unwrapped: make(map[string]cipher.AEAD),
+	}, nil
+}
+
+// Encrypt encrypts value. scope names where the value is stored, e.g. its tenant and column, and
+// must be given again to decrypt it, so ciphertext cannot be copied to another row or column.
+func (e *Envelope) Encrypt(ctx context.Context, value string, scope string) (string, error) {
+	key, err := e.dataKey(ctx)
+	if err != nil {
+		return "", fmt.Errorf("[in encryption.Envelope.Encrypt]: %w", err)
+	}
+
+	sealed, err := seal(key.aead, []byte(value), []byte(scope))
+	if err != nil {
+		return "", fmt.Errorf("[in encryption.Envelope.Encrypt]: %w", err)
+	}
+
+	return envelopePrefix + strings.Join([]string{
+		encoding.EncodeToString([]byte(key.keyID)),
+		encoding.EncodeToString(key.wrapped),
+		encoding.EncodeToString(sealed),
+	}, ":"), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt with the same scope. Values that are not encrypted,
+// e.g. rows written before encryption was enabled, are returned as is.
+func (e *Envelope) Decrypt(ctx context.Context, value string, scope string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, envelopePrefix)
+	if !ok {
+		return value, nil
+	}
+
+	parts := strings.Split(encoded, ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("[in encryption.Envelope.Decrypt] malformed value")
+	}
+	decoded := make([][]byte, len(parts))
+	for i, part := range parts {
+		b, err := encoding.DecodeString(part)
+		if err != nil {
+			return "", fmt.Errorf("[in encryption.Envelope.Decrypt] malformed value: %w", err)
+		}
+		decoded[i] = b
+	}
+	keyID, wrapped, sealed := string(decoded[0]), decoded[1], decoded[2]
+
+	aead, err := e.unwrap(ctx, keyID, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("[in encryption.Envelope.Decrypt]: %w", err)
+	}
+	plaintext, err := open(aead, sealed, []byte(scope))
+	if err != nil {
+		return "", fmt.Errorf("[in encryption.Envelope.Decrypt]: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// BlindIndex returns a keyed hash of value for exact-match lookups of encrypted values. Values are
+// compared case-insensitively, ignoring surrounding whitespace. scope separates indexes, so equal
+// values in different tenants or columns do not share an index.
+func (e *Envelope) BlindIndex(value string, scope string) string {
+	mac := hmac.New(sha256.New, e.indexKey)
+	mac.Write([]byte(scope))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// dataKey returns the current data key, generating a new one when it has reached its lifetime.
+func (e *Envelope) dataKey(ctx context.Context) (*dataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.current != nil && e.now().Sub(e.current.created) < dataKeyLifetime {
+		return e.current, nil
+	}
+
+	key, err := e.keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+
+	e.current = &dataKey{keyID: key.KeyID, wrapped: key.Wrapped, aead: aead, created: e.now()}
+	return e.current, nil
+}
+
+// unwrap returns the data key wrapped by the master key keyID, unwrapping it with the KeyProvider
+// the first time it is seen.
+func (e *Envelope) unwrap(ctx context.Context, keyID string, wrapped []byte) (cipher.AEAD, error) {
+	cacheKey := keyID + ":" + string(wrapped)
+
+	e.mu.Lock()
+	aead, ok := e.unwrapped[cacheKey]
+	e.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+
+	plaintext, err := e.keys.DecryptDataKey(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err = newGCM(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+
+	e.mu.Lock()
+	if len(e.unwrapped) >= maxUnwrappedKeys {
+		clear(e.unwrapped)
+	}
+	e.unwrapped[cacheKey] = aead
+	e.mu.Unlock()
+
+	return aead, nil
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/database"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/redact"
+)
+
+// HandleGetLogLevel is an admin Handler that returns the minimum level of the records logged.
+func HandleGetLogLevel(level *slog.LevelVar) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+		encodeResponse(w, logger, http.StatusOK, responseLogLevel{
+			Level: level.Level().String(),
+		})
+	}
+}
+
+// HandleSetLogLevel is an admin Handler that changes the minimum level of the records logged, so
+// debug logs can be turned on without restarting the service. The change is lost on restart.
+func HandleSetLogLevel(level *slog.LevelVar) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+		ctx := r.Context()
+
+		// get and validate body as level
+		newLevel, problems, err := decodeValidateBody[inputLogLevel, slog.Level](w, r)
+		if err != nil {
+			encodeBodyError(w, r, problems, err)
+			return
+		}
+
+		// logged as a warning, so the change is recorded at every level but ERROR
+		logger.WarnContext(ctx, "Changing log level", "from", level.Level().String(), "to", newLevel.String())
+		level.Set(newLevel)
+
+		encodeResponse(w, logger, http.StatusOK, responseLogLevel{
+			Level: level.Level().String(),
+		})
+	}
+}
+
+// HandleBuildInfo is an admin Handler that returns the Go version, module versions and VCS
+// revision the service was built with.
+func HandleBuildInfo() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			encodeResponse(w, logger, http.StatusNotFound, responseErr{
+				Error: "Build info not available",
+			})
+			return
+		}
+
+		response := responseBuildInfo{
+			GoVersion: info.GoVersion,
+			Main:      outputModule{Path: info.Main.Path, Version: info.Main.Version},
+			Settings:  make(map[string]string, len(info.Settings)),
+			Deps:      make([]outputModule, 0, len(info.Deps)),
+		}
+		for _, setting := range info.Settings {
+			response.Settings[setting.Key] = setting.Value
+		}
+		for _, dep := range info.Deps {
+			response.Deps = append(response.Deps, outputModule{Path: dep.Path, Version: dep.Version})
+		}
+
+		encodeResponse(w, logger, http.StatusOK, response)
+	}
+}
+
+// HandleConfig is an admin Handler that returns configuration with the values policy considers
+// sensitive masked, e.g. fields tagged `log:"sensitive"`.
+func HandleConfig(configuration any, policy *redact.Policy) http.HandlerFunc {
+	redacted := policy.Value(configuration)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+		encodeResponse(w, logger, http.StatusOK, redacted)
+	}
+}
+
+// queryStatsSnapshotter returns the aggregated stats of the queries run by the service.
+type queryStatsSnapshotter interface {
+	Snapshot() []database.StatementStats
+}
+
+// HandleQueryStats is an admin Handler that returns the stats of every normalized statement the
+// service ran, the statements the most time was spent on first.
+func HandleQueryStats(stats queryStatsSnapshotter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+		encodeResponse(w, logger, http.StatusOK, responseQueryStats{
+			Statements: stats.Snapshot(),
+		})
+	}
+}
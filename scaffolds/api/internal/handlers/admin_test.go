@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/database"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleSetLogLevel(t *testing.T) {
+	tests := map[string]struct {
+		body          string
+		maxBodyBytes  int64
+		expectedCode  int
+		expectedBody  string
+		expectedLevel slog.Level
+	}{
+		"level changed": {
+			body:          `{"level":"debug"}`,
+			expectedCode:  http.StatusOK,
+			expectedBody:  `{"level":"DEBUG"}`,
+			expectedLevel: slog.LevelDebug,
+		},
+		"unknown level": {
+			body:          `{"level":"verbose"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedBody:  `{"validation_errors":[{"name":"level","description":"must be \"DEBUG\", \"INFO\", \"WARN\" or \"ERROR\""}]}`,
+			expectedLevel: slog.LevelInfo,
+		},
+		"malformed body": {
+			body:          `{"level":`,
+			expectedCode:  http.StatusBadRequest,
+			expectedBody:  `{"error":"missing values or malformed body"}`,
+			expectedLevel: slog.LevelInfo,
+		},
+		"body too large": {
+			body:          `{"level":"debug"}`,
+			maxBodyBytes:  8,
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			expectedBody:  `{"type":"about:blank","title":"Request Entity Too Large","status":413,"detail":"request body must not be larger than 8 bytes"}`,
+			expectedLevel: slog.LevelInfo,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			level := new(slog.LevelVar)
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/admin/loglevel", strings.NewReader(tc.body))
+			if tc.maxBodyBytes > 0 {
+				req = req.WithContext(WithMaxBodyBytes(req.Context(), tc.maxBodyBytes))
+			}
+			HandleSetLogLevel(level).ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String(), "Wrong response body")
+			assert.Equal(t, tc.expectedLevel, level.Level())
+
+			rr = httptest.NewRecorder()
+			HandleGetLogLevel(level).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/loglevel", nil))
+			assert.JSONEq(t, `{"level":"`+tc.expectedLevel.String()+`"}`, rr.Body.String())
+		})
+	}
+}
+
+func TestHandleConfig(t *testing.T) {
+	configuration := struct {
+		Env        string
+		DBPassword string `log:"sensitive"`
+		AuthToken  string
+	}{
+		Env:        "dev",
+		DBPassword: "hunter2",
+		AuthToken:  "secret-token",
+	}
+
+	rr := httptest.NewRecorder()
+	HandleConfig(configuration, redact.NewPolicy([]string{"auth_token"}, nil)).
+		ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/config", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"Env":"dev","DBPassword":"[REDACTED]","AuthToken":"[REDACTED]"}`, rr.Body.String())
+}
+
+func TestHandleBuildInfo(t *testing.T) {
+	rr := httptest.NewRecorder()
+	HandleBuildInfo().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/buildinfo", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"go_version":"go`)
+}
+
+func TestHandleQueryStats(t *testing.T) {
+	stats := database.NewQueryStats()
+	stats.Record("SELECT id FROM users WHERE tenant_id = $1", 4*time.Millisecond, 2, nil)
+
+	rr := httptest.NewRecorder()
+	HandleQueryStats(stats).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/queries", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"statements":[{"statement":"SELECT id FROM users WHERE tenant_id = $1","count":1,"errors":0,`+
+		`"rows":2,"total_ms":4,"p50_ms":4,"p95_ms":4,"max_ms":4}]}`, rr.Body.String())
+}
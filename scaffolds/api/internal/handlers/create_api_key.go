@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+)
+
+type apiKeyCreator interface {
+	CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, string, error)
+}
+
+// HandleCreateAPIKey is a Handler that creates an API key based on an API key object from the
+// request body. The plain key is only part of this response.
+//
+// @Summary		Create an API key
+// @Description	Create an API key. The returned key is shown only once.
+// @Tags		api-keys
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Security	APIKeyAuth
+// @Param		api_key				body		handlers.inputAPIKey	true	"API Key Object"
+// @Success		201					{object}	handlers.responseCreatedAPIKey
+// @Failure		400					{object}	handlers.responseErr
+// @Failure		401					{object}	handlers.responseErr
+// @Failure		403					{object}	handlers.responseErr
+// @Failure		413					{object}	handlers.problemDetails
+// @Failure		500					{object}	handlers.responseErr
+// @Router		/admin/api-keys		[POST]
+// @x-v1		true
+// @x-v2		true
+func HandleCreateAPIKey(service apiKeyCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+		// setup
+		ctx := r.Context()
+
+		// get and validate body as object
+		keyIn, problems, err := decodeValidateBody[inputAPIKey, models.APIKey](w, r)
+		if err != nil {
+			encodeBodyError(w, r, problems, err)
+			return
+		}
+
+		// create object in database
+		key, plain, err := service.CreateAPIKey(ctx, keyIn)
+		if err != nil {
+			logger.ErrorContext(ctx, "error creating object in database", "error", err)
+			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error creating object",
+			})
+			return
+		}
+
+		// return response
+		encodeResponse(w, logger, http.StatusCreated, responseCreatedAPIKey{
+			APIKey: mapAPIKeyOutput(key),
+			Key:    plain,
+		})
+	}
+}
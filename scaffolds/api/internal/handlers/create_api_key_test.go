@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	serviceMock "github.com/captechconsulting/go-microservice-templates/api/internal/handlers/mock"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleCreateAPIKey(t *testing.T) {
+	mockService := new(serviceMock.MockApiKeyCreator)
+	handler := HandleCreateAPIKey(mockService)
+
+	expiresAt := time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC)
+	keyIn := models.APIKey{Owner: "billing-batch", Roles: []string{"Employee"}, Scopes: []string{"users:read"}, ExpiresAt: expiresAt}
+	keyOut := keyIn
+	keyOut.ID = 1
+	keyOut.Prefix = "0a1b2c3d"
+	keyOut.CreatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		mockCalled   bool
+		mockOutput   []any
+		requestBody  string
+		expectedCode int
+		expectedBody string
+	}{
+		"valid request, api key created": {
+			mockCalled:   true,
+			mockOutput:   []any{keyOut, "ak_0a1b2c3d_secret", nil},
+			requestBody:  `{"owner":"billing-batch","roles":["Employee"],"scopes":["users:read"],"expires_at":"2999-01-01T00:00:00Z"}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: testutil.ToJSONString(responseCreatedAPIKey{
+				APIKey: mapAPIKeyOutput(keyOut),
+				Key:    "ak_0a1b2c3d_secret",
+			}),
+		},
+		"invalid request body": {
+			mockCalled:   false,
+			requestBody:  `{"roles":["Employee"],"scopes":["users read"],"expires_at":"2000-01-01T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: testutil.ToJSONString(responseErr{
+				ValidationErrors: []problem{
+					{
+						Name:        "owner",
+						Description: "must not be blank",
+					},
+					{
+						Name:        "scopes",
+						Description: "must not be blank or contain whitespace",
+					},
+					{
+						Name:        "expires_at",
+						Description: "must be in the future",
+					},
+				},
+			}),
+		},
+		"malformed request body": {
+			mockCalled:   false,
+			requestBody:  `{"owner":`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: testutil.ToJSONString(responseErr{Error: "missing values or malformed body"}),
+		},
+		"error creating api key": {
+			mockCalled:   true,
+			mockOutput:   []any{models.APIKey{}, "", errors.New("creation error")},
+			requestBody:  `{"owner":"billing-batch","roles":["Employee"],"scopes":["users:read"],"expires_at":"2999-01-01T00:00:00Z"}`,
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: testutil.ToJSONString(responseErr{Error: "Error creating object"}),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/admin/api-keys", strings.NewReader(tc.requestBody))
+			assert.NoError(t, err)
+
+			if tc.mockCalled {
+				mockService.
+					On("CreateAPIKey", req.Context(), keyIn).
+					Return(tc.mockOutput...).
+					Once()
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String(), "Wrong response body")
+
+			if tc.mockCalled {
+				mockService.AssertExpectations(t)
+			} else {
+				mockService.AssertNotCalled(t, "CreateAPIKey")
+			}
+		})
+	}
+}
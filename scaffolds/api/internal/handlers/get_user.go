@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type userGetter interface {
+	GetUser(ctx context.Context, ID int) (models.User, error)
+}
+
+// HandleGetUser is a Handler that returns a single user by ID.
+//
+// @Summary		Get a user by ID
+// @Description	Get a user by ID
+// @Tags		user
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Security	APIKeyAuth
+// @Param		id			path		int		true	"User ID"
+// @Success		200			{object}	handlers.responseUser
+// @Failure		400			{object}	handlers.responseErr
+// @Failure		401			{object}	handlers.responseErr
+// @Failure		403			{object}	handlers.responseErr
+// @Failure		404			{object}	handlers.responseErr
+// @Failure		500			{object}	handlers.responseErr
+// @Router		/user/{ID}	[GET]
+// @x-v1		true
+func HandleGetUser(service userGetter) http.HandlerFunc {
+	return handleGetUser(service, func(user models.User) responseUser {
+		return responseUser{User: mapOutput(user)}
+	})
+}
+
+// handleGetUser returns a Handler that returns a single user by ID, mapped to the response body
+// of an API version by respond.
+func handleGetUser[O any](service userGetter, respond func(models.User) O) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+		// setup
+		ctx := r.Context()
+
+		// get and validate ID
+		idString := chi.URLParam(r, "ID")
+		ID, err := strconv.Atoi(idString)
+		if err != nil {
+			logger.ErrorContext(ctx, "error getting ID", "error", err)
+			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+				Error: "Not a valid ID",
+			})
+			return
+		}
+
+		// get object from database
+		user, err := service.GetUser(ctx, ID)
+		if err != nil {
+			if errors.Is(err, services.ErrNotFound) {
+				encodeResponse(w, logger, http.StatusNotFound, responseErr{
+					Error: "User not found",
+				})
+				return
+			}
+
+			logger.ErrorContext(ctx, "error getting object from database", "error", err)
+			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+			return
+		}
+
+		// return response
+		encodeResponse(w, logger, http.StatusOK, respond(user))
+	}
+}
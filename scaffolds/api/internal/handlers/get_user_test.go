@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serviceMock "github.com/captechconsulting/go-microservice-templates/api/internal/handlers/mock"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/services"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/testutil"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleGetUser(t *testing.T) {
+	mockService := new(serviceMock.MockUserGetter)
+	handler := HandleGetUser(mockService)
+
+	user := models.User{ID: 1, FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
+	userOut := mapOutput(user)
+
+	tests := map[string]struct {
+		mockCalled     bool
+		mockOutput     []any
+		requestIDParam string
+		expectedCode   int
+		expectedBody   string
+	}{
+		"valid request, user returned": {
+			mockCalled:     true,
+			mockOutput:     []any{user, nil},
+			requestIDParam: "1",
+			expectedCode:   http.StatusOK,
+			expectedBody:   testutil.ToJSONString(responseUser{User: userOut}),
+		},
+		"invalid ID": {
+			mockCalled:     false,
+			requestIDParam: "abc",
+			expectedCode:   http.StatusBadRequest,
+			expectedBody:   testutil.ToJSONString(responseErr{Error: "Not a valid ID"}),
+		},
+		"user not found": {
+			mockCalled:     true,
+			mockOutput:     []any{models.User{}, fmt.Errorf("[in services.GetUser] user 1: %w", services.ErrNotFound)},
+			requestIDParam: "1",
+			expectedCode:   http.StatusNotFound,
+			expectedBody:   testutil.ToJSONString(responseErr{Error: "User not found"}),
+		},
+		"error getting user": {
+			mockCalled:     true,
+			mockOutput:     []any{models.User{}, errors.New("test")},
+			requestIDParam: "1",
+			expectedCode:   http.StatusInternalServerError,
+			expectedBody:   testutil.ToJSONString(responseErr{Error: "Error retrieving data"}),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/lambda/user/"+tc.requestIDParam, nil)
+			assert.NoError(t, err)
+
+			// Add chi URLParam
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("ID", tc.requestIDParam)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			req = req.WithContext(ctx)
+
+			if tc.mockCalled {
+				mockService.
+					On("GetUser", ctx, 1).
+					Return(tc.mockOutput...).
+					Once()
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String(), "Wrong response body")
+
+			if tc.mockCalled {
+				mockService.AssertExpectations(t)
+			} else {
+				mockService.AssertNotCalled(t, "GetUser")
+			}
+		})
+	}
+}
@@ -1,25 +1,63 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
-	"github.com/go-chi/httplog/v2"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/health"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
 )
 
-// HandleHealth is a health check handler
-//
-// @Summary		Health check response
-// @Description	Health check response
-// @Tags		health-check
-// @Accept		json
-// @Produce		json
-// @Success		200				{object}	handlers.responseMsg
-// @Router		/health-check	[GET]
-func HandleHealth(logger *httplog.Logger) http.HandlerFunc {
+type readinessChecker interface {
+	Ready(ctx context.Context) health.Report
+}
+
+// outputReadiness is the public form of a health.Report: the errors of failed checks can name
+// database hosts, users and drivers, so they are only logged.
+type outputReadiness struct {
+	Status health.Status                `json:"status"`
+	Checks map[string]outputCheckStatus `json:"checks,omitempty"`
+}
+
+type outputCheckStatus struct {
+	Status health.Status `json:"status"`
+}
+
+// HandleLivez reports that the process is up and serving requests. It checks no dependencies, so
+// an outage of one does not get the service restarted.
+func HandleLivez() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		logger.Info("Health check called")
-		encodeResponse(w, logger, http.StatusOK, responseMsg{
-			Message: "hello world",
-		})
+		logger := logging.FromContext(r.Context())
+		encodeResponse(w, logger, http.StatusOK, health.Report{Status: health.StatusOK})
+	}
+}
+
+// HandleReadyz runs the checks of checker and returns the status of the service and of each check.
+// Failed checks are logged with their error. It responds with a 503 if a critical check failed or
+// the service is shutting down, so no requests are routed to it.
+func HandleReadyz(checker readinessChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+		ctx := r.Context()
+
+		report := checker.Ready(ctx)
+		status := http.StatusOK
+		switch report.Status {
+		case health.StatusUnavailable:
+			logger.WarnContext(ctx, "Service not ready", "report", report)
+			status = http.StatusServiceUnavailable
+		case health.StatusDegraded:
+			logger.WarnContext(ctx, "Service degraded", "report", report)
+		}
+
+		output := outputReadiness{Status: report.Status}
+		if len(report.Checks) > 0 {
+			output.Checks = make(map[string]outputCheckStatus, len(report.Checks))
+			for name, result := range report.Checks {
+				output.Checks[name] = outputCheckStatus{Status: result.Status}
+			}
+		}
+
+		encodeResponse(w, logger, status, output)
 	}
 }
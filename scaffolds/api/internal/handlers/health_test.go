@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleLivez(t *testing.T) {
+	rr := httptest.NewRecorder()
+	HandleLivez().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rr.Body.String())
+}
+
+func TestHandleReadyz(t *testing.T) {
+	tests := map[string]struct {
+		checkErr     error
+		shutdown     bool
+		expectedCode int
+		expectedBody string
+	}{
+		"ready": {
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"ok","checks":{"database":{"status":"ok"}}}`,
+		},
+		"database down, error not exposed": {
+			checkErr:     errors.New("dial tcp db.internal:5432: connection refused"),
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"unavailable","checks":{"database":{"status":"unavailable"}}}`,
+		},
+		"shutting down": {
+			shutdown:     true,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"unavailable","checks":{"shutdown":{"status":"unavailable"}}}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			checks := health.New(time.Second, 0)
+			checks.Register("database", health.CheckerFunc(func(ctx context.Context) error {
+				return tc.checkErr
+			}))
+			if tc.shutdown {
+				checks.Shutdown()
+			}
+
+			rr := httptest.NewRecorder()
+			HandleReadyz(checks).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+)
+
+type apiKeyLister interface {
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+}
+
+// HandleListAPIKeys is a Handler that returns a list of all API keys, without their secrets.
+//
+// @Summary		List all API keys
+// @Description	List all API keys, including revoked and expired ones
+// @Tags		api-keys
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Security	APIKeyAuth
+// @Success		200					{object}	handlers.responseAPIKeys
+// @Failure		401					{object}	handlers.responseErr
+// @Failure		403					{object}	handlers.responseErr
+// @Failure		500					{object}	handlers.responseErr
+// @Router		/admin/api-keys		[GET]
+// @x-v1		true
+// @x-v2		true
+func HandleListAPIKeys(service apiKeyLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+		// setup
+		ctx := r.Context()
+
+		// get values from database
+		keys, err := service.ListAPIKeys(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "error getting all api keys", "error", err)
+			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+			return
+		}
+
+		// return response
+		encodeResponse(w, logger, http.StatusOK, responseAPIKeys{
+			APIKeys: mapMultipleAPIKeyOutput(keys),
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	serviceMock "github.com/captechconsulting/go-microservice-templates/api/internal/handlers/mock"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleListAPIKeys(t *testing.T) {
+	mockService := new(serviceMock.MockApiKeyLister)
+	handler := HandleListAPIKeys(mockService)
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := []models.APIKey{
+		{ID: 1, Prefix: "0a1b2c3d", Owner: "billing-batch", Scopes: []string{"users:read"}, CreatedAt: createdAt},
+		{ID: 2, Prefix: "4e5f6a7b", Owner: "reporting", Scopes: []string{"users:read"}, RevokedAt: createdAt.Add(time.Hour), CreatedAt: createdAt},
+	}
+
+	tests := map[string]struct {
+		mockOutput   []any
+		expectedCode int
+		expectedBody string
+	}{
+		"api keys returned": {
+			mockOutput:   []any{keys, nil},
+			expectedCode: http.StatusOK,
+			expectedBody: testutil.ToJSONString(responseAPIKeys{APIKeys: mapMultipleAPIKeyOutput(keys)}),
+		},
+		"no api keys found": {
+			mockOutput:   []any{[]models.APIKey{}, nil},
+			expectedCode: http.StatusOK,
+			expectedBody: testutil.ToJSONString(responseAPIKeys{APIKeys: []outputAPIKey{}}),
+		},
+		"internal server error": {
+			mockOutput:   []any{[]models.APIKey{}, errors.New("test error")},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: testutil.ToJSONString(responseErr{Error: "Error retrieving data"}),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/api/admin/api-keys", nil)
+			assert.NoError(t, err)
+
+			mockService.
+				On("ListAPIKeys", req.Context()).
+				Return(tc.mockOutput...).
+				Once()
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String(), "Wrong response body")
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/testutil"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/httplog/v2"
 	"github.com/stretchr/testify/assert"
 
 	serviceMock "github.com/captechconsulting/go-microservice-templates/api/internal/handlers/mock"
@@ -18,8 +17,7 @@ import (
 
 func TestHandleListUsers(t *testing.T) {
 	mockService := new(serviceMock.MockUserLister)
-	logger := httplog.NewLogger("test")
-	handler := HandleListUsers(logger, mockService)
+	handler := HandleListUsers(mockService)
 
 	users := []models.User{
 		{ID: 1, FirstName: "John", LastName: "Doe", Role: "Admin", UserID: 1001},
@@ -4,8 +4,8 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
-	"github.com/go-chi/httplog/v2"
 )
 
 type userLister interface {
@@ -19,18 +19,33 @@ type userLister interface {
 // @Tags		users
 // @Accept		json
 // @Produce		json
+// @Security	BearerAuth
+// @Security	APIKeyAuth
 // @Success		200		{object}	handlers.responseUsers
+// @Failure		400		{object}	handlers.responseErr
+// @Failure		401		{object}	handlers.responseErr
+// @Failure		403		{object}	handlers.responseErr
 // @Failure		500		{object}	handlers.responseErr
 // @Router		/user	[GET]
-func HandleListUsers(logger *httplog.Logger, service userLister) http.HandlerFunc {
+// @x-v1		true
+func HandleListUsers(service userLister) http.HandlerFunc {
+	return handleListUsers(service, func(users []models.User) responseUsers {
+		return responseUsers{Users: mapMultipleOutput(users)}
+	})
+}
+
+// handleListUsers returns a Handler that returns a list of all users, mapped to the response body
+// of an API version by respond.
+func handleListUsers[O any](service userLister, respond func([]models.User) O) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
 		// setup
 		ctx := r.Context()
 
 		// get values from database
 		users, err := service.ListUsers(ctx)
 		if err != nil {
-			logger.Error("error getting all locations", "error", err)
+			logger.ErrorContext(ctx, "error getting all locations", "error", err)
 			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
 				Error: "Error retrieving data",
 			})
@@ -38,9 +53,6 @@ func HandleListUsers(logger *httplog.Logger, service userLister) http.HandlerFun
 		}
 
 		// return response
-		usersOut := mapMultipleOutput(users)
-		encodeResponse(w, logger, http.StatusOK, responseUsers{
-			Users: usersOut,
-		})
+		encodeResponse(w, logger, http.StatusOK, respond(users))
 	}
 }
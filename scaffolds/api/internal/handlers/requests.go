@@ -1,16 +1,23 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
 )
 
 type inputUser struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	FirstName string `json:"first_name" log:"sensitive"`
+	LastName  string `json:"last_name" log:"sensitive"`
 	Role      string `json:"role"`
 	UserID    int    `json:"user_id"`
 }
@@ -70,6 +77,113 @@ func (user inputUser) Valid() []problem {
 	return problems
 }
 
+// userName is the name of a user in the v2 API.
+type userName struct {
+	First string `json:"first" log:"sensitive"`
+	Last  string `json:"last" log:"sensitive"`
+}
+
+// inputUserV2 is a user in the v2 API, which nests the first and last name.
+type inputUserV2 struct {
+	Name   userName `json:"name"`
+	Role   string   `json:"role"`
+	UserID int      `json:"user_id"`
+}
+
+// MapTo maps a inputUserV2 to a models.User object.
+func (user inputUserV2) MapTo() (models.User, error) {
+	return user.v1().MapTo()
+}
+
+// Valid validates all fields of an inputUserV2 struct, like those of an inputUser.
+func (user inputUserV2) Valid() []problem {
+	problems := user.v1().Valid()
+	for i, p := range problems {
+		switch p.Name {
+		case "first_name":
+			problems[i].Name = "name.first"
+		case "last_name":
+			problems[i].Name = "name.last"
+		}
+	}
+
+	return problems
+}
+
+// v1 returns user as an inputUser.
+func (user inputUserV2) v1() inputUser {
+	return inputUser{
+		FirstName: user.Name.First,
+		LastName:  user.Name.Last,
+		Role:      user.Role,
+		UserID:    user.UserID,
+	}
+}
+
+type inputAPIKey struct {
+	Owner     string     `json:"owner"`
+	Roles     []string   `json:"roles"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// MapTo maps a inputAPIKey to a models.APIKey object.
+func (key inputAPIKey) MapTo() (models.APIKey, error) {
+	var expiresAt time.Time
+	if key.ExpiresAt != nil {
+		expiresAt = *key.ExpiresAt
+	}
+
+	return models.APIKey{
+		Owner:     key.Owner,
+		Roles:     key.Roles,
+		Scopes:    key.Scopes,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Valid validates all fields of an inputAPIKey struct.
+func (key inputAPIKey) Valid() []problem {
+	var problems []problem
+
+	// validate Owner is not blank
+	if key.Owner == "" {
+		problems = append(problems, problem{
+			Name:        "owner",
+			Description: "must not be blank",
+		})
+	}
+
+	// validate roles and scopes hold no blanks or whitespace, as they are stored space separated
+	if slices.ContainsFunc(key.Roles, notAToken) {
+		problems = append(problems, problem{
+			Name:        "roles",
+			Description: "must not be blank or contain whitespace",
+		})
+	}
+	if slices.ContainsFunc(key.Scopes, notAToken) {
+		problems = append(problems, problem{
+			Name:        "scopes",
+			Description: "must not be blank or contain whitespace",
+		})
+	}
+
+	// validate ExpiresAt is in the future, if set
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		problems = append(problems, problem{
+			Name:        "expires_at",
+			Description: "must be in the future",
+		})
+	}
+
+	return problems
+}
+
+// notAToken reports whether value is blank or contains whitespace.
+func notAToken(value string) bool {
+	return value == "" || strings.ContainsAny(value, " \t\n")
+}
+
 // problem represents an issue found during validation.
 type problem struct {
 	Name        string `json:"name"`
@@ -95,14 +209,35 @@ type ValidatorMapper[T any] interface {
 	Mapper[T]
 }
 
+// DefaultMaxBodyBytes is the maximum size of a request body decodeValidateBody reads, unless
+// another limit is set with WithMaxBodyBytes.
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+type maxBodyBytesKey struct{}
+
+// WithMaxBodyBytes returns a copy of ctx in which the request body decodeValidateBody reads is
+// limited to limit bytes.
+func WithMaxBodyBytes(ctx context.Context, limit int64) context.Context {
+	return context.WithValue(ctx, maxBodyBytesKey{}, limit)
+}
+
+// maxBodyBytes returns the body limit set on ctx, or DefaultMaxBodyBytes.
+func maxBodyBytes(ctx context.Context) int64 {
+	if limit, ok := ctx.Value(maxBodyBytesKey{}).(int64); ok {
+		return limit
+	}
+	return DefaultMaxBodyBytes
+}
+
 // decodeValidateBody decodes a JSON string into a ValidatorMapper, validates it, and maps it to
 // the output type. If decoding, validation, or mapping fails, it returns the appropriate errors
-// and problems.
-func decodeValidateBody[I ValidatorMapper[O], O any](r *http.Request) (O, []problem, error) {
+// and problems. Bodies over the limit set with WithMaxBodyBytes fail with a *http.MaxBytesError.
+func decodeValidateBody[I ValidatorMapper[O], O any](w http.ResponseWriter, r *http.Request) (O, []problem, error) {
 	var inputModel I
 
 	// decode to JSON
-	if err := json.NewDecoder(r.Body).Decode(&inputModel); err != nil {
+	body := http.MaxBytesReader(w, r.Body, maxBodyBytes(r.Context()))
+	if err := json.NewDecoder(body).Decode(&inputModel); err != nil {
 		return *new(O), nil, fmt.Errorf("[in decodeValidateBody] decode json: %w", err)
 	}
 
@@ -126,3 +261,57 @@ func decodeValidateBody[I ValidatorMapper[O], O any](r *http.Request) (O, []prob
 
 	return data, nil, nil
 }
+
+// encodeBodyError responds to a request whose body decodeValidateBody failed to decode, validate
+// or map: with a 413 problem if the body is too large, otherwise with a 400 listing problems.
+func encodeBodyError(w http.ResponseWriter, r *http.Request, problems []problem, err error) {
+	logger := logging.FromContext(r.Context())
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		logger.ErrorContext(r.Context(), "Request body too large", "error", err, "limit", tooLarge.Limit)
+		encodeProblem(w, logger, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("request body must not be larger than %d bytes", tooLarge.Limit))
+	case len(problems) > 0:
+		logger.ErrorContext(r.Context(), "Problems validating input", "error", err, "problems", problems)
+		encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+			ValidationErrors: problems,
+		})
+	default:
+		logger.ErrorContext(r.Context(), "BodyParser error", "error", err)
+		encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+			Error: "missing values or malformed body",
+		})
+	}
+}
+
+type inputLogLevel struct {
+	Level string `json:"level"`
+}
+
+// MapTo maps a inputLogLevel to a slog.Level.
+func (input inputLogLevel) MapTo() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(input.Level)); err != nil {
+		return 0, fmt.Errorf("[in inputLogLevel.MapTo]: %w", err)
+	}
+
+	return level, nil
+}
+
+// Valid validates all fields of an inputLogLevel struct.
+func (input inputLogLevel) Valid() []problem {
+	var problems []problem
+
+	// validate level is a slog level, e.g. `DEBUG` or `WARN+2`
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(input.Level)); err != nil {
+		problems = append(problems, problem{
+			Name:        "level",
+			Description: `must be "DEBUG", "INFO", "WARN" or "ERROR"`,
+		})
+	}
+
+	return problems
+}
@@ -2,16 +2,18 @@ package handlers
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/captechconsulting/go-microservice-templates/api/internal/database"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
-	"github.com/go-chi/httplog/v2"
 )
 
 type outputUser struct {
 	ID        int    `json:"id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	FirstName string `json:"first_name" log:"sensitive"`
+	LastName  string `json:"last_name" log:"sensitive"`
 	Role      string `json:"role"`
 	UserID    int    `json:"user_id"`
 }
@@ -46,6 +48,107 @@ type responseUsers struct {
 	Users []outputUser `json:"users"`
 }
 
+// outputUserV2 is a user in the v2 API, which nests the first and last name.
+type outputUserV2 struct {
+	ID     int      `json:"id"`
+	Name   userName `json:"name"`
+	Role   string   `json:"role"`
+	UserID int      `json:"user_id"`
+}
+
+// mapOutputV2 maps a models.User struct to an outputUserV2 struct.
+func mapOutputV2(user models.User) outputUserV2 {
+	return outputUserV2{
+		ID:     int(user.ID),
+		Name:   userName{First: user.FirstName, Last: user.LastName},
+		Role:   user.Role,
+		UserID: int(user.UserID),
+	}
+}
+
+type responseUserV2 struct {
+	User outputUserV2 `json:"user"`
+}
+
+type responseUsersV2 struct {
+	Users []outputUserV2 `json:"users"`
+}
+
+type outputAPIKey struct {
+	ID         int        `json:"id"`
+	Prefix     string     `json:"prefix"`
+	Owner      string     `json:"owner"`
+	Roles      []string   `json:"roles"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// mapAPIKeyOutput maps a models.APIKey struct to an outputAPIKey struct.
+func mapAPIKeyOutput(key models.APIKey) outputAPIKey {
+	return outputAPIKey{
+		ID:         int(key.ID),
+		Prefix:     key.Prefix,
+		Owner:      key.Owner,
+		Roles:      key.Roles,
+		Scopes:     key.Scopes,
+		ExpiresAt:  optionalTime(key.ExpiresAt),
+		LastUsedAt: optionalTime(key.LastUsedAt),
+		RevokedAt:  optionalTime(key.RevokedAt),
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// mapMultipleAPIKeyOutput maps a slice of []models.APIKey to a slice of []outputAPIKey.
+func mapMultipleAPIKeyOutput(keys []models.APIKey) []outputAPIKey {
+	keysOut := make([]outputAPIKey, len(keys))
+	for i := 0; i < len(keys); i++ {
+		keysOut[i] = mapAPIKeyOutput(keys[i])
+	}
+
+	return keysOut
+}
+
+// optionalTime maps the zero time to nil.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+type responseCreatedAPIKey struct {
+	APIKey outputAPIKey `json:"api_key"`
+	// Key is the plain API key. It is only returned once and cannot be recovered.
+	Key string `json:"key"`
+}
+
+type responseAPIKeys struct {
+	APIKeys []outputAPIKey `json:"api_keys"`
+}
+
+type responseLogLevel struct {
+	Level string `json:"level"`
+}
+
+type responseQueryStats struct {
+	Statements []database.StatementStats `json:"statements"`
+}
+
+type outputModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+type responseBuildInfo struct {
+	GoVersion string            `json:"go_version"`
+	Main      outputModule      `json:"main"`
+	Settings  map[string]string `json:"settings"`
+	Deps      []outputModule    `json:"deps"`
+}
+
 type responseMsg struct {
 	Message string `json:"message"`
 }
@@ -59,8 +162,30 @@ type responseErr struct {
 	ValidationErrors []problem `json:"validation_errors,omitempty"`
 }
 
+// problemDetails is an RFC 9457 problem details body.
+type problemDetails struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// encodeProblem writes a problem details body with the given status code.
+func encodeProblem(w http.ResponseWriter, logger *slog.Logger, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(problemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}); err != nil {
+		logger.Error("Error while marshaling problem", "err", err, "status", status)
+	}
+}
+
 // encodeResponse encodes data as a JSON response.
-func encodeResponse(w http.ResponseWriter, logger *httplog.Logger, status int, data any) {
+func encodeResponse(w http.ResponseWriter, logger *slog.Logger, status int, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type apiKeyRevoker interface {
+	RevokeAPIKey(ctx context.Context, ID int) error
+}
+
+// HandleRevokeAPIKey is a Handler that revokes an API key by ID.
+//
+// @Summary		Revoke an API key by ID
+// @Description	Revoke an API key by ID. Cached validations of the key expire within the API key cache TTL.
+// @Tags		api-keys
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Security	APIKeyAuth
+// @Param		id						path		int		true	"API Key ID"
+// @Success		200						{object}	handlers.responseMsg
+// @Failure		400						{object}	handlers.responseErr
+// @Failure		401						{object}	handlers.responseErr
+// @Failure		403						{object}	handlers.responseErr
+// @Failure		404						{object}	handlers.responseErr
+// @Failure		500						{object}	handlers.responseErr
+// @Router		/admin/api-keys/{ID}	[DELETE]
+// @x-v1		true
+// @x-v2		true
+func HandleRevokeAPIKey(service apiKeyRevoker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
+		// setup
+		ctx := r.Context()
+
+		// get and validate ID
+		idString := chi.URLParam(r, "ID")
+		ID, err := strconv.Atoi(idString)
+		if err != nil {
+			logger.ErrorContext(ctx, "error getting ID", "error", err)
+			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
+				Error: "Not a valid ID",
+			})
+			return
+		}
+
+		// revoke object in database
+		if err := service.RevokeAPIKey(ctx, ID); err != nil {
+			if errors.Is(err, services.ErrNotFound) {
+				encodeResponse(w, logger, http.StatusNotFound, responseErr{
+					Error: "API key not found",
+				})
+				return
+			}
+
+			logger.ErrorContext(ctx, "error revoking object in database", "error", err)
+			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
+				Error: "Error revoking object",
+			})
+			return
+		}
+
+		// return response
+		encodeResponse(w, logger, http.StatusOK, responseMsg{
+			Message: "API key revoked",
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serviceMock "github.com/captechconsulting/go-microservice-templates/api/internal/handlers/mock"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/services"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/testutil"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleRevokeAPIKey(t *testing.T) {
+	mockService := new(serviceMock.MockApiKeyRevoker)
+	handler := HandleRevokeAPIKey(mockService)
+
+	tests := map[string]struct {
+		mockCalled     bool
+		mockOutput     error
+		requestIDParam string
+		expectedCode   int
+		expectedBody   string
+	}{
+		"valid request, api key revoked": {
+			mockCalled:     true,
+			mockOutput:     nil,
+			requestIDParam: "1",
+			expectedCode:   http.StatusOK,
+			expectedBody:   testutil.ToJSONString(responseMsg{Message: "API key revoked"}),
+		},
+		"invalid ID": {
+			mockCalled:     false,
+			requestIDParam: "abc",
+			expectedCode:   http.StatusBadRequest,
+			expectedBody:   testutil.ToJSONString(responseErr{Error: "Not a valid ID"}),
+		},
+		"api key not found": {
+			mockCalled:     true,
+			mockOutput:     fmt.Errorf("[in services.RevokeAPIKey] api key 1: %w", services.ErrNotFound),
+			requestIDParam: "1",
+			expectedCode:   http.StatusNotFound,
+			expectedBody:   testutil.ToJSONString(responseErr{Error: "API key not found"}),
+		},
+		"error revoking api key": {
+			mockCalled:     true,
+			mockOutput:     errors.New("test"),
+			requestIDParam: "1",
+			expectedCode:   http.StatusInternalServerError,
+			expectedBody:   testutil.ToJSONString(responseErr{Error: "Error revoking object"}),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodDelete, "/api/admin/api-keys/"+tc.requestIDParam, nil)
+			assert.NoError(t, err)
+
+			// Add chi URLParam
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("ID", tc.requestIDParam)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			req = req.WithContext(ctx)
+
+			if tc.mockCalled {
+				mockService.
+					On("RevokeAPIKey", ctx, 1).
+					Return(tc.mockOutput).
+					Once()
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String(), "Wrong response body")
+
+			if tc.mockCalled {
+				mockService.AssertExpectations(t)
+			} else {
+				mockService.AssertNotCalled(t, "RevokeAPIKey")
+			}
+		})
+	}
+}
@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/services"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/httplog/v2"
 )
 
 type userUpdater interface {
@@ -21,14 +23,31 @@ type userUpdater interface {
 // @Tags		user
 // @Accept		json
 // @Produce		json
+// @Security	BearerAuth
+// @Security	APIKeyAuth
 // @Param		id			path		int	true						"User ID"
 // @Param		user		body		handlers.inputUser		true	"User Object"
 // @Success		200			{object}	handlers.responseUser
+// @Failure		400			{object}	handlers.responseErr
+// @Failure		401			{object}	handlers.responseErr
+// @Failure		403			{object}	handlers.responseErr
+// @Failure		404			{object}	handlers.responseErr
+// @Failure		413			{object}	handlers.problemDetails
 // @Failure		500			{object}	handlers.responseErr
 // @Failure		422			{object}	handlers.responseErr
 // @Router		/user/{ID}	[PUT]
-func HandleUpdateUser(logger *httplog.Logger, service userUpdater) http.HandlerFunc {
+// @x-v1		true
+func HandleUpdateUser(service userUpdater) http.HandlerFunc {
+	return handleUpdateUser[inputUser](service, func(user models.User) responseUser {
+		return responseUser{User: mapOutput(user)}
+	})
+}
+
+// handleUpdateUser returns a Handler that updates a user based on a user object of type I from
+// the request body, and returns it mapped to the response body of an API version by respond.
+func handleUpdateUser[I ValidatorMapper[models.User], O any](service userUpdater, respond func(models.User) O) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context())
 		// setup
 		ctx := r.Context()
 
@@ -36,7 +55,7 @@ func HandleUpdateUser(logger *httplog.Logger, service userUpdater) http.HandlerF
 		idString := chi.URLParam(r, "ID")
 		ID, err := strconv.Atoi(idString)
 		if err != nil {
-			logger.Error("error getting ID", "error", err)
+			logger.ErrorContext(ctx, "error getting ID", "error", err)
 			encodeResponse(w, logger, http.StatusBadRequest, responseErr{
 				Error: "Not a valid ID",
 			})
@@ -44,27 +63,23 @@ func HandleUpdateUser(logger *httplog.Logger, service userUpdater) http.HandlerF
 		}
 
 		// get and validate body as object
-		userIn, problems, err := decodeValidateBody[inputUser, models.User](r)
+		userIn, problems, err := decodeValidateBody[I, models.User](w, r)
 		if err != nil {
-			switch {
-			case len(problems) > 0:
-				logger.Error("Problems validating input", "error", err, "problems", problems)
-				encodeResponse(w, logger, http.StatusBadRequest, responseErr{
-					ValidationErrors: problems,
-				})
-			default:
-				logger.Error("BodyParser error", "error", err)
-				encodeResponse(w, logger, http.StatusBadRequest, responseErr{
-					Error: "missing values or malformed body",
-				})
-			}
+			encodeBodyError(w, r, problems, err)
 			return
 		}
 
 		// update object in database
 		user, err := service.UpdateUser(ctx, ID, userIn)
 		if err != nil {
-			logger.Error("error updating object in database", "error", err)
+			if errors.Is(err, services.ErrNotFound) {
+				encodeResponse(w, logger, http.StatusNotFound, responseErr{
+					Error: "User not found",
+				})
+				return
+			}
+
+			logger.ErrorContext(ctx, "error updating object in database", "error", err)
 			encodeResponse(w, logger, http.StatusInternalServerError, responseErr{
 				Error: "Error updating object",
 			})
@@ -72,9 +87,6 @@ func HandleUpdateUser(logger *httplog.Logger, service userUpdater) http.HandlerF
 		}
 
 		// return response
-		userOut := mapOutput(user)
-		encodeResponse(w, logger, http.StatusOK, responseUser{
-			User: userOut,
-		})
+		encodeResponse(w, logger, http.StatusOK, respond(user))
 	}
 }
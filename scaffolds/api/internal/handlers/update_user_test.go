@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,16 +11,15 @@ import (
 
 	serviceMock "github.com/captechconsulting/go-microservice-templates/api/internal/handlers/mock"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/services"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/testutil"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/httplog/v2"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestHandleUpdateUser(t *testing.T) {
 	mockService := new(serviceMock.MockUserUpdater)
-	logger := httplog.NewLogger("test")
-	handler := HandleUpdateUser(logger, mockService)
+	handler := HandleUpdateUser(mockService)
 
 	user := models.User{FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
 	userIn := inputUser{FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
@@ -67,6 +67,15 @@ func TestHandleUpdateUser(t *testing.T) {
 				},
 			}),
 		},
+		"user not found": {
+			mockCalled:     true,
+			mockInput:      []any{1, user},
+			mockOutput:     []any{models.User{}, fmt.Errorf("[in services.UpdateUser] user 1: %w", services.ErrNotFound)},
+			requestIDParam: "1",
+			requestBody:    testutil.ToJSONString(userIn),
+			expectedCode:   http.StatusNotFound,
+			expectedBody:   testutil.ToJSONString(responseErr{Error: "User not found"}),
+		},
 		"error creating user": {
 			mockCalled:     true,
 			mockInput:      []any{1, user},
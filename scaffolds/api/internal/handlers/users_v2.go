@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+)
+
+// HandleListUsersV2 is a Handler that returns a list of all users in the v2 representation.
+//
+// @Summary		List all users
+// @Description	List all users
+// @Tags		users
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Security	APIKeyAuth
+// @Success		200		{object}	handlers.responseUsersV2
+// @Failure		400		{object}	handlers.responseErr
+// @Failure		401		{object}	handlers.responseErr
+// @Failure		403		{object}	handlers.responseErr
+// @Failure		500		{object}	handlers.responseErr
+// @Router		/user	[GET]
+// @x-v2		true
+func HandleListUsersV2(service userLister) http.HandlerFunc {
+	return handleListUsers(service, func(users []models.User) responseUsersV2 {
+		usersOut := make([]outputUserV2, len(users))
+		for i, user := range users {
+			usersOut[i] = mapOutputV2(user)
+		}
+		return responseUsersV2{Users: usersOut}
+	})
+}
+
+// HandleGetUserV2 is a Handler that returns a single user by ID in the v2 representation.
+//
+// @Summary		Get a user by ID
+// @Description	Get a user by ID
+// @Tags		user
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Security	APIKeyAuth
+// @Param		id			path		int		true	"User ID"
+// @Success		200			{object}	handlers.responseUserV2
+// @Failure		400			{object}	handlers.responseErr
+// @Failure		401			{object}	handlers.responseErr
+// @Failure		403			{object}	handlers.responseErr
+// @Failure		404			{object}	handlers.responseErr
+// @Failure		500			{object}	handlers.responseErr
+// @Router		/user/{ID}	[GET]
+// @x-v2		true
+func HandleGetUserV2(service userGetter) http.HandlerFunc {
+	return handleGetUser(service, func(user models.User) responseUserV2 {
+		return responseUserV2{User: mapOutputV2(user)}
+	})
+}
+
+// HandleUpdateUserV2 is a Handler that updates a user based on a v2 user object from the request
+// body.
+//
+// @Summary		Update a user by ID
+// @Description	Update a user by ID
+// @Tags		user
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Security	APIKeyAuth
+// @Param		id			path		int	true						"User ID"
+// @Param		user		body		handlers.inputUserV2	true	"User Object"
+// @Success		200			{object}	handlers.responseUserV2
+// @Failure		400			{object}	handlers.responseErr
+// @Failure		401			{object}	handlers.responseErr
+// @Failure		403			{object}	handlers.responseErr
+// @Failure		404			{object}	handlers.responseErr
+// @Failure		413			{object}	handlers.problemDetails
+// @Failure		500			{object}	handlers.responseErr
+// @Failure		422			{object}	handlers.responseErr
+// @Router		/user/{ID}	[PUT]
+// @x-v2		true
+func HandleUpdateUserV2(service userUpdater) http.HandlerFunc {
+	return handleUpdateUser[inputUserV2](service, func(user models.User) responseUserV2 {
+		return responseUserV2{User: mapOutputV2(user)}
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	serviceMock "github.com/captechconsulting/go-microservice-templates/api/internal/handlers/mock"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleListUsersV2(t *testing.T) {
+	mockService := new(serviceMock.MockUserLister)
+	users := []models.User{{ID: 1, FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}}
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/user", nil)
+	mockService.On("ListUsers", req.Context()).Return(users, nil).Once()
+
+	rr := httptest.NewRecorder()
+	HandleListUsersV2(mockService).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code, "Wrong code received")
+	assert.JSONEq(t,
+		`{"users":[{"id":1,"name":{"first":"John","last":"Doe"},"role":"Customer","user_id":1001}]}`,
+		rr.Body.String(),
+		"Wrong response body",
+	)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandleUpdateUserV2(t *testing.T) {
+	user := models.User{FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
+
+	tests := map[string]struct {
+		mockCalled   bool
+		requestBody  string
+		expectedCode int
+		expectedBody string
+	}{
+		"valid request, user updated": {
+			mockCalled:   true,
+			requestBody:  `{"name":{"first":"John","last":"Doe"},"role":"Customer","user_id":1001}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"user":{"id":0,"name":{"first":"John","last":"Doe"},"role":"Customer","user_id":1001}}`,
+		},
+		"v1 request body": {
+			mockCalled:   false,
+			requestBody:  `{"first_name":"John","last_name":"Doe","role":"Customer","user_id":1001}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"validation_errors":[` +
+				`{"name":"name.first","description":"must not be blank"},` +
+				`{"name":"name.last","description":"must not be blank"}]}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockService := new(serviceMock.MockUserUpdater)
+
+			req := httptest.NewRequest(http.MethodPut, "/v2/user/1", strings.NewReader(tc.requestBody))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("ID", "1")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			req = req.WithContext(ctx)
+
+			if tc.mockCalled {
+				mockService.On("UpdateUser", ctx, 1, user).Return(user, nil).Once()
+			}
+
+			rr := httptest.NewRecorder()
+			HandleUpdateUserV2(mockService).ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String(), "Wrong response body")
+
+			if tc.mockCalled {
+				mockService.AssertExpectations(t)
+			} else {
+				mockService.AssertNotCalled(t, "UpdateUser")
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status is the status of a single check or of the whole service.
+type Status string
+
+const (
+	// StatusOK means every check passed.
+	StatusOK Status = "ok"
+	// StatusDegraded means a non-critical check failed. The service is still ready.
+	StatusDegraded Status = "degraded"
+	// StatusUnavailable means a critical check failed or the service is shutting down.
+	StatusUnavailable Status = "unavailable"
+)
+
+// errShuttingDown is reported instead of running the checks once Shutdown is called.
+var errShuttingDown = errors.New("shutting down")
+
+// Checker reports whether a dependency is available. Check must return once ctx is done.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to a Checker.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f.
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Pinger is implemented by *sql.DB, for primaries and replicas alike.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// DB returns a Checker that pings db.
+func DB(db Pinger) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("[in health.DB] failed to ping database: %w", err)
+		}
+		return nil
+	})
+}
+
+// HTTP returns a Checker that sends a GET request to url with client. Responses with a status code
+// below 400 pass.
+func HTTP(client *http.Client, url string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("[in health.HTTP] failed to create request: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("[in health.HTTP] failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("[in health.HTTP] unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// CheckResult is the outcome of a single check.
+type CheckResult struct {
+	Status    Status    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Duration  float64   `json:"duration_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the outcome of all checks.
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckOption configures a check, see Health.Register.
+type CheckOption func(*check)
+
+// WithTimeout overrides the timeout of the check.
+func WithTimeout(timeout time.Duration) CheckOption {
+	return func(c *check) {
+		c.timeout = timeout
+	}
+}
+
+// NonCritical makes a failure of the check degrade the service instead of making it unavailable,
+// e.g. for outbound dependencies that only some routes need.
+func NonCritical() CheckOption {
+	return func(c *check) {
+		c.critical = false
+	}
+}
+
+type check struct {
+	name     string
+	checker  Checker
+	timeout  time.Duration
+	critical bool
+
+	// mu is held while the check runs, so concurrent probes share a single run
+	mu     sync.Mutex
+	result CheckResult
+}
+
+// Health runs the checks of the dependencies the service needs to handle requests. Results are
+// cached, so frequent probes do not put load on the dependencies.
+type Health struct {
+	timeout      time.Duration
+	cacheTTL     time.Duration
+	checks       []*check
+	shuttingDown atomic.Bool
+	now          func() time.Time
+}
+
+// New returns a Health without checks. Checks time out after timeout unless registered with
+// WithTimeout, and their results are cached for cacheTTL.
+func New(timeout time.Duration, cacheTTL time.Duration) *Health {
+	return &Health{
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+	}
+}
+
+// Register adds a check named name. Checks are critical unless registered with NonCritical.
+// Register must not be called once the checks are running.
+func (h *Health) Register(name string, checker Checker, opts ...CheckOption) {
+	c := &check{
+		name:     name,
+		checker:  checker,
+		timeout:  h.timeout,
+		critical: true,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	h.checks = append(h.checks, c)
+}
+
+// Shutdown makes every later report unavailable, so load balancers stop routing requests to the
+// service while it drains.
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// Ready runs all checks concurrently and reports whether the service is ready to handle requests.
+func (h *Health) Ready(ctx context.Context) Report {
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(h.checks)),
+	}
+	if h.shuttingDown.Load() {
+		report.Status = StatusUnavailable
+		report.Checks["shutdown"] = CheckResult{
+			Status:    StatusUnavailable,
+			Critical:  true,
+			Error:     errShuttingDown.Error(),
+			CheckedAt: h.now(),
+		}
+		return report
+	}
+
+	results := make([]CheckResult, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for i, c := range h.checks {
+		result := results[i]
+		report.Checks[c.name] = result
+		switch {
+		case result.Status == StatusOK:
+		case result.Critical:
+			report.Status = StatusUnavailable
+		case report.Status == StatusOK:
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+// run returns the cached result of c, or runs it if the result is older than the cache TTL.
+func (h *Health) run(ctx context.Context, c *check) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.result.CheckedAt.IsZero() && h.now().Sub(c.result.CheckedAt) < h.cacheTTL {
+		return c.result
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// the checker runs on its own goroutine, so a checker that ignores ctx cannot block the probe
+	start := h.now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.checker.Check(checkCtx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-checkCtx.Done():
+		err = checkCtx.Err()
+	}
+
+	result := CheckResult{
+		Status:    StatusOK,
+		Critical:  c.critical,
+		Duration:  float64(h.now().Sub(start).Microseconds()) / 1000,
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+
+	// a probe that gave up says nothing about the dependency, so its result is not cached
+	if ctx.Err() == nil {
+		c.result = result
+	}
+	return result
+}
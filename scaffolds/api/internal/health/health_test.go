@@ -0,0 +1,142 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReady(t *testing.T) {
+	ok := CheckerFunc(func(ctx context.Context) error { return nil })
+	failing := CheckerFunc(func(ctx context.Context) error { return errors.New("connection refused") })
+	// hanging ignores ctx, the check must time out anyway
+	hanging := CheckerFunc(func(ctx context.Context) error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+
+	tests := map[string]struct {
+		register       func(h *Health)
+		expectedStatus Status
+		expectedChecks map[string]Status
+	}{
+		"no checks": {
+			register:       func(h *Health) {},
+			expectedStatus: StatusOK,
+			expectedChecks: map[string]Status{},
+		},
+		"all checks pass": {
+			register: func(h *Health) {
+				h.Register("database", ok)
+				h.Register("cache", ok)
+			},
+			expectedStatus: StatusOK,
+			expectedChecks: map[string]Status{"database": StatusOK, "cache": StatusOK},
+		},
+		"critical check fails": {
+			register: func(h *Health) {
+				h.Register("database", failing)
+				h.Register("jwks", ok, NonCritical())
+			},
+			expectedStatus: StatusUnavailable,
+			expectedChecks: map[string]Status{"database": StatusUnavailable, "jwks": StatusOK},
+		},
+		"non-critical check fails": {
+			register: func(h *Health) {
+				h.Register("database", ok)
+				h.Register("jwks", failing, NonCritical())
+			},
+			expectedStatus: StatusDegraded,
+			expectedChecks: map[string]Status{"database": StatusOK, "jwks": StatusUnavailable},
+		},
+		"check times out": {
+			register: func(h *Health) {
+				h.Register("database", hanging, WithTimeout(10*time.Millisecond))
+			},
+			expectedStatus: StatusUnavailable,
+			expectedChecks: map[string]Status{"database": StatusUnavailable},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := New(time.Second, 0)
+			tc.register(h)
+
+			report := h.Ready(context.Background())
+
+			assert.Equal(t, tc.expectedStatus, report.Status)
+			checks := map[string]Status{}
+			for name, result := range report.Checks {
+				checks[name] = result.Status
+				if result.Status != StatusOK {
+					assert.NotEmpty(t, result.Error, name)
+				}
+			}
+			assert.Equal(t, tc.expectedChecks, checks)
+		})
+	}
+}
+
+func TestReadyCache(t *testing.T) {
+	var calls atomic.Int32
+	h := New(time.Second, time.Minute)
+	h.Register("database", CheckerFunc(func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	h.now = func() time.Time { return now }
+
+	h.Ready(context.Background())
+	h.Ready(context.Background())
+	assert.Equal(t, int32(1), calls.Load())
+
+	now = now.Add(time.Minute)
+	h.Ready(context.Background())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestShutdown(t *testing.T) {
+	h := New(time.Second, 0)
+	h.Register("database", CheckerFunc(func(ctx context.Context) error { return nil }))
+
+	assert.Equal(t, StatusOK, h.Ready(context.Background()).Status)
+
+	h.Shutdown()
+	report := h.Ready(context.Background())
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.Equal(t, "shutting down", report.Checks["shutdown"].Error)
+}
+
+func TestHTTP(t *testing.T) {
+	tests := map[string]struct {
+		status    int
+		expectErr bool
+	}{
+		"ok":           {status: http.StatusOK},
+		"server error": {status: http.StatusBadGateway, expectErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			err := HTTP(server.Client(), server.URL).Check(context.Background())
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Hook is a component of the application, e.g. a server, a connection pool or a telemetry
+// exporter, that is started and stopped by a Manager.
+type Hook struct {
+	Name string
+	// OnStart starts the component. It must not block, long-running work like serving requests is
+	// run with Manager.Go. Hooks without OnStart are considered started when they are appended,
+	// e.g. a database connection opened during setup.
+	OnStart func(ctx context.Context) error
+	// OnStop stops the component. It should return once ctx is done.
+	OnStop func(ctx context.Context) error
+	// Timeout bounds OnStop. If it is zero, the timeout of the Manager is used.
+	Timeout time.Duration
+}
+
+// Option configures New.
+type Option func(*Manager)
+
+// WithStopTimeout sets the time each hook is given to stop, unless it sets its own Timeout. If
+// this function is not called, hooks are given 10 seconds.
+func WithStopTimeout(timeout time.Duration) Option {
+	return func(m *Manager) {
+		m.stopTimeout = timeout
+	}
+}
+
+// WithDrainDelay waits delay between failing the readiness probe and stopping the first hook, so
+// load balancers stop routing requests to the service before its servers stop accepting them. If
+// this function is not called, hooks are stopped immediately.
+func WithDrainDelay(delay time.Duration) Option {
+	return func(m *Manager) {
+		m.drainDelay = delay
+	}
+}
+
+// entry is an appended Hook and whether it was started.
+type entry struct {
+	Hook
+	started bool
+}
+
+// Manager starts hooks in the order they are appended and stops them in reverse order when the
+// process receives a termination signal or a component fails.
+type Manager struct {
+	logger      *slog.Logger
+	stopTimeout time.Duration
+	drainDelay  time.Duration
+
+	mu      sync.Mutex
+	hooks   []entry
+	drain   []func()
+	running bool
+
+	failed       chan error
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// New returns a Manager logging with logger.
+func New(logger *slog.Logger, opts ...Option) *Manager {
+	m := &Manager{
+		logger:      logger,
+		stopTimeout: 10 * time.Second,
+		failed:      make(chan error, 1),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Append adds h. It is started by Run after the hooks appended before it, and stopped before
+// them.
+func (m *Manager) Append(h Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, entry{Hook: h, started: h.OnStart == nil})
+}
+
+// AppendServer appends a hook that serves with serve, e.g. server.ListenAndServe, in the
+// background and shuts server down gracefully, waiting up to timeout for active requests to
+// complete. If timeout is zero, the timeout of the Manager is used.
+func (m *Manager) AppendServer(name string, server *http.Server, serve func() error, timeout time.Duration) {
+	m.Append(Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			m.Go(name, func() error {
+				if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					return err
+				}
+				return nil
+			})
+			return nil
+		},
+		OnStop:  server.Shutdown,
+		Timeout: timeout,
+	})
+}
+
+// OnDrain registers f to be called first when shutting down, before the drain delay, e.g. to
+// fail the readiness probe.
+func (m *Manager) OnDrain(f func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.drain = append(m.drain, f)
+}
+
+// Go runs a background worker. If run returns an error, the application is shut down and Run
+// returns the error.
+func (m *Manager) Go(name string, run func() error) {
+	go func() {
+		if err := run(); err != nil {
+			select {
+			case m.failed <- fmt.Errorf("[in lifecycle.Manager.Go] %s failed: %w", name, err):
+			default:
+				// the application is already shutting down
+				m.logger.Error("Component failed", "component", name, "err", err)
+			}
+		}
+	}()
+}
+
+// Run starts the hooks and blocks until ctx is done, the process receives SIGHUP, SIGINT,
+// SIGTERM or SIGQUIT, or a worker started with Go fails, and then shuts down, see Shutdown. It
+// returns nil if the application stopped cleanly, so the process can exit with a failure code
+// otherwise. A second signal terminates the process immediately.
+func (m *Manager) Run(ctx context.Context) error {
+	if err := m.start(ctx); err != nil {
+		return errors.Join(err, m.Shutdown())
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer stop()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		m.logger.Info("Shutdown signal received")
+	case err = <-m.failed:
+		m.logger.Error("Component failed, shutting down", "err", err)
+	}
+	stop()
+
+	return errors.Join(err, m.Shutdown())
+}
+
+// start starts the hooks in order. It stops at the first that fails.
+func (m *Manager) start(ctx context.Context) error {
+	m.mu.Lock()
+	n := len(m.hooks)
+	m.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		m.mu.Lock()
+		h := m.hooks[i]
+		m.mu.Unlock()
+		if h.started {
+			continue
+		}
+
+		if err := h.OnStart(ctx); err != nil {
+			return fmt.Errorf("[in lifecycle.Manager.Run] failed to start %s: %w", h.Name, err)
+		}
+		m.mu.Lock()
+		m.hooks[i].started = true
+		m.mu.Unlock()
+	}
+
+	m.mu.Lock()
+	m.running = true
+	m.mu.Unlock()
+	return nil
+}
+
+// Shutdown calls the functions registered with OnDrain, waits for the drain delay and stops the
+// started hooks in reverse order, each within its timeout. A hook that fails or times out does not
+// keep the others from stopping. The drain is skipped if the hooks were not all started, e.g. when
+// setup fails. Only the first call has an effect, later calls return the same error.
+func (m *Manager) Shutdown() error {
+	m.shutdownOnce.Do(func() {
+		m.mu.Lock()
+		running, drain := m.running, m.drain
+		var started []Hook
+		for _, h := range m.hooks {
+			if h.started {
+				started = append(started, h.Hook)
+			}
+		}
+		m.mu.Unlock()
+
+		if running {
+			for _, f := range drain {
+				f()
+			}
+			if m.drainDelay > 0 {
+				m.logger.Info("Draining before stopping", "delay", m.drainDelay.String())
+				time.Sleep(m.drainDelay)
+			}
+		}
+
+		var errs []error
+		for i := len(started) - 1; i >= 0; i-- {
+			if err := m.stop(started[i]); err != nil {
+				m.logger.Error("Error stopping component", "component", started[i].Name, "err", err)
+				errs = append(errs, err)
+			}
+		}
+		m.shutdownErr = errors.Join(errs...)
+
+		if running && m.shutdownErr == nil {
+			m.logger.Info("Shutdown complete")
+		}
+	})
+
+	return m.shutdownErr
+}
+
+// stop calls the OnStop of hook and waits until it returns or its timeout expires.
+func (m *Manager) stop(hook Hook) error {
+	if hook.OnStop == nil {
+		return nil
+	}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = m.stopTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.OnStop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("[in lifecycle.Manager.Shutdown] failed to stop %s: %w", hook.Name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("[in lifecycle.Manager.Shutdown] %s did not stop within %s: %w", hook.Name, timeout, ctx.Err())
+	}
+}
+
+// Closer adapts a close function, e.g. sql.DB.Close, to the OnStop of a Hook.
+func Closer(closeFunc func() error) func(context.Context) error {
+	return func(context.Context) error {
+		return closeFunc()
+	}
+}
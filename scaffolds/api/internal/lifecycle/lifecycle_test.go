@@ -0,0 +1,209 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	tests := map[string]struct {
+		hooks          func(m *Manager, record func(event string))
+		stopped        bool
+		expectedEvents []string
+		expectedErr    string
+	}{
+		"hooks stopped in reverse order after drain": {
+			hooks: func(m *Manager, record func(string)) {
+				m.Append(Hook{Name: "database", OnStop: func(context.Context) error {
+					record("stop database")
+					return nil
+				}})
+				m.Append(Hook{
+					Name:    "worker",
+					OnStart: func(context.Context) error { record("start worker"); return nil },
+					OnStop:  func(context.Context) error { record("stop worker"); return nil },
+				})
+				m.Append(Hook{
+					Name:    "server",
+					OnStart: func(context.Context) error { record("start server"); return nil },
+					OnStop:  func(context.Context) error { record("stop server"); return nil },
+				})
+			},
+			stopped: true,
+			expectedEvents: []string{
+				"start worker", "start server", "drain", "stop server", "stop worker", "stop database",
+			},
+		},
+		"hooks appended without OnStart keep their position": {
+			hooks: func(m *Manager, record func(string)) {
+				m.Append(Hook{
+					Name:    "tracer",
+					OnStart: func(context.Context) error { record("start tracer"); return nil },
+					OnStop:  func(context.Context) error { record("stop tracer"); return nil },
+				})
+				m.Append(Hook{Name: "database", OnStop: func(context.Context) error {
+					record("stop database")
+					return nil
+				}})
+				m.Append(Hook{
+					Name:    "server",
+					OnStart: func(context.Context) error { record("start server"); return nil },
+					OnStop:  func(context.Context) error { record("stop server"); return nil },
+				})
+				m.Append(Hook{Name: "cache", OnStop: func(context.Context) error {
+					record("stop cache")
+					return nil
+				}})
+			},
+			stopped: true,
+			expectedEvents: []string{
+				"start tracer", "start server", "drain", "stop cache", "stop server", "stop database", "stop tracer",
+			},
+		},
+		"failed start stops started hooks without drain": {
+			hooks: func(m *Manager, record func(string)) {
+				m.Append(Hook{
+					Name:    "database",
+					OnStart: func(context.Context) error { record("start database"); return nil },
+					OnStop:  func(context.Context) error { record("stop database"); return nil },
+				})
+				m.Append(Hook{
+					Name:    "server",
+					OnStart: func(context.Context) error { return errors.New("address in use") },
+					OnStop:  func(context.Context) error { record("stop server"); return nil },
+				})
+			},
+			stopped:        true,
+			expectedEvents: []string{"start database", "stop database"},
+			expectedErr:    "failed to start server: address in use",
+		},
+		"failed worker shuts down": {
+			hooks: func(m *Manager, record func(string)) {
+				m.Append(Hook{
+					Name: "consumer",
+					OnStart: func(context.Context) error {
+						m.Go("consumer", func() error { return errors.New("connection lost") })
+						return nil
+					},
+					OnStop: func(context.Context) error { record("stop consumer"); return nil },
+				})
+			},
+			expectedEvents: []string{"drain", "stop consumer"},
+			expectedErr:    "consumer failed: connection lost",
+		},
+		"hook timing out does not keep others from stopping": {
+			hooks: func(m *Manager, record func(string)) {
+				m.Append(Hook{Name: "database", OnStop: func(context.Context) error {
+					record("stop database")
+					return nil
+				}})
+				// flusher ignores ctx, it must time out anyway
+				m.Append(Hook{Name: "flusher", Timeout: 10 * time.Millisecond, OnStop: func(context.Context) error {
+					time.Sleep(200 * time.Millisecond)
+					return nil
+				}})
+			},
+			stopped:        true,
+			expectedEvents: []string{"drain", "stop database"},
+			expectedErr:    "flusher did not stop within 10ms: context deadline exceeded",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				mu     sync.Mutex
+				events []string
+			)
+			record := func(event string) {
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, event)
+			}
+
+			m := New(slog.New(slog.NewTextHandler(io.Discard, nil)), WithDrainDelay(time.Millisecond))
+			m.OnDrain(func() { record("drain") })
+			tc.hooks(m, record)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tc.stopped {
+				cancel()
+			}
+			defer cancel()
+
+			err := m.Run(ctx)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedErr)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, tc.expectedEvents, events)
+		})
+	}
+}
+
+func TestShutdownBeforeRun(t *testing.T) {
+	var drained, stopped bool
+	m := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	m.OnDrain(func() { drained = true })
+	m.Append(Hook{Name: "database", OnStop: Closer(func() error {
+		stopped = true
+		return nil
+	})})
+	m.Append(Hook{
+		Name:    "server",
+		OnStart: func(context.Context) error { return nil },
+		OnStop:  func(context.Context) error { t.Fatalf("hook stopped without being started"); return nil },
+	})
+
+	assert.NoError(t, m.Shutdown())
+	assert.False(t, drained, "drained before starting")
+	assert.True(t, stopped, "database not closed")
+}
+
+func TestAppendServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &http.Server{
+		ReadHeaderTimeout: time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	}
+
+	m := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	m.AppendServer("http server", server, func() error { return server.Serve(listener) }, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Run(ctx)
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	cancel()
+	assert.NoError(t, <-done)
+
+	_, err = http.Get("http://" + listener.Addr().String())
+	assert.Error(t, err, "server still serving after shutdown")
+}
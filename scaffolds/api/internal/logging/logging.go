@@ -0,0 +1,121 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	loggerKey
+)
+
+// Options configures the handler returned by NewHandler.
+type Options struct {
+	// Format is `json` or `text`.
+	Format string
+	// Level is the minimum level of the records written.
+	Level slog.Leveler
+	// AddSource adds the file and line of the log call to every record.
+	AddSource bool
+}
+
+// NewHandler returns a ContextHandler writing records to w in the format set by options.
+func NewHandler(w io.Writer, options Options) (*ContextHandler, error) {
+	handlerOptions := &slog.HandlerOptions{Level: options.Level, AddSource: options.AddSource}
+
+	switch options.Format {
+	case "json":
+		return NewContextHandler(slog.NewJSONHandler(w, handlerOptions)), nil
+	case "text":
+		return NewContextHandler(slog.NewTextHandler(w, handlerOptions)), nil
+	default:
+		return nil, fmt.Errorf("[in logging.NewHandler] unknown format %q", options.Format)
+	}
+}
+
+// WithLogger returns a copy of ctx carrying logger, the logger of the request being handled.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the logger carried by ctx, or slog.Default if there is none. Handlers and
+// middleware log with it, so the attributes added for a request, e.g. its tenant, are on every
+// line.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request being handled.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by ctx, if any.
+func RequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok && id != ""
+}
+
+// NewRequestID returns a random request ID, for requests that arrive without one.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ContextHandler is a slog.Handler that adds the request ID, trace ID and chi route pattern carried
+// by the context of a record to it, so every line logged with a request's context can be tied to
+// the request.
+type ContextHandler struct {
+	next slog.Handler
+}
+
+// NewContextHandler returns a ContextHandler passing records on to next.
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+// Enabled reports whether the wrapped handler handles records at level.
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle adds the attributes carried by ctx to record and passes it on.
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	record = record.Clone()
+	if id, ok := RequestID(ctx); ok {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
+	}
+	// the pattern is complete once chi has routed the request to its handler
+	if routeContext := chi.RouteContext(ctx); routeContext != nil && routeContext.RoutePattern() != "" {
+		record.AddAttrs(slog.String("route", routeContext.RoutePattern()))
+	}
+
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs returns a ContextHandler whose attributes are added to every record.
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup returns a ContextHandler that qualifies later attributes with name.
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute is the route label of requests that did not match a route, so unknown paths
+// cannot grow the number of series.
+const unmatchedRoute = "unmatched"
+
+// otherMethod is the method label of requests with a method outside the standard set, as net/http
+// accepts any method token and each would otherwise add new series.
+const otherMethod = "OTHER"
+
+// Metrics holds the Prometheus collectors of the application and the registry they are exposed
+// from.
+type Metrics struct {
+	registry      *prometheus.Registry
+	requests      *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	inFlight      prometheus.Gauge
+	serviceErrors *prometheus.CounterVec
+}
+
+// New returns Metrics registered with a new registry, along with Go runtime and process metrics
+// and the connection pool statistics of db.
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests handled, by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests, by method and route pattern.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests currently being handled.",
+		}),
+		serviceErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "service_errors_total",
+			Help: "Number of errors returned by services, by operation and error kind.",
+		}, []string{"operation", "kind"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.duration,
+		m.inFlight,
+		m.serviceErrors,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, "users"),
+	)
+
+	return m
+}
+
+// Handler returns the handler exposing the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware records the count, duration and status code of every request, labelled with the chi
+// route pattern the request matched rather than its path. A request whose handler panics is
+// recorded with a 500, the response middleware.Recovery writes for it, so Middleware can come
+// after Recovery.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			recovered := recover()
+
+			// the route is known once chi has routed the request
+			route := unmatchedRoute
+			if routeContext := chi.RouteContext(r.Context()); routeContext != nil && routeContext.RoutePattern() != "" {
+				route = routeContext.RoutePattern()
+			}
+			status := ww.Status()
+			switch {
+			case status != 0:
+			case recovered != nil:
+				status = http.StatusInternalServerError
+			default:
+				status = http.StatusOK
+			}
+
+			method := methodLabel(r.Method)
+			m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+			m.duration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// methodLabel returns the method label of a request with method.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return otherMethod
+	}
+}
+
+// ObserveError counts an error of the given kind returned by a service operation. It implements
+// services.ErrorObserver.
+func (m *Metrics) ObserveError(operation string, kind string) {
+	m.serviceErrors.WithLabelValues(operation, kind).Inc()
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMetrics(t *testing.T) *Metrics {
+	t.Helper()
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("creating database mock: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	return New(db)
+}
+
+func TestMiddleware(t *testing.T) {
+	m := newTestMetrics(t)
+
+	router := chi.NewRouter()
+	// panics are recovered before the metrics middleware, like middleware.Recovery in main
+	router.Use(middleware.Recoverer)
+	router.Use(m.Middleware)
+	router.Get("/lambda/user/{ID}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 1.0, promtestutil.ToFloat64(m.inFlight))
+		switch chi.URLParam(r, "ID") {
+		case "0":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case "panic":
+			panic("something went wrong")
+		}
+		_, _ = w.Write([]byte("{}"))
+	})
+
+	for _, path := range []string{"/lambda/user/1", "/lambda/user/2", "/lambda/user/0", "/lambda/user/panic", "/unknown/1", "/unknown/2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	tests := map[string]struct {
+		route    string
+		status   string
+		expected float64
+	}{
+		"ok by route pattern":        {route: "/lambda/user/{ID}", status: "200", expected: 2},
+		"not found by route pattern": {route: "/lambda/user/{ID}", status: "404", expected: 1},
+		"panic by route pattern":     {route: "/lambda/user/{ID}", status: "500", expected: 1},
+		"unmatched paths":            {route: unmatchedRoute, status: "404", expected: 2},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, promtestutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, tc.route, tc.status)))
+		})
+	}
+	for _, method := range []string{"FOO", "BAR", "get"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/unknown/3", nil))
+	}
+	assert.Equal(t, 3.0, promtestutil.ToFloat64(m.requests.WithLabelValues(otherMethod, unmatchedRoute, "405")))
+
+	assert.Equal(t, 5, promtestutil.CollectAndCount(m.requests))
+	assert.Equal(t, 3, promtestutil.CollectAndCount(m.duration))
+	assert.Equal(t, 0.0, promtestutil.ToFloat64(m.inFlight))
+}
+
+func TestObserveError(t *testing.T) {
+	m := newTestMetrics(t)
+
+	m.ObserveError("UserService.GetUser", "not_found")
+	m.ObserveError("UserService.GetUser", "not_found")
+	m.ObserveError("UserService.ListUsers", "internal")
+
+	assert.Equal(t, 2.0, promtestutil.ToFloat64(m.serviceErrors.WithLabelValues("UserService.GetUser", "not_found")))
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(m.serviceErrors.WithLabelValues("UserService.ListUsers", "internal")))
+}
+
+func TestHandler(t *testing.T) {
+	m := newTestMetrics(t)
+	m.ObserveError("UserService.GetUser", "not_found")
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	for _, metric := range []string{
+		`service_errors_total{kind="not_found",operation="UserService.GetUser"} 1`,
+		"go_goroutines",
+		`go_sql_open_connections{db_name="users"}`,
+	} {
+		assert.Contains(t, string(body), metric)
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+)
+
+// APIKey authenticates requests that carry an API key in the named header. The principal of a
+// valid key is stored in the request context, see auth.PrincipalFromContext, and Authenticate then
+// lets the request through without a bearer token. Requests without the header are passed on
+// unchanged, requests with an invalid key are rejected with a 401.
+func APIKey(validator auth.APIKeyValidator, header string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := logging.FromContext(r.Context())
+			key := r.Header.Get(header)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := validator.ValidateAPIKey(r.Context(), key)
+			if err != nil {
+				logger.WarnContext(r.Context(), "Request rejected, invalid api key", "err", err, "method", r.Method, "path", r.URL.Path)
+				encodeError(w, http.StatusUnauthorized, "invalid api key")
+				return
+			}
+
+			ctx := auth.WithPrincipal(r.Context(), principal)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/tenant"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubAPIKeys map[string]auth.Principal
+
+func (s stubAPIKeys) ValidateAPIKey(_ context.Context, key string) (auth.Principal, error) {
+	principal, ok := s[key]
+	if !ok {
+		return auth.Principal{}, auth.ErrInvalidAPIKey
+	}
+	return principal, nil
+}
+
+func TestAPIKey(t *testing.T) {
+	issuer := testutil.NewJWTIssuer(t)
+	verifier := auth.NewVerifier(auth.NewJWKS(issuer.JWKSURL(), time.Hour), "https://issuer.test", "users-api", 0)
+	keys := stubAPIKeys{
+		"ak_0a1b2c3d_secret": {Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey},
+	}
+
+	validToken := issuer.Sign("RS256", "", map[string]any{
+		"sub":       "user-1",
+		"iss":       "https://issuer.test",
+		"aud":       "users-api",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"tenant_id": "tenant-b",
+	})
+
+	tests := map[string]struct {
+		headers         map[string]string
+		expectedCode    int
+		expectedSubject string
+		expectedMethod  string
+		expectedTenant  string
+		expectedBody    string
+	}{
+		"valid api key": {
+			headers:         map[string]string{"X-API-Key": "ak_0a1b2c3d_secret"},
+			expectedCode:    http.StatusOK,
+			expectedSubject: "billing-batch",
+			expectedMethod:  auth.MethodAPIKey,
+			expectedTenant:  "tenant-a",
+		},
+		"invalid api key": {
+			headers:      map[string]string{"X-API-Key": "ak_0a1b2c3d_wrong"},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"error":"invalid api key"}`,
+		},
+		"no api key falls back to bearer token": {
+			headers:         map[string]string{"Authorization": "Bearer " + validToken},
+			expectedCode:    http.StatusOK,
+			expectedSubject: "user-1",
+			expectedMethod:  auth.MethodBearer,
+			expectedTenant:  "tenant-b",
+		},
+		"no credentials": {
+			headers:      map[string]string{},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"error":"missing bearer token"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotPrincipal auth.Principal
+			var gotTenant string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPrincipal, _ = auth.PrincipalFromContext(r.Context())
+				gotTenant, _ = tenant.FromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := APIKey(keys, "X-API-Key")(
+				Authenticate(verifier)(
+					Tenant(TenantFromPrincipal(), TenantFromClaim("tenant_id"))(next),
+				),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/lambda/user", nil)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+			assert.Equal(t, tc.expectedSubject, gotPrincipal.Subject, "Wrong subject in context")
+			assert.Equal(t, tc.expectedMethod, gotPrincipal.Method, "Wrong method in context")
+			assert.Equal(t, tc.expectedTenant, gotTenant, "Wrong tenant in context")
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, rr.Body.String(), "Wrong response body")
+			}
+		})
+	}
+}
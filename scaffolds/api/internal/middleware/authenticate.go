@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+)
+
+type tokenVerifier interface {
+	Verify(ctx context.Context, token string) (auth.Claims, error)
+}
+
+// Authenticate requires a valid bearer token on every request that has not been authenticated
+// already, e.g. by APIKey. The verified claims are stored in the request context for handlers, see
+// auth.ClaimsFromContext. Requests with a missing or invalid token are rejected with a 401.
+func Authenticate(verifier tokenVerifier) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := logging.FromContext(r.Context())
+			if _, ok := auth.PrincipalFromContext(r.Context()); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := bearerToken(r.Header.Get("Authorization"))
+			if !ok {
+				logger.WarnContext(r.Context(), "Request rejected, missing bearer token", "method", r.Method, "path", r.URL.Path)
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				encodeError(w, http.StatusUnauthorized, "missing bearer token")
+				return
+			}
+
+			claims, err := verifier.Verify(r.Context(), token)
+			if err != nil {
+				logger.WarnContext(r.Context(), "Request rejected, invalid bearer token", "err", err, "method", r.Method, "path", r.URL.Path)
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				encodeError(w, http.StatusUnauthorized, "invalid bearer token")
+				return
+			}
+
+			ctx := auth.WithClaims(r.Context(), claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// bearerToken extracts the token from an `Authorization: Bearer <token>` header value.
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/tenant"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	issuer := testutil.NewJWTIssuer(t)
+	verifier := auth.NewVerifier(auth.NewJWKS(issuer.JWKSURL(), time.Hour), "https://issuer.test", "users-api", 0)
+
+	validClaims := map[string]any{
+		"sub":       "user-1",
+		"iss":       "https://issuer.test",
+		"aud":       "users-api",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"tenant_id": "tenant-a",
+	}
+	expiredClaims := map[string]any{
+		"sub": "user-1",
+		"iss": "https://issuer.test",
+		"aud": "users-api",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	}
+
+	tests := map[string]struct {
+		authorization   string
+		expectedCode    int
+		expectedSubject string
+		expectedTenant  string
+		expectedBody    string
+		expectedHeader  string
+	}{
+		"valid token": {
+			authorization:   "Bearer " + issuer.Sign("RS256", "", validClaims),
+			expectedCode:    http.StatusOK,
+			expectedSubject: "user-1",
+			expectedTenant:  "tenant-a",
+		},
+		"lowercase scheme": {
+			authorization:   "bearer " + issuer.Sign("ES256", "", validClaims),
+			expectedCode:    http.StatusOK,
+			expectedSubject: "user-1",
+			expectedTenant:  "tenant-a",
+		},
+		"missing token": {
+			authorization:  "",
+			expectedCode:   http.StatusUnauthorized,
+			expectedBody:   `{"error":"missing bearer token"}`,
+			expectedHeader: `Bearer`,
+		},
+		"wrong scheme": {
+			authorization:  "Basic dXNlcjpwYXNz",
+			expectedCode:   http.StatusUnauthorized,
+			expectedBody:   `{"error":"missing bearer token"}`,
+			expectedHeader: `Bearer`,
+		},
+		"expired token": {
+			authorization:  "Bearer " + issuer.Sign("RS256", "", expiredClaims),
+			expectedCode:   http.StatusUnauthorized,
+			expectedBody:   `{"error":"invalid bearer token"}`,
+			expectedHeader: `Bearer error="invalid_token"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotSubject, gotTenant string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				claims, _ := auth.ClaimsFromContext(r.Context())
+				gotSubject = claims.Subject
+				gotTenant, _ = tenant.FromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := Authenticate(verifier)(Tenant(TenantFromClaim("tenant_id"))(next))
+
+			req := httptest.NewRequest(http.MethodGet, "/lambda/user", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+			assert.Equal(t, tc.expectedSubject, gotSubject, "Wrong subject in context")
+			assert.Equal(t, tc.expectedTenant, gotTenant, "Wrong tenant in context")
+			assert.Equal(t, tc.expectedHeader, rr.Header().Get("WWW-Authenticate"))
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, rr.Body.String(), "Wrong response body")
+			}
+		})
+	}
+}
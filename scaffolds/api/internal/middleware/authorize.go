@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/go-chi/chi/v5"
+)
+
+// Authorize rejects requests whose claims do not satisfy policy. It must run after Authenticate
+// and be attached to the route itself, e.g. with chi.Router.With, so the OwnerParam path parameter
+// is available. Requests without claims are rejected with a 401 and callers that do not satisfy
+// the policy with a 403. If the policy's owner lookup fails, a 500 is returned.
+func Authorize(policy auth.Policy) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := logging.FromContext(r.Context())
+			var resourceID string
+			if policy.OwnerParam != "" {
+				resourceID = chi.URLParam(r, policy.OwnerParam)
+			}
+
+			err := policy.Authorize(r.Context(), resourceID)
+			switch {
+			case err == nil:
+				next.ServeHTTP(w, r)
+			case errors.Is(err, auth.ErrUnauthenticated):
+				logger.WarnContext(r.Context(), "Request rejected, no claims", "method", r.Method, "path", r.URL.Path)
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				encodeError(w, http.StatusUnauthorized, "missing bearer token")
+			case errors.Is(err, auth.ErrForbidden):
+				logger.WarnContext(r.Context(), "Request rejected, forbidden", "err", err, "method", r.Method, "path", r.URL.Path)
+				encodeError(w, http.StatusForbidden, "forbidden")
+			default:
+				logger.ErrorContext(r.Context(), "Error authorizing request", "err", err, "method", r.Method, "path", r.URL.Path)
+				encodeError(w, http.StatusInternalServerError, "internal server error")
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+
+	claims := func(roles ...any) *auth.Claims {
+		c, err := auth.NewClaims(map[string]any{
+			"roles":   roles,
+			"scope":   "users:read",
+			"user_id": json.Number("1001"),
+		})
+		if err != nil {
+			t.Fatalf("building claims: %v", err)
+		}
+		return &c
+	}
+
+	policy := auth.Policy{
+		Roles:      []string{"Employee"},
+		Scopes:     []string{"users:read"},
+		OwnerRoles: []string{"Customer"},
+		OwnerParam: "ID",
+	}
+	lookupPolicy := policy
+	lookupPolicy.Owner = func(ctx context.Context, resourceID string) (string, error) {
+		if resourceID == "7" {
+			return "1001", nil
+		}
+		return "", errors.New("db down")
+	}
+
+	tests := map[string]struct {
+		policy         *auth.Policy
+		claims         *auth.Claims
+		path           string
+		expectedCode   int
+		expectedBody   string
+		expectedHeader string
+	}{
+		"employee": {
+			claims:       claims("Employee"),
+			path:         "/lambda/user/1002",
+			expectedCode: http.StatusOK,
+		},
+		"owner": {
+			claims:       claims("Customer"),
+			path:         "/lambda/user/1001",
+			expectedCode: http.StatusOK,
+		},
+		"not owner": {
+			claims:       claims("Customer"),
+			path:         "/lambda/user/1002",
+			expectedCode: http.StatusForbidden,
+			expectedBody: `{"error":"forbidden"}`,
+		},
+		"no role": {
+			claims:       claims(),
+			path:         "/lambda/user/1001",
+			expectedCode: http.StatusForbidden,
+			expectedBody: `{"error":"forbidden"}`,
+		},
+		"owner by lookup": {
+			policy:       &lookupPolicy,
+			claims:       claims("Customer"),
+			path:         "/lambda/user/7",
+			expectedCode: http.StatusOK,
+		},
+		"owner lookup fails": {
+			policy:       &lookupPolicy,
+			claims:       claims("Customer"),
+			path:         "/lambda/user/8",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"error":"internal server error"}`,
+		},
+		"no claims": {
+			claims:         nil,
+			path:           "/lambda/user/1001",
+			expectedCode:   http.StatusUnauthorized,
+			expectedBody:   `{"error":"missing bearer token"}`,
+			expectedHeader: `Bearer`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := policy
+			if tc.policy != nil {
+				p = *tc.policy
+			}
+
+			router := chi.NewRouter()
+			router.With(Authorize(p)).Get("/lambda/user/{ID}", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			ctx := context.Background()
+			if tc.claims != nil {
+				ctx = auth.WithClaims(ctx, *tc.claims)
+			}
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil).WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+			assert.Equal(t, tc.expectedHeader, rr.Header().Get("WWW-Authenticate"))
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, rr.Body.String(), "Wrong response body")
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// maxLoggedBodyBytes bounds the part of each body LogBodies keeps. Truncated bodies are no longer
+// valid JSON, so they are masked entirely, see redact.Policy.Body.
+const maxLoggedBodyBytes = 64 << 10
+
+// LogBodies logs the request and response body of every request at debug level under `body`, so
+// the redacting handler masks their sensitive fields before they are written. Bodies are only
+// captured while the request logger writes debug records. It must run after Logger.
+func LogBodies() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := logging.FromContext(r.Context())
+			if !logger.Enabled(r.Context(), slog.LevelDebug) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var request, response boundedBuffer
+			if r.Body != nil {
+				r.Body = teeReadCloser{Reader: io.TeeReader(r.Body, &request), Closer: r.Body}
+			}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&response)
+			next.ServeHTTP(ww, r)
+
+			logger.DebugContext(r.Context(), "Request body", "method", r.Method, "path", r.URL.Path, "body", string(request.data))
+			logger.DebugContext(r.Context(), "Response body", "status", ww.Status(), "body", string(response.data))
+		})
+	}
+}
+
+// boundedBuffer keeps the first maxLoggedBodyBytes written to it and discards the rest.
+type boundedBuffer struct {
+	data []byte
+}
+
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	if room := maxLoggedBodyBytes - len(b.data); room > 0 {
+		b.data = append(b.data, p[:min(room, len(p))]...)
+	}
+	return len(p), nil
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
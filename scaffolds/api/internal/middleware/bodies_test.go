@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogBodies(t *testing.T) {
+	tests := map[string]struct {
+		level         slog.Level
+		expectedLines []map[string]any
+	}{
+		"debug": {
+			level: slog.LevelDebug,
+			expectedLines: []map[string]any{
+				{"msg": "Request body", "body": `{"first_name":"[REDACTED]","id":1}`},
+				{"msg": "Response body", "body": `{"first_name":"[REDACTED]","id":1}`},
+			},
+		},
+		"info": {
+			level: slog.LevelInfo,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			handler := redact.NewHandler(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: tc.level}), redact.NewPolicy([]string{"first_name"}, nil))
+
+			var received string
+			router := Logger(slog.New(handler))(LogBodies()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				received = string(body)
+				_, _ = w.Write([]byte(`{"id":1,"first_name":"Ada"}`))
+			})))
+
+			request := httptest.NewRequest(http.MethodPut, "/api/v1/user/1", strings.NewReader(`{"id":1,"first_name":"Ada"}`))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, `{"id":1,"first_name":"Ada"}`, received)
+			assert.Equal(t, `{"id":1,"first_name":"Ada"}`, recorder.Body.String())
+
+			var lines []map[string]any
+			decoder := json.NewDecoder(&out)
+			for decoder.More() {
+				var line map[string]any
+				if err := decoder.Decode(&line); err != nil {
+					t.Fatalf("decoding log line: %v", err)
+				}
+				if line["msg"] != "Request handled" {
+					lines = append(lines, map[string]any{"msg": line["msg"], "body": line["body"]})
+				}
+			}
+			assert.Equal(t, tc.expectedLines, lines)
+		})
+	}
+}
+
+func TestBoundedBuffer(t *testing.T) {
+	var buffer boundedBuffer
+
+	n, err := buffer.Write(bytes.Repeat([]byte("a"), maxLoggedBodyBytes-1))
+	assert.NoError(t, err)
+	assert.Equal(t, maxLoggedBodyBytes-1, n)
+
+	n, err = buffer.Write([]byte("bcd"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Len(t, buffer.data, maxLoggedBodyBytes)
+	assert.Equal(t, byte('b'), buffer.data[maxLoggedBodyBytes-1])
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/handlers"
+)
+
+// BodyLimits holds the maximum request body size, in bytes, of each route and of all other
+// routes. Routes are named `<METHOD> <pattern>`, e.g. `PUT /v1/user/{ID}`.
+type BodyLimits struct {
+	Default int64
+	Routes  map[string]int64
+}
+
+// For returns the body limit of the route.
+func (l BodyLimits) For(route string) int64 {
+	if limit, ok := l.Routes[route]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+// BodyLimit limits the request body the handlers decode to the limit of the route, see
+// handlers.WithMaxBodyBytes. Larger bodies are rejected by the handler with a 413. It must run
+// after routing.
+func BodyLimit(limits BodyLimits) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := limits.For(r.Method + " " + routePattern(r))
+			next.ServeHTTP(w, r.WithContext(handlers.WithMaxBodyBytes(r.Context(), limit)))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/handlers"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	limits := BodyLimits{
+		Default: 64,
+		Routes: map[string]int64{
+			"PUT /loglevel/{name}": 8,
+		},
+	}
+
+	tests := map[string]struct {
+		path         string
+		expectedCode int
+	}{
+		"route limit": {
+			path:         "/loglevel/app",
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		"default limit": {
+			path:         "/loglevel",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Group(func(r chi.Router) {
+				r.Use(BodyLimit(limits))
+				r.Put("/loglevel", handlers.HandleSetLogLevel(new(slog.LevelVar)))
+				r.Put("/loglevel/{name}", handlers.HandleSetLogLevel(new(slog.LevelVar)))
+			})
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, tc.path, strings.NewReader(`{"level":"debug"}`))
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+		})
+	}
+}
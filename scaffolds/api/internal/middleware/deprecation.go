@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Deprecation describes a deprecated version of the API.
+type Deprecation struct {
+	// At is when the version was deprecated.
+	At time.Time
+	// Sunset is when the version stops being served, if that is scheduled.
+	Sunset time.Time
+	// Successor is the path of the version replacing it, e.g. `/v2`, if there is one.
+	Successor string
+}
+
+// ParseDeprecations parses maps of API versions to the date they were deprecated and the date
+// they stop being served. Dates are `2006-01-02` or RFC 3339 timestamps. Every version with a
+// sunset must have a deprecation date, so its `Deprecation` header is the same on every instance.
+func ParseDeprecations(deprecated map[string]string, sunsets map[string]string) (map[string]Deprecation, error) {
+	deprecations := make(map[string]Deprecation, len(deprecated))
+
+	for version, date := range deprecated {
+		at, err := parseDate(date)
+		if err != nil {
+			return nil, fmt.Errorf("[in middleware.ParseDeprecations] version %q: %w", version, err)
+		}
+		deprecations[strings.TrimSpace(version)] = Deprecation{At: at}
+	}
+	for version, date := range sunsets {
+		sunset, err := parseDate(date)
+		if err != nil {
+			return nil, fmt.Errorf("[in middleware.ParseDeprecations] version %q: %w", version, err)
+		}
+		version = strings.TrimSpace(version)
+		deprecation, ok := deprecations[version]
+		if !ok {
+			return nil, fmt.Errorf("[in middleware.ParseDeprecations] version %q has a sunset but no deprecation date", version)
+		}
+		deprecation.Sunset = sunset
+		deprecations[version] = deprecation
+	}
+
+	return deprecations, nil
+}
+
+// parseDate parses a `2006-01-02` date or an RFC 3339 timestamp.
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, must be YYYY-MM-DD or RFC 3339", s)
+	}
+	return t, nil
+}
+
+// Deprecated announces that the routes it wraps are deprecated with a `Deprecation` header (RFC
+// 9745), a `Sunset` header (RFC 8594) if the sunset is scheduled and a `Link` header pointing to
+// the successor, if there is one.
+func Deprecated(deprecation Deprecation) Middleware {
+	deprecationHeader := fmt.Sprintf("@%d", deprecation.At.Unix())
+	var sunsetHeader string
+	if !deprecation.Sunset.IsZero() {
+		sunsetHeader = deprecation.Sunset.UTC().Format(http.TimeFormat)
+	}
+	var linkHeader string
+	if deprecation.Successor != "" {
+		linkHeader = fmt.Sprintf(`<%s>; rel="successor-version"`, deprecation.Successor)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecationHeader)
+			if sunsetHeader != "" {
+				w.Header().Set("Sunset", sunsetHeader)
+			}
+			if linkHeader != "" {
+				w.Header().Add("Link", linkHeader)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
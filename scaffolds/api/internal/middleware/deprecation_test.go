@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecated(t *testing.T) {
+	tests := map[string]struct {
+		deprecation     Deprecation
+		expectedHeaders map[string]string
+	}{
+		"deprecated with sunset and successor": {
+			deprecation: Deprecation{
+				At:        time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				Sunset:    time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+				Successor: "/api/v2",
+			},
+			expectedHeaders: map[string]string{
+				"Deprecation": "@1767225600",
+				"Sunset":      "Wed, 01 Jul 2026 00:00:00 GMT",
+				"Link":        `</api/v2>; rel="successor-version"`,
+			},
+		},
+		"deprecated without sunset": {
+			deprecation: Deprecation{At: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+			expectedHeaders: map[string]string{
+				"Deprecation": "@1767225600",
+				"Sunset":      "",
+				"Link":        "",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := Deprecated(tc.deprecation)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/user", nil))
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			for header, expected := range tc.expectedHeaders {
+				assert.Equal(t, expected, rr.Header().Get(header), header)
+			}
+		})
+	}
+}
+
+func TestParseDeprecations(t *testing.T) {
+	deprecations, err := ParseDeprecations(
+		map[string]string{"v1": "2026-01-01"},
+		map[string]string{"v1": "2026-07-01T12:00:00Z"},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, Deprecation{
+		At:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC),
+	}, deprecations["v1"])
+
+	_, err = ParseDeprecations(nil, map[string]string{"v0": "2026-03-01"})
+	assert.ErrorContains(t, err, `version "v0" has a sunset but no deprecation date`)
+
+	_, err = ParseDeprecations(map[string]string{"v1": "soon"}, nil)
+	assert.ErrorContains(t, err, `version "v1"`)
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Logger carries logger in the context of every request, so handlers and middleware log with
+// logging.FromContext instead of being given a logger, and logs every request once it is handled.
+// Requests answered with a 5xx are logged as errors, those answered with a 4xx as warnings.
+// Middleware later in the chain can add attributes to the logger for the rest of the request, e.g.
+// Tenant adds the tenant ID.
+func Logger(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx := logging.WithLogger(r.Context(), logger)
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+
+			logger.Log(ctx, level, "Request handled",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"duration_ms", float64(time.Since(start).Microseconds())/1000,
+			)
+		})
+	}
+}
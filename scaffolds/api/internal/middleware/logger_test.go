@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	tests := map[string]struct {
+		status        int
+		expectedLevel string
+	}{
+		"ok":           {status: http.StatusOK, expectedLevel: "INFO"},
+		"client error": {status: http.StatusNotFound, expectedLevel: "WARN"},
+		"server error": {status: http.StatusInternalServerError, expectedLevel: "ERROR"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&out, nil))
+
+			handler := Logger(logger)(Tenant(TenantFromHeader("X-Tenant-ID"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				logging.FromContext(r.Context()).InfoContext(r.Context(), "Handling")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte("body"))
+			})))
+
+			request := httptest.NewRequest(http.MethodGet, "/api/user/1", nil)
+			request.Header.Set("X-Tenant-ID", "acme")
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			decoder := json.NewDecoder(&out)
+			var handling, handled map[string]any
+			if err := decoder.Decode(&handling); err != nil {
+				t.Fatalf("decoding handler log line: %v", err)
+			}
+			if err := decoder.Decode(&handled); err != nil {
+				t.Fatalf("decoding request log line: %v", err)
+			}
+
+			assert.Equal(t, "acme", handling["tenant_id"])
+			assert.Equal(t, "Request handled", handled["msg"])
+			assert.Equal(t, tc.expectedLevel, handled["level"])
+			assert.Equal(t, http.MethodGet, handled["method"])
+			assert.Equal(t, "/api/user/1", handled["path"])
+			assert.Equal(t, float64(tc.status), handled["status"])
+			assert.Equal(t, 4.0, handled["bytes"])
+			assert.Contains(t, handled, "duration_ms")
+		})
+	}
+}
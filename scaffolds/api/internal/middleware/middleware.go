@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Middleware is the standard net/http middleware signature used by chi.
+type Middleware = func(next http.Handler) http.Handler
+
+type responseErr struct {
+	Error string `json:"error"`
+}
+
+// problem is an RFC 9457 problem details body.
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// encodeProblem writes a problem details body with the given status code.
+func encodeProblem(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+}
+
+// encodeError writes a JSON error body with the given status code.
+func encodeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(responseErr{Error: message})
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/ratelimit"
+	"github.com/go-chi/chi/v5"
+)
+
+// RateLimit limits each client to the limit of the route it calls, see ratelimit.Limits.
+// Authenticated clients are identified by their principal, so every API key owner and token
+// subject has its own buckets; other clients by their IP address. Every response carries
+// `RateLimit-*` headers and requests over the limit are rejected with a 429. If the store fails,
+// requests are let through. It must run after routing and authentication.
+func RateLimit(store ratelimit.Store, limits ratelimit.Limits) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.Method + " " + routePattern(r)
+			if takeToken(w, r, store, route+" "+rateLimitClient(r), limits.For(route), route) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// RateLimitIP limits each IP address to limit across all routes. It must run before
+// authentication, so requests with invalid credentials are limited too and cannot exhaust the
+// database with API key lookups; RateLimit then limits authenticated clients per route. Responses
+// are like those of RateLimit, whose headers replace these on requests it limits.
+func RateLimitIP(store ratelimit.Store, limit ratelimit.Limit) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if takeToken(w, r, store, "ip "+remoteIP(r), limit, "ip") {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// takeToken takes a token from the bucket key for the request and sets the `RateLimit-*` headers.
+// It responds with a 429 and returns false if the bucket is empty. name identifies the limit in
+// the logs.
+func takeToken(w http.ResponseWriter, r *http.Request, store ratelimit.Store, key string, limit ratelimit.Limit, name string) bool {
+	logger := logging.FromContext(r.Context())
+
+	result, err := store.Take(r.Context(), key, limit)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Rate limit not enforced", "err", err, "method", r.Method, "path", r.URL.Path)
+		return true
+	}
+
+	for key, value := range result.Headers() {
+		w.Header().Set(key, value)
+	}
+	if !result.Allowed {
+		logger.WarnContext(r.Context(), "Request rejected, rate limit exceeded", "limit_route", name)
+		encodeProblem(w, http.StatusTooManyRequests, "rate limit exceeded, retry after "+w.Header().Get("Retry-After")+"s")
+		return false
+	}
+	return true
+}
+
+// routePattern returns the chi route pattern of the request, e.g. `/v1/user/{ID}`, or its
+// path if it has not been routed.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return r.URL.Path
+}
+
+// rateLimitClient identifies the client of the request for rate limiting. API keys are limited
+// each on their own, as their owners are free text and may be shared between keys and tenants.
+func rateLimitClient(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		if principal.Method == auth.MethodAPIKey {
+			return principal.Method + ":" + principal.Tenant + ":" + principal.KeyPrefix
+		}
+		return principal.Method + ":" + principal.Subject
+	}
+	return "ip:" + remoteIP(r)
+}
+
+// remoteIP returns the IP address of the client of the request.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/ratelimit"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(_ context.Context, _ string, _ ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	limits := ratelimit.Limits{
+		Default: ratelimit.Limit{Requests: 5, Period: time.Minute},
+		Routes: map[string]ratelimit.Limit{
+			"GET /lambda/user/{ID}": {Requests: 1, Period: time.Minute},
+		},
+	}
+
+	type call struct {
+		path         string
+		remoteAddr   string
+		principal    *auth.Principal
+		expectedCode int
+		expectedBody string
+		expectedHdrs map[string]string
+	}
+
+	tests := map[string]struct {
+		store ratelimit.Store
+		calls []call
+	}{
+		"route limit per client ip": {
+			store: ratelimit.NewMemoryStore(),
+			calls: []call{
+				{
+					path:         "/lambda/user/1",
+					remoteAddr:   "10.0.0.1:1234",
+					expectedCode: http.StatusOK,
+					expectedHdrs: map[string]string{"RateLimit-Limit": "1", "RateLimit-Remaining": "0", "RateLimit-Reset": "60"},
+				},
+				{
+					path:         "/lambda/user/2",
+					remoteAddr:   "10.0.0.1:5678",
+					expectedCode: http.StatusTooManyRequests,
+					expectedBody: `{"type":"about:blank","title":"Too Many Requests","status":429,"detail":"rate limit exceeded, retry after 60s"}`,
+					expectedHdrs: map[string]string{"RateLimit-Remaining": "0", "Retry-After": "60", "Content-Type": "application/problem+json"},
+				},
+				{
+					path:         "/lambda/user/1",
+					remoteAddr:   "10.0.0.2:1234",
+					expectedCode: http.StatusOK,
+				},
+				{
+					path:         "/lambda/user",
+					remoteAddr:   "10.0.0.1:1234",
+					expectedCode: http.StatusOK,
+					expectedHdrs: map[string]string{"RateLimit-Limit": "5", "RateLimit-Remaining": "4"},
+				},
+			},
+		},
+		"authenticated clients limited by principal": {
+			store: ratelimit.NewMemoryStore(),
+			calls: []call{
+				{
+					path:         "/lambda/user/1",
+					remoteAddr:   "10.0.0.1:1234",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey, KeyPrefix: "0a1b2c3d"},
+					expectedCode: http.StatusOK,
+				},
+				{
+					path:         "/lambda/user/1",
+					remoteAddr:   "10.0.0.2:1234",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey, KeyPrefix: "0a1b2c3d"},
+					expectedCode: http.StatusTooManyRequests,
+				},
+				{
+					path:         "/lambda/user/1",
+					remoteAddr:   "10.0.0.1:1234",
+					principal:    &auth.Principal{Subject: "user-1", Method: auth.MethodBearer},
+					expectedCode: http.StatusOK,
+				},
+			},
+		},
+		"api keys limited each on their own": {
+			store: ratelimit.NewMemoryStore(),
+			calls: []call{
+				{
+					path:         "/lambda/user/1",
+					remoteAddr:   "10.0.0.1:1234",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey, KeyPrefix: "0a1b2c3d"},
+					expectedCode: http.StatusOK,
+				},
+				{
+					path:         "/lambda/user/1",
+					remoteAddr:   "10.0.0.1:1234",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey, KeyPrefix: "4e5f6a7b"},
+					expectedCode: http.StatusOK,
+				},
+				{
+					path:         "/lambda/user/1",
+					remoteAddr:   "10.0.0.2:1234",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-b", Method: auth.MethodAPIKey, KeyPrefix: "8c9d0e1f"},
+					expectedCode: http.StatusOK,
+				},
+				{
+					path:         "/lambda/user/1",
+					remoteAddr:   "10.0.0.2:1234",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey, KeyPrefix: "4e5f6a7b"},
+					expectedCode: http.StatusTooManyRequests,
+				},
+			},
+		},
+		"store failure lets requests through": {
+			store: failingStore{},
+			calls: []call{
+				{
+					path:         "/lambda/user/1",
+					remoteAddr:   "10.0.0.1:1234",
+					expectedCode: http.StatusOK,
+					expectedHdrs: map[string]string{"RateLimit-Limit": ""},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Group(func(r chi.Router) {
+				r.Use(RateLimit(tc.store, limits))
+				ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+				r.Get("/lambda/user", ok)
+				r.Get("/lambda/user/{ID}", ok)
+			})
+
+			for i, c := range tc.calls {
+				req := httptest.NewRequest(http.MethodGet, c.path, nil)
+				req.RemoteAddr = c.remoteAddr
+				if c.principal != nil {
+					req = req.WithContext(auth.WithPrincipal(req.Context(), *c.principal))
+				}
+
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, req)
+
+				assert.Equal(t, c.expectedCode, rr.Code, "call %d: wrong code received", i)
+				if c.expectedBody != "" {
+					assert.JSONEq(t, c.expectedBody, rr.Body.String(), "call %d: wrong response body", i)
+				}
+				for key, value := range c.expectedHdrs {
+					assert.Equal(t, value, rr.Header().Get(key), "call %d: wrong %s header", i, key)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitIP(t *testing.T) {
+	// the API key validator is slow and costly, the IP limit must reject before it is called
+	var validated int
+	authenticate := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			validated++
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+
+	router := chi.NewRouter()
+	router.Group(func(r chi.Router) {
+		r.Use(RateLimitIP(ratelimit.NewMemoryStore(), ratelimit.Limit{Requests: 2, Period: time.Minute}))
+		r.Use(authenticate)
+		ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+		r.Get("/lambda/user", ok)
+		r.Get("/lambda/user/{ID}", ok)
+	})
+
+	calls := []struct {
+		path         string
+		remoteAddr   string
+		expectedCode int
+	}{
+		{path: "/lambda/user", remoteAddr: "10.0.0.1:1234", expectedCode: http.StatusUnauthorized},
+		{path: "/lambda/user/1", remoteAddr: "10.0.0.1:5678", expectedCode: http.StatusUnauthorized},
+		{path: "/lambda/user/2", remoteAddr: "10.0.0.1:1234", expectedCode: http.StatusTooManyRequests},
+		{path: "/lambda/user", remoteAddr: "10.0.0.2:1234", expectedCode: http.StatusUnauthorized},
+	}
+
+	for i, c := range calls {
+		req := httptest.NewRequest(http.MethodGet, c.path, nil)
+		req.RemoteAddr = c.remoteAddr
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, c.expectedCode, rr.Code, "call %d: wrong code received", i)
+	}
+	assert.Equal(t, 3, validated, "limited requests must not be authenticated")
+}
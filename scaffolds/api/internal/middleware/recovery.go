@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/reporting"
+	"github.com/go-chi/chi/v5"
+)
+
+// Recovery recovers panics in later handlers, reports them to reporter with the stack of the
+// panicking goroutine and the method, path and route of the request, and responds with a 500.
+// http.ErrAbortHandler is not recovered, so net/http aborts the response as intended.
+func Recovery(reporter reporting.ErrorReporter) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				tags := map[string]string{"method": r.Method, "path": r.URL.Path}
+				if routeCtx := chi.RouteContext(r.Context()); routeCtx != nil && routeCtx.RoutePattern() != "" {
+					tags["route"] = routeCtx.RoutePattern()
+				}
+				reporter.Report(r.Context(), reporting.Recovered(v, tags))
+
+				// upgraded connections are no longer HTTP, so no response is written
+				if r.Header.Get("Connection") != "Upgrade" {
+					encodeError(w, http.StatusInternalServerError, "internal server error")
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
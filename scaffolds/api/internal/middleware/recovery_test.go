@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/reporting"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingReporter keeps the events reported to it.
+type recordingReporter struct {
+	events []reporting.Event
+}
+
+func (r *recordingReporter) Report(_ context.Context, event reporting.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestRecovery(t *testing.T) {
+	tests := map[string]struct {
+		handler        http.HandlerFunc
+		expectedCode   int
+		expectedBody   string
+		expectedErr    string
+		expectedReport bool
+	}{
+		"handler does not panic": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+			expectedCode: http.StatusOK,
+		},
+		"handler panics": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				panic("something went wrong")
+			},
+			expectedCode:   http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+			expectedErr:    "panic: something went wrong",
+			expectedReport: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			reporter := &recordingReporter{}
+			router := chi.NewRouter()
+			router.Use(Recovery(reporter))
+			router.Get("/api/user/{ID}", tc.handler)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/user/7", nil))
+
+			assert.Equal(t, tc.expectedCode, rr.Code)
+			assert.Equal(t, tc.expectedBody, strings.TrimSpace(rr.Body.String()))
+			if !tc.expectedReport {
+				assert.Empty(t, reporter.events)
+				return
+			}
+			if len(reporter.events) != 1 {
+				t.Fatalf("expected one reported event, got %d", len(reporter.events))
+			}
+			event := reporter.events[0]
+			assert.EqualError(t, event.Err, tc.expectedErr)
+			assert.True(t, event.Panic)
+			assert.Equal(t, map[string]string{"method": "GET", "path": "/api/user/7", "route": "/api/user/{ID}"}, event.Tags)
+			assert.Contains(t, reporting.FormatStack(event.Stack), "middleware.TestRecovery")
+		})
+	}
+}
+
+func TestRecoveryAbortHandler(t *testing.T) {
+	handler := Recovery(&recordingReporter{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
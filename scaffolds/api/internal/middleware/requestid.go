@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+)
+
+// RequestIDHeader is the request and response header carrying the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the length of the request IDs accepted from callers.
+const maxRequestIDLength = 128
+
+// RequestID carries the ID of every request in the context, so it is added to every log line by
+// logging.ContextHandler, and returns it in the `X-Request-ID` header. The ID is taken from the
+// `X-Request-ID` request header, or generated if the header is missing or holds anything but a
+// short string of letters, digits, `-`, `_` and `.`. The header is set on the request as well, so
+// the request logger reports the same ID.
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = logging.NewRequestID()
+				r.Header.Set(RequestIDHeader, requestID)
+			}
+
+			w.Header().Set(RequestIDHeader, requestID)
+			next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), requestID)))
+		})
+	}
+}
+
+// validRequestID reports whether id can be used as a request ID without sanitizing it first.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := map[string]struct {
+		header            string
+		expectedGenerated bool
+	}{
+		"caller request ID": {
+			header: "c6af9ac6-7b61-11e6-9a41-93e8deadbeef",
+		},
+		"missing request ID": {
+			expectedGenerated: true,
+		},
+		"invalid request ID": {
+			header:            "id\nwith newline",
+			expectedGenerated: true,
+		},
+		"long request ID": {
+			header:            strings.Repeat("a", maxRequestIDLength+1),
+			expectedGenerated: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var handlerID, headerID string
+			handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handlerID, _ = logging.RequestID(r.Context())
+				headerID = r.Header.Get(RequestIDHeader)
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			if tc.header != "" {
+				request.Header.Set(RequestIDHeader, tc.header)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if tc.expectedGenerated {
+				assert.Len(t, handlerID, 32)
+			} else {
+				assert.Equal(t, tc.header, handlerID)
+			}
+			assert.Equal(t, handlerID, headerID)
+			assert.Equal(t, handlerID, recorder.Header().Get(RequestIDHeader))
+		})
+	}
+}
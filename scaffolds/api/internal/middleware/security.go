@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SecurityHeaders configures the response headers set by the Security middleware.
+type SecurityHeaders struct {
+	// HSTSMaxAge is the max-age of the Strict-Transport-Security header. HSTS is disabled if it
+	// is zero.
+	HSTSMaxAge time.Duration
+	// ContentSecurityPolicy is the Content-Security-Policy header. It is omitted if empty.
+	ContentSecurityPolicy string
+	// ReferrerPolicy is the Referrer-Policy header. It is omitted if empty.
+	ReferrerPolicy string
+}
+
+// Security sets the configured security headers, and `X-Content-Type-Options: nosniff`, on every
+// response. Handlers can override them before writing the response.
+func Security(headers SecurityHeaders) Middleware {
+	values := headers.values()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for key, value := range values {
+				w.Header().Set(key, value)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// values returns the headers to set on every response.
+func (h SecurityHeaders) values() map[string]string {
+	values := map[string]string{"X-Content-Type-Options": "nosniff"}
+	if h.HSTSMaxAge > 0 {
+		values["Strict-Transport-Security"] = "max-age=" + strconv.Itoa(int(h.HSTSMaxAge.Seconds())) + "; includeSubDomains"
+	}
+	if h.ContentSecurityPolicy != "" {
+		values["Content-Security-Policy"] = h.ContentSecurityPolicy
+	}
+	if h.ReferrerPolicy != "" {
+		values["Referrer-Policy"] = h.ReferrerPolicy
+	}
+	return values
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurity(t *testing.T) {
+	tests := map[string]struct {
+		headers         SecurityHeaders
+		expectedHeaders map[string]string
+	}{
+		"all headers": {
+			headers: SecurityHeaders{
+				HSTSMaxAge:            365 * 24 * time.Hour,
+				ContentSecurityPolicy: "default-src 'none'",
+				ReferrerPolicy:        "no-referrer",
+			},
+			expectedHeaders: map[string]string{
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+				"X-Content-Type-Options":    "nosniff",
+				"Content-Security-Policy":   "default-src 'none'",
+				"Referrer-Policy":           "no-referrer",
+			},
+		},
+		"empty values omitted": {
+			headers: SecurityHeaders{},
+			expectedHeaders: map[string]string{
+				"Strict-Transport-Security": "",
+				"X-Content-Type-Options":    "nosniff",
+				"Content-Security-Policy":   "",
+				"Referrer-Policy":           "",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := Security(tc.headers)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/lambda/user", nil))
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			for key, value := range tc.expectedHeaders {
+				assert.Equal(t, value, rr.Header().Get(key), "wrong %s header", key)
+			}
+		})
+	}
+}
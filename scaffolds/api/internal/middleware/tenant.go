@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/tenant"
+)
+
+// TenantSource extracts a tenant ID from a request. An empty string means the source could not
+// resolve a tenant.
+type TenantSource func(r *http.Request) string
+
+// TenantFromHeader returns a TenantSource that reads the tenant ID from the named header.
+func TenantFromHeader(name string) TenantSource {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// TenantFromClaim returns a TenantSource that reads the tenant ID from the named claim of the
+// verified token. It must run after Authenticate.
+func TenantFromClaim(name string) TenantSource {
+	return func(r *http.Request) string {
+		claims, ok := auth.ClaimsFromContext(r.Context())
+		if !ok {
+			return ""
+		}
+		return claims.String(name)
+	}
+}
+
+// TenantFromPrincipal returns a TenantSource that reads the tenant ID from the authenticated
+// principal, which carries one for API keys. It must run after APIKey.
+func TenantFromPrincipal() TenantSource {
+	return func(r *http.Request) string {
+		principal, ok := auth.PrincipalFromContext(r.Context())
+		if !ok {
+			return ""
+		}
+		return principal.Tenant
+	}
+}
+
+// Tenant resolves the tenant for each request from the given sources, in order, and stores the
+// first match in the request context. Requests without a tenant are rejected with a 400.
+func Tenant(sources ...TenantSource) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := logging.FromContext(r.Context())
+			for _, source := range sources {
+				if ID := source(r); ID != "" {
+					ctx := logging.WithLogger(tenant.WithID(r.Context(), ID), logger.With("tenant_id", ID))
+					next.ServeHTTP(w, r.WithContext(ctx))
+					return
+				}
+			}
+
+			logger.WarnContext(r.Context(), "Request rejected, no tenant resolved", "method", r.Method, "path", r.URL.Path)
+			encodeError(w, http.StatusBadRequest, "missing tenant")
+		})
+	}
+}
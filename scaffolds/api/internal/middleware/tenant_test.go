@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+
+	tests := map[string]struct {
+		sources        []TenantSource
+		headers        map[string]string
+		expectedCode   int
+		expectedTenant string
+		expectedBody   string
+	}{
+		"tenant from header": {
+			sources:        []TenantSource{TenantFromHeader("X-Tenant-ID")},
+			headers:        map[string]string{"X-Tenant-ID": "tenant-a"},
+			expectedCode:   http.StatusOK,
+			expectedTenant: "tenant-a",
+		},
+		"first matching source wins": {
+			sources: []TenantSource{
+				TenantFromHeader("X-Org-ID"),
+				TenantFromHeader("X-Tenant-ID"),
+			},
+			headers:        map[string]string{"X-Org-ID": "tenant-b", "X-Tenant-ID": "tenant-a"},
+			expectedCode:   http.StatusOK,
+			expectedTenant: "tenant-b",
+		},
+		"missing tenant rejected": {
+			sources:      []TenantSource{TenantFromHeader("X-Tenant-ID")},
+			headers:      map[string]string{},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"missing tenant"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotTenant string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotTenant, _ = tenant.FromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/lambda/user", nil)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			rr := httptest.NewRecorder()
+			Tenant(tc.sources...)(next).ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code, "Wrong code received")
+			assert.Equal(t, tc.expectedTenant, gotTenant, "Wrong tenant in context")
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, rr.Body.String(), "Wrong response body")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	tests := map[string]struct {
+		path           string
+		headers        map[string]string
+		expectedName   string
+		expectedStatus int
+		expectedParent string
+		expectedCode   codes.Code
+	}{
+		"new trace": {
+			path:           "/users/1",
+			expectedName:   "GET /users/{ID}",
+			expectedStatus: http.StatusOK,
+			expectedCode:   codes.Unset,
+		},
+		"w3c trace context": {
+			path:           "/users/1",
+			headers:        map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			expectedName:   "GET /users/{ID}",
+			expectedStatus: http.StatusOK,
+			expectedParent: "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedCode:   codes.Unset,
+		},
+		"x-ray trace header": {
+			path:           "/users/1",
+			headers:        map[string]string{"X-Amzn-Trace-Id": "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"},
+			expectedName:   "GET /users/{ID}",
+			expectedStatus: http.StatusOK,
+			expectedParent: "5759e988bd862e3fe1be46a994272793",
+			expectedCode:   codes.Unset,
+		},
+		"server error": {
+			path:           "/fail",
+			expectedName:   "GET /fail",
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   codes.Error,
+		},
+		"unmatched route": {
+			path:           "/missing",
+			expectedName:   "GET",
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   codes.Unset,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			var handlerSpan trace.SpanContext
+			router := chi.NewRouter()
+			router.Use(Tracing(provider))
+			router.Get("/users/{ID}", func(w http.ResponseWriter, r *http.Request) {
+				handlerSpan = trace.SpanContextFromContext(r.Context())
+				_, _ = w.Write([]byte("OK"))
+			})
+			router.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+				handlerSpan = trace.SpanContextFromContext(r.Context())
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			if !assert.Len(t, spans, 1) {
+				return
+			}
+			span := spans[0]
+			assert.Equal(t, tc.expectedName, span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Contains(t, span.Attributes(), semconv.HTTPResponseStatusCode(tc.expectedStatus))
+			assert.Equal(t, tc.expectedCode, span.Status().Code)
+			if handlerSpan.IsValid() {
+				assert.Equal(t, handlerSpan, span.SpanContext())
+			}
+			if tc.expectedParent != "" {
+				assert.Equal(t, tc.expectedParent, span.SpanContext().TraceID().String())
+				assert.True(t, span.Parent().IsRemote())
+			} else {
+				assert.False(t, span.Parent().IsValid())
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript applies the same token bucket step as take atomically in Redis. Buckets are hashes
+// of their token count and the time of their last update in milliseconds, and expire once they
+// have refilled completely.
+//
+// KEYS[1] bucket, ARGV[1] capacity, ARGV[2] refill rate per millisecond, ARGV[3] now in
+// milliseconds. Returns allowed (0/1) and the tokens left, as a string to keep the fraction.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(bucket[1])
+local updated = tonumber(bucket[2])
+if tokens == nil or updated == nil then
+	tokens = capacity
+	updated = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((capacity - tokens) / rate) + 1)
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore keeps token buckets in Redis so all instances of a service share them. Buckets are
+// stored under keys starting with `ratelimit:`.
+type RedisStore struct {
+	client redis.Scripter
+	now    func() time.Time
+}
+
+// NewRedisStore returns a RedisStore that uses client.
+func NewRedisStore(client redis.Scripter) *RedisStore {
+	return &RedisStore{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// Take takes a token from the bucket identified by key. New buckets start full.
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	values, err := takeScript.Run(
+		ctx,
+		s.client,
+		[]string{"ratelimit:" + key},
+		limit.Requests,
+		limit.rate()/1000,
+		s.now().UnixMilli(),
+	).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("[in ratelimit.RedisStore.Take] failed to take token: %w", err)
+	}
+
+	var allowed int64
+	var tokens float64
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("[in ratelimit.RedisStore.Take] unexpected reply %v", values)
+	}
+	if _, err := fmt.Sscan(fmt.Sprint(values[0], " ", values[1]), &allowed, &tokens); err != nil {
+		return Result{}, fmt.Errorf("[in ratelimit.RedisStore.Take] unexpected reply %v: %w", values, err)
+	}
+
+	return newResult(allowed == 1, tokens, limit), nil
+}
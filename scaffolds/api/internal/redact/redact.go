@@ -0,0 +1,191 @@
+package redact
+
+import (
+	"encoding"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// Mask replaces redacted values.
+const Mask = "[REDACTED]"
+
+// bodyKey is the attribute key request and response bodies are logged under. String values of
+// body attributes are redacted as JSON documents.
+const bodyKey = "body"
+
+// Policy decides which values are sensitive. A value is sensitive if its key is on the deny list or
+// it is a struct field tagged `log:"sensitive"`, unless its key is on the allow list. Keys are
+// matched case-insensitively, ignoring underscores and dashes, so `first_name` matches the
+// attribute `firstName` and the field `FirstName`.
+type Policy struct {
+	deny  map[string]bool
+	allow map[string]bool
+}
+
+// NewPolicy returns a Policy that redacts the keys in deny, except those in allow.
+func NewPolicy(deny []string, allow []string) *Policy {
+	p := &Policy{deny: make(map[string]bool, len(deny)), allow: make(map[string]bool, len(allow))}
+	for _, key := range deny {
+		p.deny[normalize(key)] = true
+	}
+	for _, key := range allow {
+		p.allow[normalize(key)] = true
+	}
+
+	return p
+}
+
+// Attr returns a with sensitive values masked, including those nested in groups, structs, maps,
+// slices and JSON bodies.
+func (p *Policy) Attr(a slog.Attr) slog.Attr {
+	if p.sensitive(a.Key, false) {
+		return slog.String(a.Key, Mask)
+	}
+
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		attrs := value.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, attr := range attrs {
+			redacted[i] = p.Attr(attr)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindString:
+		if a.Key == bodyKey {
+			return slog.String(a.Key, p.Body(value.String()))
+		}
+	case slog.KindAny:
+		return slog.Any(a.Key, p.Value(value.Any()))
+	}
+
+	return slog.Attr{Key: a.Key, Value: value}
+}
+
+// Value returns a copy of v with sensitive values masked. Structs are returned as maps keyed by
+// their JSON field names. Errors and values that marshal themselves are returned as is.
+func (p *Policy) Value(v any) any {
+	return p.value(reflect.ValueOf(v))
+}
+
+func (p *Policy) value(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.CanInterface() && opaque(v.Interface()) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return p.value(v.Elem())
+	case reflect.Struct:
+		fields := make(map[string]any, v.NumField())
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := fieldName(field)
+			if name == "-" {
+				continue
+			}
+			if p.sensitive(name, field.Tag.Get("log") == "sensitive") {
+				fields[name] = Mask
+				continue
+			}
+			fields[name] = p.value(v.Field(i))
+		}
+		return fields
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		entries := make(map[string]any, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			key := iter.Key().String()
+			if p.sensitive(key, false) {
+				entries[key] = Mask
+				continue
+			}
+			entries[key] = p.value(iter.Value())
+		}
+		return entries
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		elements := make([]any, v.Len())
+		for i := range v.Len() {
+			elements[i] = p.value(v.Index(i))
+		}
+		return elements
+	}
+
+	if !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// Body returns the JSON document body with sensitive values masked. Bodies that are not JSON are
+// masked entirely, as their contents cannot be inspected.
+func (p *Policy) Body(body string) string {
+	if strings.TrimSpace(body) == "" {
+		return body
+	}
+
+	var document any
+	if err := json.Unmarshal([]byte(body), &document); err != nil {
+		return Mask
+	}
+	redacted, err := json.Marshal(p.Value(document))
+	if err != nil {
+		return Mask
+	}
+
+	return string(redacted)
+}
+
+// sensitive reports whether the value at key is redacted. tagged is set for struct fields tagged
+// as sensitive.
+func (p *Policy) sensitive(key string, tagged bool) bool {
+	key = normalize(key)
+	if p.allow[key] {
+		return false
+	}
+
+	return tagged || p.deny[key]
+}
+
+// opaque reports whether v is logged as is rather than inspected field by field.
+func opaque(v any) bool {
+	switch v.(type) {
+	case error, json.Marshaler, encoding.TextMarshaler:
+		return true
+	}
+
+	return false
+}
+
+// fieldName returns the JSON name of a struct field.
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// separators are ignored when matching keys.
+var separators = strings.NewReplacer("_", "", "-", "")
+
+func normalize(key string) string {
+	return strings.ToLower(separators.Replace(strings.TrimSpace(key)))
+}
@@ -1,38 +1,355 @@
 package routes
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/database"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/handlers"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/health"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/metrics"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/middleware"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/ratelimit"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/redact"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/services"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/httplog/v2"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
 )
 
 type Option func(*routerOptions)
 
 type routerOptions struct {
-	registerHealthRoute bool
+	health         *health.Health
+	tenantSources  []middleware.TenantSource
+	verifier       *auth.Verifier
+	apiKeys        auth.APIKeyValidator
+	apiKeyHeader   string
+	apiKeyService  *services.APIKeyService
+	rateLimitStore ratelimit.Store
+	rateLimits     ratelimit.Limits
+	ipLimitStore   ratelimit.Store
+	ipLimit        ratelimit.Limit
+	metrics        *metrics.Metrics
+	metricsRouter  chi.Router
+	adminRouter    chi.Router
+	logLevel       *slog.LevelVar
+	configuration  any
+	redactPolicy   *redact.Policy
+	queryStats     *database.QueryStats
+	bodyLimits     *middleware.BodyLimits
+	basePath       string
+	deprecations   map[string]middleware.Deprecation
+}
+
+// userHandlers are the handlers of the user routes of a version of the API.
+type userHandlers struct {
+	list   http.HandlerFunc
+	get    http.HandlerFunc
+	update http.HandlerFunc
+}
+
+// version is a version of the API, served below `<base path>/<name>`, with its own handlers and
+// DTOs. The API key admin routes are the same in every version.
+type version struct {
+	name  string
+	users userHandlers
+}
+
+// versions returns the versions of the API, oldest first.
+func versions(svs *services.UserService) []version {
+	return []version{
+		{
+			name: "v1",
+			users: userHandlers{
+				list:   handlers.HandleListUsers(svs),
+				get:    handlers.HandleGetUser(svs),
+				update: handlers.HandleUpdateUser(svs),
+			},
+		},
+		{
+			name: "v2",
+			users: userHandlers{
+				list:   handlers.HandleListUsersV2(svs),
+				get:    handlers.HandleGetUserV2(svs),
+				update: handlers.HandleUpdateUserV2(svs),
+			},
+		},
+	}
+}
+
+// WithRegisterHealthRoute registers the `/livez` liveness and `/readyz` readiness probes. The
+// readiness probe reports the checks registered on checks. If this function is not called, the
+// probes are not registered.
+func WithRegisterHealthRoute(checks *health.Health) Option {
+	return func(options *routerOptions) {
+		options.health = checks
+	}
+}
+
+// WithTenantSources sets where the tenant for user routes is resolved from. Sources are tried in
+// order. If this function is not called, the tenant is read from the `X-Tenant-ID` header.
+func WithTenantSources(sources ...middleware.TenantSource) Option {
+	return func(options *routerOptions) {
+		options.tenantSources = sources
+	}
+}
+
+// WithVerifier requires a valid bearer token, checked by verifier, on all user routes. If this
+// function is not called, requests carry no claims and every user route is rejected by its
+// authorization policy.
+func WithVerifier(verifier *auth.Verifier) Option {
+	return func(options *routerOptions) {
+		options.verifier = verifier
+	}
 }
 
-// WithRegisterHealthRoute controls whether a healthcheck route will be registered. If `false` is
-// passed in or this function is not called, the default is `false`.
-func WithRegisterHealthRoute(registerHealthRoute bool) Option {
+// WithAPIKeys accepts API keys, checked by validator, from the named header as an alternative to
+// bearer tokens on all user routes. If this function is not called, API keys are ignored.
+func WithAPIKeys(validator auth.APIKeyValidator, header string) Option {
 	return func(options *routerOptions) {
-		options.registerHealthRoute = registerHealthRoute
+		options.apiKeys = validator
+		options.apiKeyHeader = header
 	}
 }
 
-func RegisterRoutes(router *chi.Mux, logger *httplog.Logger, svs *services.UserService, opts ...Option) {
+// WithAPIKeyAdmin registers the routes that create, list and revoke API keys. They require the
+// `Admin` role. If this function is not called, the routes are not registered.
+func WithAPIKeyAdmin(service *services.APIKeyService) Option {
+	return func(options *routerOptions) {
+		options.apiKeyService = service
+	}
+}
+
+// WithRateLimit limits the requests each client can make to the user and admin routes, see
+// middleware.RateLimit. If this function is not called, requests are not limited.
+func WithRateLimit(store ratelimit.Store, limits ratelimit.Limits) Option {
+	return func(options *routerOptions) {
+		options.rateLimitStore = store
+		options.rateLimits = limits
+	}
+}
+
+// WithIPRateLimit limits the requests each IP address can make to the user and admin routes
+// before they are authenticated, see middleware.RateLimitIP. If this function is not called,
+// requests are only limited after authentication, by WithRateLimit.
+func WithIPRateLimit(store ratelimit.Store, limit ratelimit.Limit) Option {
+	return func(options *routerOptions) {
+		options.ipLimitStore = store
+		options.ipLimit = limit
+	}
+}
+
+// WithMetrics records the requests to every route and the errors returned by the user service in
+// m, and serves them at `/metrics`. The metrics route is registered on admin, a router served on a
+// separate port, or on the main router if admin is nil. If this function is not called, no metrics
+// are recorded.
+func WithMetrics(m *metrics.Metrics, admin chi.Router) Option {
+	return func(options *routerOptions) {
+		options.metrics = m
+		options.metricsRouter = admin
+	}
+}
+
+// WithAdminRoutes registers the debug routes on admin, a router served on a separate port:
+// `GET` and `PUT /admin/loglevel` read and change level at runtime, `GET /admin/buildinfo` returns
+// the build info, `GET /admin/config` returns configuration with the values policy considers
+// sensitive masked, and `/debug/pprof/` serves the pprof profiles. The routes are authenticated
+// like the user routes and require the `Admin` role. If admin is nil or this function is not
+// called, the routes are not registered.
+func WithAdminRoutes(admin chi.Router, level *slog.LevelVar, configuration any, policy *redact.Policy) Option {
+	return func(options *routerOptions) {
+		options.adminRouter = admin
+		options.logLevel = level
+		options.configuration = configuration
+		options.redactPolicy = policy
+	}
+}
+
+// WithQueryStats registers `GET /admin/queries`, which returns the stats of the queries recorded
+// in stats, with the other admin routes. If WithAdminRoutes or this function is not called, the
+// route is not registered.
+func WithQueryStats(stats *database.QueryStats) Option {
+	return func(options *routerOptions) {
+		options.queryStats = stats
+	}
+}
+
+// WithBodyLimits limits the request bodies of the user and admin routes, see
+// middleware.BodyLimit. If this function is not called, bodies are limited to
+// handlers.DefaultMaxBodyBytes.
+func WithBodyLimits(limits middleware.BodyLimits) Option {
+	return func(options *routerOptions) {
+		options.bodyLimits = &limits
+	}
+}
+
+// WithBasePath serves the versioned routes below path, e.g. `/api` serves `/api/v1/user`. The path
+// must start with a `/` and not end with one. The health probes are always served from the root.
+// If this function is not called, the versioned routes are served from the root too.
+func WithBasePath(path string) Option {
+	return func(options *routerOptions) {
+		options.basePath = path
+	}
+}
+
+// WithDeprecations announces that the versions of the API in deprecations, keyed by name, e.g.
+// `v1`, are deprecated, see middleware.Deprecated. Versions without a successor point to the
+// latest version. If this function is not called, no version is deprecated.
+func WithDeprecations(deprecations map[string]middleware.Deprecation) Option {
+	return func(options *routerOptions) {
+		options.deprecations = deprecations
+	}
+}
+
+func RegisterRoutes(router *chi.Mux, svs *services.UserService, opts ...Option) {
 	options := routerOptions{
-		registerHealthRoute: false,
+		tenantSources: []middleware.TenantSource{middleware.TenantFromHeader("X-Tenant-ID")},
 	}
 	for _, opt := range opts {
 		opt(&options)
 	}
 
-	if options.registerHealthRoute {
-		router.Get("/lambda/health-check", handlers.HandleHealth(logger))
+	// middleware must be added before the first route is registered
+	if options.metrics != nil {
+		router.Use(options.metrics.Middleware)
+		svs.ObserveErrors(options.metrics)
+
+		metricsRouter := options.metricsRouter
+		if metricsRouter == nil {
+			metricsRouter = router
+		}
+		metricsRouter.Method(http.MethodGet, "/metrics", options.metrics.Handler())
+	}
+
+	if options.health != nil {
+		router.Get("/livez", handlers.HandleLivez())
+		router.Get("/readyz", handlers.HandleReadyz(options.health))
+	}
+
+	if options.adminRouter != nil {
+		options.adminRouter.Group(func(r chi.Router) {
+			if options.ipLimitStore != nil {
+				r.Use(middleware.RateLimitIP(options.ipLimitStore, options.ipLimit))
+			}
+			if options.apiKeys != nil {
+				r.Use(middleware.APIKey(options.apiKeys, options.apiKeyHeader))
+			}
+			if options.verifier != nil {
+				r.Use(middleware.Authenticate(options.verifier))
+			}
+			r.Use(middleware.Authorize(auth.Policy{
+				Roles: []string{"Admin"},
+			}))
+			if options.bodyLimits != nil {
+				r.Use(middleware.BodyLimit(*options.bodyLimits))
+			}
+
+			r.Get("/admin/loglevel", handlers.HandleGetLogLevel(options.logLevel))
+			r.Put("/admin/loglevel", handlers.HandleSetLogLevel(options.logLevel))
+			r.Get("/admin/buildinfo", handlers.HandleBuildInfo())
+			r.Get("/admin/config", handlers.HandleConfig(options.configuration, options.redactPolicy))
+			if options.queryStats != nil {
+				r.Get("/admin/queries", handlers.HandleQueryStats(options.queryStats))
+			}
+			r.Mount("/debug", chiMiddleware.Profiler())
+		})
+	}
+
+	apiVersions := versions(svs)
+	latest := options.basePath + "/" + apiVersions[len(apiVersions)-1].name
+	for _, v := range apiVersions {
+		router.Route(options.basePath+"/"+v.name, func(r chi.Router) {
+			if deprecation, ok := options.deprecations[v.name]; ok {
+				if deprecation.Successor == "" {
+					deprecation.Successor = latest
+				}
+				r.Use(middleware.Deprecated(deprecation))
+			}
+
+			// the middleware of the group runs after routing, so it sees the route pattern
+			r.Group(func(r chi.Router) {
+				if options.ipLimitStore != nil {
+					r.Use(middleware.RateLimitIP(options.ipLimitStore, options.ipLimit))
+				}
+				if options.apiKeys != nil {
+					r.Use(middleware.APIKey(options.apiKeys, options.apiKeyHeader))
+				}
+				if options.verifier != nil {
+					r.Use(middleware.Authenticate(options.verifier))
+				}
+				r.Use(middleware.Tenant(options.tenantSources...))
+				if options.rateLimitStore != nil {
+					r.Use(middleware.RateLimit(options.rateLimitStore, options.rateLimits))
+				}
+				if options.bodyLimits != nil {
+					r.Use(middleware.BodyLimit(*options.bodyLimits))
+				}
+
+				registerUserRoutes(r, svs, v.users)
+				if options.apiKeyService != nil {
+					registerAPIKeyRoutes(r, options.apiKeyService)
+				}
+			})
+		})
 	}
+}
+
+// registerUserRoutes registers the user routes served by users with their authorization policies.
+func registerUserRoutes(r chi.Router, svs *services.UserService, users userHandlers) {
+	r.With(middleware.Authorize(auth.Policy{
+		Roles:  []string{"Employee"},
+		Scopes: []string{"users:read"},
+	})).Get("/user", users.list)
 
-	router.Get("/lambda/user", handlers.HandleListUsers(logger, svs))
-	router.Put("/lambda/user/{ID}", handlers.HandleUpdateUser(logger, svs))
+	r.With(middleware.Authorize(auth.Policy{
+		Roles:      []string{"Employee"},
+		Scopes:     []string{"users:read"},
+		OwnerRoles: []string{"Customer"},
+		OwnerParam: "ID",
+		Owner:      userOwner(svs),
+	})).Get("/user/{ID}", users.get)
+
+	r.With(middleware.Authorize(auth.Policy{
+		Roles:  []string{"Employee"},
+		Scopes: []string{"users:write"},
+	})).Put("/user/{ID}", users.update)
+}
+
+// registerAPIKeyRoutes registers the routes that create, list and revoke API keys.
+func registerAPIKeyRoutes(r chi.Router, service *services.APIKeyService) {
+	r.Route("/admin/api-keys", func(r chi.Router) {
+		r.Use(middleware.Authorize(auth.Policy{
+			Roles: []string{"Admin"},
+		}))
+
+		r.Post("/", handlers.HandleCreateAPIKey(service))
+		r.Get("/", handlers.HandleListAPIKeys(service))
+		r.Delete("/{ID}", handlers.HandleRevokeAPIKey(service))
+	})
+}
+
+// userOwner returns an auth.OwnerLookup that resolves the `user_id` of the user addressed by a
+// route's {ID} parameter.
+func userOwner(svs *services.UserService) auth.OwnerLookup {
+	return func(ctx context.Context, resourceID string) (string, error) {
+		ID, err := strconv.Atoi(resourceID)
+		if err != nil {
+			return "", nil
+		}
+
+		user, err := svs.GetUser(ctx, ID)
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			return "", nil
+		case err != nil:
+			return "", fmt.Errorf("[in routes.userOwner]: %w", err)
+		}
+
+		return strconv.Itoa(int(user.UserID)), nil
+	}
 }
@@ -0,0 +1,222 @@
+package routes
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/health"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/metrics"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/middleware"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/redact"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/services"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/testutil"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMetrics(t *testing.T) {
+	tests := map[string]struct {
+		admin                bool
+		expectedRouterStatus int
+		expectedAdminStatus  int
+	}{
+		"metrics on main router": {
+			admin:                false,
+			expectedRouterStatus: http.StatusOK,
+			expectedAdminStatus:  http.StatusNotFound,
+		},
+		"metrics on admin router": {
+			admin:                true,
+			expectedRouterStatus: http.StatusNotFound,
+			expectedAdminStatus:  http.StatusOK,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, _, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("creating database mock: %v", err)
+			}
+			defer db.Close()
+
+			router := chi.NewRouter()
+			admin := chi.NewRouter()
+			var adminRouter chi.Router
+			if tc.admin {
+				adminRouter = admin
+			}
+
+			RegisterRoutes(
+				router,
+				services.NewUserService(db, nil),
+				WithRegisterHealthRoute(health.New(time.Second, 0)),
+				WithMetrics(metrics.New(db), adminRouter),
+			)
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			assert.Equal(t, tc.expectedRouterStatus, recorder.Code)
+
+			recorder = httptest.NewRecorder()
+			admin.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			assert.Equal(t, tc.expectedAdminStatus, recorder.Code)
+			if tc.admin {
+				assert.Contains(t, recorder.Body.String(), `http_requests_total{method="GET",route="/livez",status="200"} 1`)
+			}
+		})
+	}
+}
+
+func TestWithAdminRoutes(t *testing.T) {
+	issuer := testutil.NewJWTIssuer(t)
+	verifier := auth.NewVerifier(auth.NewJWKS(issuer.JWKSURL(), time.Hour), "https://issuer.test", "users-api", 0)
+	token := func(roles ...string) string {
+		return "Bearer " + issuer.Sign("RS256", testutil.RSAKeyID, map[string]any{
+			"sub":   "user-1",
+			"iss":   "https://issuer.test",
+			"aud":   "users-api",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": roles,
+		})
+	}
+
+	tests := map[string]struct {
+		method        string
+		path          string
+		authorization string
+		body          string
+		expectedCode  int
+		expectedLevel slog.Level
+	}{
+		"anonymous": {
+			method:        http.MethodGet,
+			path:          "/admin/loglevel",
+			expectedCode:  http.StatusUnauthorized,
+			expectedLevel: slog.LevelInfo,
+		},
+		"not an admin": {
+			method:        http.MethodPut,
+			path:          "/admin/loglevel",
+			authorization: token("Employee"),
+			body:          `{"level":"DEBUG"}`,
+			expectedCode:  http.StatusForbidden,
+			expectedLevel: slog.LevelInfo,
+		},
+		"admin changes level": {
+			method:        http.MethodPut,
+			path:          "/admin/loglevel",
+			authorization: token("Admin"),
+			body:          `{"level":"DEBUG"}`,
+			expectedCode:  http.StatusOK,
+			expectedLevel: slog.LevelDebug,
+		},
+		"admin reads profiles": {
+			method:        http.MethodGet,
+			path:          "/debug/pprof/",
+			authorization: token("Admin"),
+			expectedCode:  http.StatusOK,
+			expectedLevel: slog.LevelInfo,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, _, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("creating database mock: %v", err)
+			}
+			defer db.Close()
+
+			level := new(slog.LevelVar)
+			router := chi.NewRouter()
+			admin := chi.NewRouter()
+			RegisterRoutes(
+				router,
+				services.NewUserService(db, nil),
+				WithVerifier(verifier),
+				WithAdminRoutes(admin, level, struct{ Env string }{Env: "test"}, redact.NewPolicy(nil, nil)),
+			)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			admin.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Equal(t, tc.expectedLevel, level.Level())
+
+			// the admin routes are never served on the main router
+			recorder = httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(tc.method, tc.path, nil))
+			assert.Equal(t, http.StatusNotFound, recorder.Code)
+		})
+	}
+}
+
+func TestVersions(t *testing.T) {
+	tests := map[string]struct {
+		path                string
+		expectedCode        int
+		expectedDeprecation string
+		expectedLink        string
+	}{
+		"deprecated version": {
+			path:                "/api/v1/user",
+			expectedCode:        http.StatusUnauthorized,
+			expectedDeprecation: "@1767225600",
+			expectedLink:        `</api/v2>; rel="successor-version"`,
+		},
+		"latest version": {
+			path:         "/api/v2/user/1",
+			expectedCode: http.StatusUnauthorized,
+		},
+		"unknown version": {
+			path:         "/api/v3/user",
+			expectedCode: http.StatusNotFound,
+		},
+		"outside base path": {
+			path:         "/v2/user",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, _, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("creating database mock: %v", err)
+			}
+			defer db.Close()
+
+			router := chi.NewRouter()
+			RegisterRoutes(
+				router,
+				services.NewUserService(db, nil),
+				WithBasePath("/api"),
+				WithDeprecations(map[string]middleware.Deprecation{
+					"v1": {At: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+				}),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("X-Tenant-ID", "tenant-a")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Equal(t, tc.expectedDeprecation, recorder.Header().Get("Deprecation"))
+			assert.Equal(t, tc.expectedLink, recorder.Header().Get("Link"))
+		})
+	}
+}
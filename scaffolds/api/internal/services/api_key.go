@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/api/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/tenant"
+)
+
+type APIKeyService struct {
+	database *sql.DB
+}
+
+// NewAPIKeyService returns a new APIKeyService struct.
+func NewAPIKeyService(db *sql.DB) *APIKeyService {
+	return &APIKeyService{
+		database: db,
+	}
+}
+
+// CreateAPIKey generates a new API key for the tenant in ctx and stores its hash. It returns the
+// stored key and the plain API key, which is not stored and cannot be recovered.
+func (s APIKeyService) CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, string, error) {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return models.APIKey{}, "", fmt.Errorf("[in services.CreateAPIKey]: %w", tenant.ErrMissing)
+	}
+
+	plain, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return models.APIKey{}, "", fmt.Errorf("[in services.CreateAPIKey]: %w", err)
+	}
+
+	err = s.database.QueryRowContext(
+		ctx,
+		`
+		INSERT INTO "api_keys" (
+			"tenant_id",
+			"prefix",
+			"hash",
+			"owner",
+			"roles",
+			"scopes",
+			"expires_at"
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING
+			"id",
+			"created_at"
+		`,
+		tenantID,
+		prefix,
+		hash,
+		key.Owner,
+		strings.Join(key.Roles, " "),
+		strings.Join(key.Scopes, " "),
+		nullTime(key.ExpiresAt),
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return models.APIKey{}, "", fmt.Errorf("[in services.CreateAPIKey] failed to create api key: %w", err)
+	}
+
+	key.Prefix = prefix
+	return key, plain, nil
+}
+
+// ListAPIKeys returns all API keys, including revoked and expired ones, that belong to the tenant
+// in ctx.
+func (s APIKeyService) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return []models.APIKey{}, fmt.Errorf("[in services.ListAPIKeys]: %w", tenant.ErrMissing)
+	}
+
+	rows, err := s.database.QueryContext(
+		ctx,
+		`
+		SELECT
+			"id",
+			"prefix",
+			"owner",
+			"roles",
+			"scopes",
+			"expires_at",
+			"last_used_at",
+			"revoked_at",
+			"created_at"
+		FROM
+			"api_keys"
+		WHERE
+			"tenant_id" = $1
+		ORDER BY
+			"id"
+		`,
+		tenantID,
+	)
+	if err != nil {
+		return []models.APIKey{}, fmt.Errorf("[in services.ListAPIKeys] failed to get api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		var key models.APIKey
+		var roles, scopes string
+		var expiresAt, lastUsedAt, revokedAt sql.NullTime
+		err = rows.Scan(
+			&key.ID,
+			&key.Prefix,
+			&key.Owner,
+			&roles,
+			&scopes,
+			&expiresAt,
+			&lastUsedAt,
+			&revokedAt,
+			&key.CreatedAt,
+		)
+		if err != nil {
+			return []models.APIKey{}, fmt.Errorf("[in services.ListAPIKeys] failed to scan api key from row: %w", err)
+		}
+		key.Roles = strings.Fields(roles)
+		key.Scopes = strings.Fields(scopes)
+		key.ExpiresAt = expiresAt.Time
+		key.LastUsedAt = lastUsedAt.Time
+		key.RevokedAt = revokedAt.Time
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []models.APIKey{}, fmt.Errorf("[in services.ListAPIKeys] failed to scan api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key of the tenant in ctx by ID. Keys that do not exist or are
+// already revoked result in ErrNotFound.
+func (s APIKeyService) RevokeAPIKey(ctx context.Context, ID int) error {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("[in services.RevokeAPIKey]: %w", tenant.ErrMissing)
+	}
+
+	result, err := s.database.ExecContext(
+		ctx,
+		`
+		UPDATE
+			"api_keys"
+		SET
+			"revoked_at" = NOW()
+		WHERE
+			"id" = $1
+			AND "tenant_id" = $2
+			AND "revoked_at" IS NULL
+		`,
+		ID,
+		tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("[in services.RevokeAPIKey] failed to revoke api key: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("[in services.RevokeAPIKey] failed to revoke api key: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("[in services.RevokeAPIKey] api key %d: %w", ID, ErrNotFound)
+	}
+
+	return nil
+}
+
+// ValidateAPIKey returns the principal for an active API key and records its use. Malformed,
+// unknown, revoked and expired keys result in auth.ErrInvalidAPIKey. The stored hash is compared
+// in constant time.
+func (s APIKeyService) ValidateAPIKey(ctx context.Context, key string) (auth.Principal, error) {
+	prefix, hash, err := auth.ParseAPIKey(key)
+	if err != nil {
+		return auth.Principal{}, fmt.Errorf("[in services.ValidateAPIKey]: %w", err)
+	}
+
+	var ID int
+	var tenantID, owner, roles, scopes string
+	var stored []byte
+	var expiresAt sql.NullTime
+	err = s.database.QueryRowContext(
+		ctx,
+		`
+		SELECT
+			"id",
+			"tenant_id",
+			"hash",
+			"owner",
+			"roles",
+			"scopes",
+			"expires_at"
+		FROM
+			"api_keys"
+		WHERE
+			"prefix" = $1
+			AND "revoked_at" IS NULL
+			AND ("expires_at" IS NULL OR "expires_at" > NOW())
+		`,
+		prefix,
+	).Scan(&ID, &tenantID, &stored, &owner, &roles, &scopes, &expiresAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return auth.Principal{}, fmt.Errorf("[in services.ValidateAPIKey] no active key %q: %w", prefix, auth.ErrInvalidAPIKey)
+	case err != nil:
+		return auth.Principal{}, fmt.Errorf("[in services.ValidateAPIKey] failed to get api key: %w", err)
+	}
+
+	if !auth.MatchAPIKeyHash(hash, stored) {
+		return auth.Principal{}, fmt.Errorf("[in services.ValidateAPIKey] secret mismatch for %q: %w", prefix, auth.ErrInvalidAPIKey)
+	}
+
+	_, err = s.database.ExecContext(
+		ctx,
+		`
+		UPDATE
+			"api_keys"
+		SET
+			"last_used_at" = NOW()
+		WHERE
+			"id" = $1
+		`,
+		ID,
+	)
+	if err != nil {
+		return auth.Principal{}, fmt.Errorf("[in services.ValidateAPIKey] failed to record use: %w", err)
+	}
+
+	return auth.Principal{
+		Subject:   owner,
+		Tenant:    tenantID,
+		Roles:     strings.Fields(roles),
+		Scopes:    strings.Fields(scopes),
+		Method:    auth.MethodAPIKey,
+		KeyPrefix: prefix,
+		ExpiresAt: expiresAt.Time,
+	}, nil
+}
+
+// nullTime maps the zero time to NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -3,27 +3,71 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
+	"github.com/captechconsulting/go-microservice-templates/api/internal/encryption"
 	"github.com/captechconsulting/go-microservice-templates/api/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/telemetry"
+	"github.com/captechconsulting/go-microservice-templates/api/internal/tenant"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ErrNotFound is returned when the requested object does not exist for the tenant in ctx.
+var ErrNotFound = errors.New("not found")
+
+// ErrorObserver is notified of the errors returned by service operations, classified by kind:
+// `not_found`, `missing_tenant`, `canceled`, `timeout` or `internal`.
+type ErrorObserver interface {
+	ObserveError(operation string, kind string)
+}
+
 type UserService struct {
 	database *sql.DB
+	envelope *encryption.Envelope
+	observer ErrorObserver
 }
 
-// NewUserService returns a new UserService struct.
-func NewUserService(db *sql.DB) *UserService {
+// NewUserService returns a new UserService struct. The fields of models.User tagged `encrypt` are
+// stored encrypted with envelope.
+func NewUserService(db *sql.DB, envelope *encryption.Envelope) *UserService {
 	return &UserService{
 		database: db,
+		envelope: envelope,
 	}
 }
 
-// ListUsers returns a list of all UserService objects from the database.
-func (s UserService) ListUsers(ctx context.Context) ([]models.User, error) {
+// ObserveErrors reports the errors returned by the methods of s to observer.
+func (s *UserService) ObserveErrors(observer ErrorObserver) {
+	s.observer = observer
+}
+
+// ListUsers returns a list of all UserService objects from the database that belong to the tenant
+// in ctx.
+func (s UserService) ListUsers(ctx context.Context) (_ []models.User, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "UserService.ListUsers")
+	defer func() { s.end(span, "UserService.ListUsers", err) }()
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return []models.User{}, fmt.Errorf("[in services.ListUsers]: %w", tenant.ErrMissing)
+	}
+
 	rows, err := s.database.QueryContext(
 		ctx,
-		`SELECT * FROM "users"`,
+		`
+		SELECT
+			"id",
+			"first_name",
+			"last_name",
+			"role",
+			"user_id"
+		FROM
+			"users"
+		WHERE
+			"tenant_id" = $1
+		`,
+		tenantID,
 	)
 	if err != nil {
 		return []models.User{}, fmt.Errorf("[in services.ListUsers] failed to get users: %w", err)
@@ -37,6 +81,9 @@ func (s UserService) ListUsers(ctx context.Context) ([]models.User, error) {
 		if err != nil {
 			return []models.User{}, fmt.Errorf("[in services.ListUsers] failed to scan user from row: %w", err)
 		}
+		if err = s.envelope.DecryptFields(ctx, &user, tenantID); err != nil {
+			return []models.User{}, fmt.Errorf("[in services.ListUsers] failed to decrypt user: %w", err)
+		}
 		users = append(users, user)
 	}
 
@@ -47,9 +94,67 @@ func (s UserService) ListUsers(ctx context.Context) ([]models.User, error) {
 	return users, nil
 }
 
-// UpdateUser updates am UserService objects from the database by ID.
-func (s UserService) UpdateUser(ctx context.Context, ID int, user models.User) (models.User, error) {
-	_, err := s.database.ExecContext(
+// GetUser returns a single UserService object from the database by ID. Only users belonging to
+// the tenant in ctx are returned, any other ID results in ErrNotFound.
+func (s UserService) GetUser(ctx context.Context, ID int) (_ models.User, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "UserService.GetUser")
+	defer func() { s.end(span, "UserService.GetUser", err) }()
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return models.User{}, fmt.Errorf("[in services.GetUser]: %w", tenant.ErrMissing)
+	}
+
+	var user models.User
+	err = s.database.QueryRowContext(
+		ctx,
+		`
+		SELECT
+			"id",
+			"first_name",
+			"last_name",
+			"role",
+			"user_id"
+		FROM
+			"users"
+		WHERE
+			"id" = $1
+			AND "tenant_id" = $2
+		`,
+		ID,
+		tenantID,
+	).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Role, &user.UserID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return models.User{}, fmt.Errorf("[in services.GetUser] user %d: %w", ID, ErrNotFound)
+	case err != nil:
+		return models.User{}, fmt.Errorf("[in services.GetUser] failed to get user: %w", err)
+	}
+
+	if err = s.envelope.DecryptFields(ctx, &user, tenantID); err != nil {
+		return models.User{}, fmt.Errorf("[in services.GetUser] failed to decrypt user: %w", err)
+	}
+
+	return user, nil
+}
+
+// UpdateUser updates am UserService objects from the database by ID. Only users belonging to the
+// tenant in ctx can be updated, any other ID results in ErrNotFound.
+func (s UserService) UpdateUser(ctx context.Context, ID int, user models.User) (_ models.User, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "UserService.UpdateUser")
+	defer func() { s.end(span, "UserService.UpdateUser", err) }()
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return models.User{}, fmt.Errorf("[in services.UpdateUser]: %w", tenant.ErrMissing)
+	}
+
+	stored := user
+	if err = s.envelope.EncryptFields(ctx, &stored, tenantID); err != nil {
+		return models.User{}, fmt.Errorf("[in services.UpdateUser] failed to encrypt user: %w", err)
+	}
+
+	result, err := s.database.ExecContext(
 		ctx,
 		`
 		UPDATE
@@ -58,20 +163,115 @@ func (s UserService) UpdateUser(ctx context.Context, ID int, user models.User) (
 			"first_name" = $1,
 			"last_name" = $2,
 			"role" = $3,
-			"user_id" = $4
+			"user_id" = $4,
+			"last_name_index" = $5
 		WHERE
-			"id" = $5
+			"id" = $6
+			AND "tenant_id" = $7
 		`,
-		user.FirstName,
-		user.LastName,
+		stored.FirstName,
+		stored.LastName,
 		user.Role,
 		user.UserID,
+		s.lastNameIndex(tenantID, user.LastName),
 		ID,
+		tenantID,
 	)
 	if err != nil {
 		return models.User{}, fmt.Errorf("[in services.UpdateUser] failed to update user: %w", err)
 	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return models.User{}, fmt.Errorf("[in services.UpdateUser] failed to update user: %w", err)
+	}
+	if affected == 0 {
+		return models.User{}, fmt.Errorf("[in services.UpdateUser] user %d: %w", ID, ErrNotFound)
+	}
+
 	user.ID = uint(ID)
 	return user, nil
 }
+
+// FindUsersByLastName returns the users belonging to the tenant in ctx whose last name matches
+// lastName, ignoring case. Last names are stored encrypted, so they are matched by blind index.
+func (s UserService) FindUsersByLastName(ctx context.Context, lastName string) (_ []models.User, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "UserService.FindUsersByLastName")
+	defer func() { s.end(span, "UserService.FindUsersByLastName", err) }()
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return []models.User{}, fmt.Errorf("[in services.FindUsersByLastName]: %w", tenant.ErrMissing)
+	}
+
+	rows, err := s.database.QueryContext(
+		ctx,
+		`
+		SELECT
+			"id",
+			"first_name",
+			"last_name",
+			"role",
+			"user_id"
+		FROM
+			"users"
+		WHERE
+			"tenant_id" = $1
+			AND "last_name_index" = $2
+		`,
+		tenantID,
+		s.lastNameIndex(tenantID, lastName),
+	)
+	if err != nil {
+		return []models.User{}, fmt.Errorf("[in services.FindUsersByLastName] failed to get users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		err = rows.Scan(&user.ID, &user.FirstName, &user.LastName, &user.Role, &user.UserID)
+		if err != nil {
+			return []models.User{}, fmt.Errorf("[in services.FindUsersByLastName] failed to scan user from row: %w", err)
+		}
+		if err = s.envelope.DecryptFields(ctx, &user, tenantID); err != nil {
+			return []models.User{}, fmt.Errorf("[in services.FindUsersByLastName] failed to decrypt user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []models.User{}, fmt.Errorf("[in services.FindUsersByLastName] failed to scan users: %w", err)
+	}
+
+	return users, nil
+}
+
+// lastNameIndex returns the blind index of lastName stored in the "last_name_index" column.
+func (s UserService) lastNameIndex(tenantID string, lastName string) string {
+	return s.envelope.BlindIndex(lastName, tenantID+"/last_name")
+}
+
+// end records err, if any, on span, ends it and reports err to the ErrorObserver of s.
+func (s UserService) end(span trace.Span, operation string, err error) {
+	telemetry.End(span, err)
+	if err != nil && s.observer != nil {
+		s.observer.ObserveError(operation, errorKind(err))
+	}
+}
+
+// errorKind classifies err for an ErrorObserver.
+func errorKind(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, tenant.ErrMissing):
+		return "missing_tenant"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "internal"
+	}
+}
@@ -2,30 +2,58 @@ package swagger
 
 import (
 	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
 
 	"github.com/captechconsulting/go-microservice-templates/api/internal/swagger/docs"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/httplog/v2"
 	"github.com/swaggo/http-swagger/v2"
+	"github.com/swaggo/swag"
 )
 
-func RunSwagger(r *chi.Mux, logger *httplog.Logger, host string) {
-	// docs
-	docs.SwaggerInfo.Title = "User Microservice API"
-	docs.SwaggerInfo.Description = "Sample Go API"
-	docs.SwaggerInfo.Version = "1.0"
+// swaggerCSP is the content security policy of the Swagger UI.
+const swaggerCSP = "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:"
 
-	docs.SwaggerInfo.Host = host
-	docs.SwaggerInfo.BasePath = "/lambda"
-
-	docs.SwaggerInfo.Schemes = []string{"http"}
-
-	// handler
-	baseURL := "http://" + host
-
-	r.Get("/swagger/*", httpSwagger.Handler(
-		httpSwagger.URL(baseURL+"/swagger/doc.json"),
-	))
+// specs are the swagger docs of every version of the API, generated with `make swagger`.
+var specs = []struct {
+	version string
+	spec    *swag.Spec
+}{
+	{version: "v1", spec: docs.SwaggerInfov1},
+	{version: "v2", spec: docs.SwaggerInfov2},
+}
 
-	logger.Info(fmt.Sprintf("Swagger URL: %s/swagger/index.html", baseURL))
+// RunSwagger serves the Swagger UI of every version of the API at `/swagger/<version>/`, for the
+// versioned routes served below basePath, see routes.WithBasePath.
+func RunSwagger(r *chi.Mux, logger *slog.Logger, scheme string, host string, basePath string) {
+	baseURL := scheme + "://" + host
+
+	// the UI needs inline scripts and styles, which the API's content security policy forbids
+	ui := r.With(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Security-Policy", swaggerCSP)
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	for _, s := range specs {
+		// docs
+		s.spec.Title = "User Microservice API"
+		s.spec.Description = "Sample Go API"
+		s.spec.Version = strings.TrimPrefix(s.version, "v") + ".0"
+
+		s.spec.Host = host
+		s.spec.BasePath = basePath + "/" + s.version
+
+		s.spec.Schemes = []string{scheme}
+
+		// handler
+		ui.Get("/swagger/"+s.version+"/*", httpSwagger.Handler(
+			httpSwagger.URL(baseURL+"/swagger/"+s.version+"/doc.json"),
+			httpSwagger.InstanceName(s.spec.InstanceName()),
+		))
+
+		logger.Info(fmt.Sprintf("Swagger URL: %s/swagger/%s/index.html", baseURL, s.version))
+	}
 }
@@ -0,0 +1,102 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	tests := map[string]struct {
+		exporter    string
+		expectedErr bool
+	}{
+		"none":             {exporter: "none"},
+		"stdout":           {exporter: "stdout"},
+		"otlp":             {exporter: "otlp"},
+		"unknown exporter": {exporter: "zipkin", expectedErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			provider, err := NewTracerProvider(context.Background(), tc.exporter, "user-microservice")
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, provider, otel.GetTracerProvider())
+			assert.NoError(t, provider.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestPropagator(t *testing.T) {
+	tests := map[string]struct {
+		header          string
+		value           string
+		expectedTraceID string
+	}{
+		"w3c trace context": {
+			header:          "traceparent",
+			value:           "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expectedTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		"x-ray trace header": {
+			header:          "X-Amzn-Trace-Id",
+			value:           "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1",
+			expectedTraceID: "5759e988bd862e3fe1be46a994272793",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			headers := http.Header{}
+			headers.Set(tc.header, tc.value)
+
+			ctx := Propagator().Extract(context.Background(), propagation.HeaderCarrier(headers))
+			spanContext := trace.SpanContextFromContext(ctx)
+			assert.Equal(t, tc.expectedTraceID, spanContext.TraceID().String())
+			assert.True(t, spanContext.IsSampled())
+
+			// both headers are written for downstream services
+			injected := http.Header{}
+			Propagator().Inject(ctx, propagation.HeaderCarrier(injected))
+			assert.NotEmpty(t, injected.Get("traceparent"))
+			assert.NotEmpty(t, injected.Get("X-Amzn-Trace-Id"))
+		})
+	}
+}
+
+func TestEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(TracerName)
+
+	_, span := tracer.Start(context.Background(), "ok")
+	End(span, nil)
+	_, span = tracer.Start(context.Background(), "failed")
+	End(span, errors.New("test"))
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+		assert.Equal(t, "test", spans[1].Status().Description)
+		assert.Len(t, spans[1].Events(), 1)
+	}
+}
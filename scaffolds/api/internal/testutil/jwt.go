@@ -0,0 +1,136 @@
+package testutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+const (
+	// RSAKeyID is the key ID of the RSA key served by a JWTIssuer.
+	RSAKeyID = "test-rsa"
+	// ECKeyID is the key ID of the EC key served by a JWTIssuer.
+	ECKeyID = "test-ec"
+)
+
+// JWTIssuer signs test tokens with keys generated at test time and serves the matching JWKS
+// document from a local HTTP server.
+type JWTIssuer struct {
+	server   *httptest.Server
+	rsaKey   *rsa.PrivateKey
+	ecKey    *ecdsa.PrivateKey
+	requests atomic.Int64
+}
+
+// NewJWTIssuer generates an RSA and an EC P-256 key and starts a JWKS server that is closed when
+// the test completes.
+func NewJWTIssuer(t *testing.T) *JWTIssuer {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key: %v", err)
+	}
+
+	issuer := &JWTIssuer{rsaKey: rsaKey, ecKey: ecKey}
+	issuer.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issuer.requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(issuer.jwks()))
+	}))
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+// JWKSURL returns the URL the JWKS document is served from.
+func (i *JWTIssuer) JWKSURL() string {
+	return i.server.URL
+}
+
+// Requests returns how many times the JWKS document has been fetched.
+func (i *JWTIssuer) Requests() int64 {
+	return i.requests.Load()
+}
+
+// Sign returns a compact JWT with the given claims. alg must be RS256 or ES256, and the token is
+// signed with the matching key. kid overrides the key ID in the header when not empty.
+func (i *JWTIssuer) Sign(alg string, kid string, claims map[string]any) string {
+	if kid == "" {
+		kid = map[string]string{"RS256": RSAKeyID, "ES256": ECKeyID}[alg]
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(ToJSONString(map[string]string{
+		"alg": alg,
+		"kid": kid,
+		"typ": "JWT",
+	})))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(ToJSONString(claims)))
+	digest := sha256.Sum256([]byte(header + "." + payload))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		sig, err := rsa.SignPKCS1v15(rand.Reader, i.rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			panic(fmt.Sprintf("sign RS256 token: %v", err))
+		}
+		signature = sig
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, i.ecKey, digest[:])
+		if err != nil {
+			panic(fmt.Sprintf("sign ES256 token: %v", err))
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	default:
+		panic(fmt.Sprintf("unsupported alg %q", alg))
+	}
+
+	return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (i *JWTIssuer) jwks() string {
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	coordinate := func(v *big.Int) string { return encode(v.FillBytes(make([]byte, 32))) }
+
+	document, _ := json.Marshal(map[string]any{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": RSAKeyID,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   encode(i.rsaKey.N.Bytes()),
+				"e":   encode(big.NewInt(int64(i.rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": ECKeyID,
+				"use": "sig",
+				"alg": "ES256",
+				"crv": "P-256",
+				"x":   coordinate(i.ecKey.X),
+				"y":   coordinate(i.ecKey.Y),
+			},
+		},
+	})
+
+	return string(document)
+}
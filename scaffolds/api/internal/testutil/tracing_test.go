@@ -0,0 +1,27 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+func TestNewSpanRecorder(t *testing.T) {
+	previous := otel.GetTracerProvider()
+
+	t.Run("records spans", func(t *testing.T) {
+		recorder := NewSpanRecorder(t)
+
+		_, span := otel.Tracer("test").Start(context.Background(), "operation")
+		span.End()
+
+		spans := recorder.Ended()
+		if assert.Len(t, spans, 1) {
+			assert.Equal(t, "operation", spans[0].Name())
+		}
+	})
+
+	assert.Equal(t, previous, otel.GetTracerProvider())
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/config"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/database"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/handlers"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/metrics"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/middleware"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/redact"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/reporting"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/services"
+)
+
+func main() {
+	ctx := context.Background()
+	if err := run(ctx); err != nil {
+		log.Fatalf("Startup failed. err: %v", err)
+	}
+}
+
+// run initializes the configuration, sets up logging, connects to the database, builds the token
+// verifier and API key validator, and starts the AWS Lambda handler for the API Gateway
+// authorizer. It returns an error if any step in this initialization process fails.
+func run(ctx context.Context) error {
+	cfg, err := config.New()
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to load config: %w", err)
+	}
+
+	// sensitive attributes are masked before they reach CloudWatch, and every line logged with an
+	// invocation's context is tagged with its Lambda request ID
+	logHandler, err := logging.NewHandler(os.Stdout, logging.Options{
+		Format:    cfg.LogFormat,
+		Level:     cfg.LogLevel,
+		AddSource: cfg.LogAddSource,
+	})
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
+	logger := slog.New(redact.NewHandler(logHandler, redact.NewPolicy(cfg.LogRedactKeys, cfg.LogRedactAllow)))
+
+	// handlers and middleware log with logging.FromContext, which falls back to the default logger
+	slog.SetDefault(logger)
+
+	// panics are logged with their stack, and sent to Sentry when ERROR_REPORTING_DSN is set
+	var reporter reporting.ErrorReporter = reporting.NewLogReporter()
+	if cfg.ErrorReportingDSN != "" {
+		sentry, err := reporting.NewSentryReporter(
+			&http.Client{Timeout: time.Duration(cfg.ErrorReportingTimeout) * time.Second},
+			cfg.ErrorReportingDSN,
+			cfg.Env,
+		)
+		if err != nil {
+			return fmt.Errorf("[in main.run]: %w", err)
+		}
+		reporter = reporting.Multi(reporter, sentry)
+	}
+
+	// metrics are written to stdout in CloudWatch Embedded Metric Format, see metrics.Emitter
+	emitter := metrics.NewEmitter(os.Stdout, cfg.MetricsNamespace, map[string]string{
+		"FunctionName": lambdacontext.FunctionName,
+	})
+
+	verifier := auth.NewVerifier(
+		auth.NewJWKS(cfg.AuthJWKSURL, time.Duration(cfg.AuthJWKSRefresh)*time.Second),
+		cfg.AuthIssuer,
+		cfg.AuthAudience,
+		time.Duration(cfg.AuthClockSkew)*time.Second,
+	)
+
+	// with IAM auth, connections authenticate with short-lived tokens instead of DATABASE_PASSWORD
+	var dbCredentials database.Credentials = cfg.DBCredentials
+	if cfg.DBIAMAuth {
+		if dbCredentials, err = database.NewIAMCredentials(ctx, cfg.DBHost, cfg.DBPort, cfg.DBUser); err != nil {
+			return fmt.Errorf("[in main.run]: %w", err)
+		}
+	}
+
+	db, err := database.New(
+		ctx,
+		database.DSN{
+			Host:        cfg.DBHost,
+			Port:        cfg.DBPort,
+			Name:        cfg.DBName,
+			SSLMode:     cfg.DBSSLMode,
+			SSLRootCert: cfg.DBSSLRootCert,
+		},
+		dbCredentials,
+		logger,
+		time.Duration(cfg.DBRetryDuration)*time.Second,
+		database.WithSlowQueryThreshold(time.Duration(cfg.DBSlowQueryThreshold)*time.Millisecond),
+	)
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
+
+	defer func() {
+		if err = db.Close(); err != nil {
+			logger.Error("Error closing db connection", "err", err)
+		}
+	}()
+
+	// validated API keys are cached for the lifetime of the execution environment, so revocations
+	// and expiry take effect within the cache TTL
+	keys := auth.NewAPIKeyCache(services.NewAPIKeyService(db), time.Duration(cfg.APIKeyCacheTTL)*time.Second)
+
+	type (
+		request  = events.APIGatewayCustomAuthorizerRequestTypeRequest
+		response = events.APIGatewayCustomAuthorizerResponse
+	)
+	handler := middleware.HandlerFuncT[request, response](handlers.HandleAuthorizer(verifier, keys, handlers.AuthorizerSettings{
+		APIKeyHeader: cfg.APIKeyHeader,
+		TenantClaim:  cfg.TenantClaim,
+	}))
+	// Metrics comes before AuthorizerRecovery, so invocations denied after a panic are counted too
+	handler = middleware.Logger[request, response](logger)(
+		middleware.Metrics[request, response](emitter, nil)(
+			middleware.AuthorizerRecovery(reporter)(handler),
+		),
+	)
+
+	lambda.Start(handler)
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Claims holds the claims of a verified JWT. Registered claims are exposed as fields, any other
+// claim can be read with Value or String.
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	Scopes    []string
+	Roles     []string
+	raw       map[string]any
+}
+
+// Value returns the raw value of the named claim.
+func (c Claims) Value(name string) (any, bool) {
+	value, ok := c.raw[name]
+	return value, ok
+}
+
+// String returns the named claim as a string. Numeric claims are formatted, any other type
+// returns an empty string.
+func (c Claims) String(name string) string {
+	switch value := c.raw[name].(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	default:
+		return ""
+	}
+}
+
+// NewClaims maps a decoded JWT payload to Claims. Numeric claims must be json.Number values, as
+// produced by a json.Decoder with UseNumber.
+func NewClaims(raw map[string]any) (Claims, error) {
+	claims := Claims{raw: raw}
+
+	var err error
+	if claims.Subject, err = stringClaim(raw, "sub"); err != nil {
+		return Claims{}, err
+	}
+	if claims.Issuer, err = stringClaim(raw, "iss"); err != nil {
+		return Claims{}, err
+	}
+	if claims.Audience, err = stringsClaim(raw, "aud"); err != nil {
+		return Claims{}, err
+	}
+	if claims.ExpiresAt, err = timeClaim(raw, "exp"); err != nil {
+		return Claims{}, err
+	}
+	if claims.NotBefore, err = timeClaim(raw, "nbf"); err != nil {
+		return Claims{}, err
+	}
+	if claims.IssuedAt, err = timeClaim(raw, "iat"); err != nil {
+		return Claims{}, err
+	}
+	if claims.Roles, err = stringsClaim(raw, "roles"); err != nil {
+		return Claims{}, err
+	}
+
+	// scopes are either a space delimited "scope" string (RFC 8693) or an "scp" array
+	scope, err := stringClaim(raw, "scope")
+	if err != nil {
+		return Claims{}, err
+	}
+	if scope != "" {
+		claims.Scopes = strings.Fields(scope)
+	} else if claims.Scopes, err = stringsClaim(raw, "scp"); err != nil {
+		return Claims{}, err
+	}
+
+	return claims, nil
+}
+
+func stringClaim(raw map[string]any, name string) (string, error) {
+	value, ok := raw[name]
+	if !ok {
+		return "", nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("claim %q must be a string", name)
+	}
+	return s, nil
+}
+
+func stringsClaim(raw map[string]any, name string) ([]string, error) {
+	switch value := raw[name].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{value}, nil
+	case []any:
+		values := make([]string, len(value))
+		for i, v := range value {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("claim %q must only contain strings", name)
+			}
+			values[i] = s
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("claim %q must be a string or an array of strings", name)
+	}
+}
+
+func timeClaim(raw map[string]any, name string) (time.Time, error) {
+	value, ok := raw[name]
+	if !ok {
+		return time.Time{}, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, fmt.Errorf("claim %q must be a number", name)
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("claim %q must be a number: %w", name, err)
+	}
+	return time.Unix(int64(seconds), 0), nil
+}
+
+type claimsKey struct{}
+
+// WithClaims returns a copy of ctx that carries the verified claims.
+func WithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the verified claims stored in ctx and whether any were present.
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(Claims)
+	return claims, ok
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKey is returned when a token references a key ID that is not in the key set.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// minRefreshInterval limits how often an unknown key ID can force the key set to be refetched, so
+// tokens with made up key IDs cannot be used to hammer the JWKS endpoint.
+const minRefreshInterval = time.Minute
+
+// JWKS is a cached JSON Web Key Set fetched from a remote endpoint. Keys are refetched lazily once
+// the refresh interval has passed, or when a token references a key ID that is not cached, which
+// handles key rotation at the issuer. Refreshing lazily rather than in a background goroutine
+// keeps the cache correct in Lambda, where the process is frozen between invocations.
+type JWKS struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+
+	refreshMu sync.Mutex
+}
+
+// NewJWKS returns a JWKS for the given endpoint. Keys are not fetched until first use.
+func NewJWKS(url string, refreshInterval time.Duration) *JWKS {
+	return &JWKS{
+		url:             url,
+		client:          &http.Client{Timeout: 5 * time.Second},
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}
+}
+
+// Key returns the public key with the given key ID.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok, fetchedAt := j.lookup(kid)
+	stale := j.now().Sub(fetchedAt) >= j.refreshInterval
+	if ok && !stale {
+		return key, nil
+	}
+	if !ok && !stale && j.now().Sub(fetchedAt) < minRefreshInterval {
+		return nil, fmt.Errorf("[in auth.JWKS.Key] kid %q: %w", kid, ErrUnknownKey)
+	}
+
+	if err := j.refresh(ctx, fetchedAt); err != nil {
+		// keep serving a cached key if the endpoint is temporarily unavailable
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("[in auth.JWKS.Key]: %w", err)
+	}
+
+	if key, ok, _ = j.lookup(kid); !ok {
+		return nil, fmt.Errorf("[in auth.JWKS.Key] kid %q: %w", kid, ErrUnknownKey)
+	}
+	return key, nil
+}
+
+func (j *JWKS) lookup(kid string) (crypto.PublicKey, bool, time.Time) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	key, ok := j.keys[kid]
+	return key, ok, j.fetchedAt
+}
+
+// refresh fetches the key set unless another caller already refreshed it after seenFetchedAt.
+func (j *JWKS) refresh(ctx context.Context, seenFetchedAt time.Time) error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	j.mu.RLock()
+	refreshed := j.fetchedAt.After(seenFetchedAt)
+	j.mu.RUnlock()
+	if refreshed {
+		return nil
+	}
+
+	keys, err := j.fetch(ctx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	// record the attempt even on failure so an unavailable endpoint is not retried per request
+	j.fetchedAt = j.now()
+	if err != nil {
+		return err
+	}
+	j.keys = keys
+
+	return nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("[in auth.JWKS.fetch] build request: %w", err)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("[in auth.JWKS.fetch] get %s: %w", j.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("[in auth.JWKS.fetch] get %s: unexpected status %d", j.url, resp.StatusCode)
+	}
+
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, fmt.Errorf("[in auth.JWKS.fetch] decode json: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(document.Keys))
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// skip keys we cannot use rather than failing the whole set
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+// publicKey converts a JWK to an *rsa.PublicKey or *ecdsa.PublicKey.
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("decode n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("decode e: %w", err)
+		}
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if key.N.BitLen() < 2048 {
+			return nil, errors.New("rsa key must be at least 2048 bits")
+		}
+		return key, nil
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decode y: %w", err)
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid P-256 coordinate length")
+		}
+		// ecdh validates that the point is on the curve
+		if _, err = ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, fmt.Errorf("invalid P-256 point: %w", err)
+		}
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
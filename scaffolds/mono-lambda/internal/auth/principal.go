@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Methods a Principal can have been authenticated with.
+const (
+	MethodBearer = "bearer"
+	MethodAPIKey = "api_key"
+)
+
+// Principal is the authenticated caller of a request. With gateway authentication it is built by
+// the API Gateway authorizer and handed to the API functions through the authorizer context.
+// KeyPrefix identifies the API key of API key principals. ExpiresAt is when its credential stops authenticating, it is zero if the credential does not
+// expire or its expiry is unknown.
+type Principal struct {
+	Subject   string
+	UserID    string
+	Tenant    string
+	Roles     []string
+	Scopes    []string
+	Method    string
+	KeyPrefix string
+	ExpiresAt time.Time
+}
+
+// AuthorizerContext encodes the principal as API Gateway authorizer context entries. Entries may
+// only hold strings, numbers and booleans, so roles and scopes are joined.
+func (p Principal) AuthorizerContext() map[string]any {
+	return map[string]any{
+		"subject":    p.Subject,
+		"user_id":    p.UserID,
+		"tenant":     p.Tenant,
+		"roles":      strings.Join(p.Roles, ","),
+		"scopes":     strings.Join(p.Scopes, " "),
+		"method":     p.Method,
+		"key_prefix": p.KeyPrefix,
+	}
+}
+
+// PrincipalFromAuthorizer decodes the entries written by Principal.AuthorizerContext, as found in
+// events.APIGatewayProxyRequestContext.Authorizer.
+func PrincipalFromAuthorizer(entries map[string]any) (Principal, error) {
+	value := func(name string) string {
+		switch v := entries[name].(type) {
+		case string:
+			return v
+		case nil:
+			return ""
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+
+	principal := Principal{
+		Subject:   value("subject"),
+		UserID:    value("user_id"),
+		Tenant:    value("tenant"),
+		Roles:     split(value("roles"), ","),
+		Scopes:    split(value("scopes"), " "),
+		Method:    value("method"),
+		KeyPrefix: value("key_prefix"),
+	}
+	if principal.Subject == "" {
+		return Principal{}, errors.New("authorizer context has no subject")
+	}
+
+	return principal, nil
+}
+
+// split splits s around sep, dropping empty elements. It returns nil if nothing remains.
+func split(s string, sep string) []string {
+	var values []string
+	for _, value := range strings.Split(s, sep) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx that carries the principal.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the caller of the request and whether one is known. The principal
+// is the one stored with WithPrincipal or, failing that, is derived from the verified claims in
+// ctx. A principal derived from claims has no tenant, see the tenant package instead.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	if principal, ok := ctx.Value(principalKey{}).(Principal); ok {
+		return principal, true
+	}
+
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return Principal{}, false
+	}
+	return Principal{
+		Subject: claims.Subject,
+		UserID:  claims.String(OwnerClaim),
+		Roles:   claims.Roles,
+		Scopes:  claims.Scopes,
+		Method:  MethodBearer,
+	}, true
+}
+
+// BearerToken extracts the token from an `Authorization: Bearer <token>` header value.
+func BearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalAuthorizerContext(t *testing.T) {
+	principal := Principal{
+		Subject: "user-1",
+		UserID:  "1001",
+		Tenant:  "tenant-a",
+		Roles:   []string{"Customer", "Employee"},
+		Scopes:  []string{"users:read", "users:write"},
+		Method:  MethodBearer,
+	}
+
+	entries := principal.AuthorizerContext()
+	for name, value := range entries {
+		assert.IsType(t, "", value, "entry %q must be a string", name)
+	}
+
+	// API Gateway adds its own entries, e.g. principalId
+	entries["principalId"] = "user-1"
+	entries["integrationLatency"] = float64(12)
+
+	got, err := PrincipalFromAuthorizer(entries)
+	assert.NoError(t, err)
+	assert.Equal(t, principal, got)
+}
+
+func TestPrincipalFromAuthorizer(t *testing.T) {
+	tests := map[string]struct {
+		entries     map[string]any
+		expected    Principal
+		expectedErr bool
+	}{
+		"all entries": {
+			entries: map[string]any{
+				"subject":    "svc-reporting",
+				"tenant":     "tenant-b",
+				"roles":      "Employee",
+				"scopes":     "users:read",
+				"method":     MethodAPIKey,
+				"key_prefix": "0a1b2c3d",
+			},
+			expected: Principal{
+				Subject:   "svc-reporting",
+				Tenant:    "tenant-b",
+				Roles:     []string{"Employee"},
+				Scopes:    []string{"users:read"},
+				Method:    MethodAPIKey,
+				KeyPrefix: "0a1b2c3d",
+			},
+		},
+		"numeric user id": {
+			entries:  map[string]any{"subject": "user-1", "user_id": float64(1001)},
+			expected: Principal{Subject: "user-1", UserID: "1001"},
+		},
+		"empty lists": {
+			entries:  map[string]any{"subject": "user-1", "roles": "", "scopes": ""},
+			expected: Principal{Subject: "user-1"},
+		},
+		"missing subject": {
+			entries:     map[string]any{"roles": "Employee"},
+			expectedErr: true,
+		},
+		"no entries": {
+			entries:     nil,
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := PrincipalFromAuthorizer(tc.entries)
+
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	claims, err := NewClaims(map[string]any{
+		"sub":     "user-1",
+		"roles":   []any{"Customer"},
+		"scope":   "users:read",
+		"user_id": json.Number("1001"),
+	})
+	assert.NoError(t, err)
+
+	stored := Principal{Subject: "svc-reporting", Tenant: "tenant-b", Method: MethodAPIKey}
+
+	tests := map[string]struct {
+		ctx        context.Context
+		expected   Principal
+		expectedOK bool
+	}{
+		"stored principal": {
+			ctx:        WithPrincipal(context.Background(), stored),
+			expected:   stored,
+			expectedOK: true,
+		},
+		"stored principal wins over claims": {
+			ctx:        WithPrincipal(WithClaims(context.Background(), claims), stored),
+			expected:   stored,
+			expectedOK: true,
+		},
+		"derived from claims": {
+			ctx: WithClaims(context.Background(), claims),
+			expected: Principal{
+				Subject: "user-1",
+				UserID:  "1001",
+				Roles:   []string{"Customer"},
+				Scopes:  []string{"users:read"},
+				Method:  MethodBearer,
+			},
+			expectedOK: true,
+		},
+		"nothing in context": {
+			ctx:        context.Background(),
+			expectedOK: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := PrincipalFromContext(tc.ctx)
+
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := map[string]struct {
+		header        string
+		expectedToken string
+		expectedOK    bool
+	}{
+		"bearer":        {header: "Bearer abc", expectedToken: "abc", expectedOK: true},
+		"lowercase":     {header: "bearer abc", expectedToken: "abc", expectedOK: true},
+		"extra space":   {header: "Bearer  abc ", expectedToken: "abc", expectedOK: true},
+		"empty token":   {header: "Bearer ", expectedOK: false},
+		"other scheme":  {header: "Basic dXNlcjpwYXNz", expectedOK: false},
+		"missing value": {header: "", expectedOK: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			token, ok := BearerToken(tc.header)
+
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedToken, token)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"context"
+	"fmt"
+)
+
+// DBCredentials resolves the database user and password. Either may be a secret reference, see
+// ResolveSecret, which is resolved again once its cache entry expires, so rotated credentials are
+// picked up without a restart.
+type DBCredentials struct {
+	provider SecretProvider
+	user     string
+	password string
+}
+
+// NewDBCredentials returns DBCredentials for user and password, which are plain values or secret
+// references resolved with provider.
+func NewDBCredentials(provider SecretProvider, user string, password string) *DBCredentials {
+	return &DBCredentials{provider: provider, user: user, password: password}
+}
+
+// Get returns the current user and password.
+func (c *DBCredentials) Get(ctx context.Context) (string, string, error) {
+	user, err := ResolveSecret(ctx, c.provider, c.user)
+	if err != nil {
+		return "", "", fmt.Errorf("[in config.DBCredentials.Get] failed to resolve user: %w", err)
+	}
+	password, err := ResolveSecret(ctx, c.provider, c.password)
+	if err != nil {
+		return "", "", fmt.Errorf("[in config.DBCredentials.Get] failed to resolve password: %w", err)
+	}
+
+	return user, password, nil
+}
+
+// Refresh drops the cached secrets the credentials reference and returns them freshly resolved.
+// Plain values never change.
+func (c *DBCredentials) Refresh(ctx context.Context) (string, string, error) {
+	if cache, ok := c.provider.(*SecretCache); ok {
+		for _, value := range []string{c.user, c.password} {
+			if name, ok := secretName(value); ok {
+				cache.Invalidate(name)
+			}
+		}
+	}
+
+	return c.Get(ctx)
+}
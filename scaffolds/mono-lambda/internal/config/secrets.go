@@ -0,0 +1,149 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretScheme starts environment values that reference a secret instead of holding the value.
+const secretScheme = "secret://"
+
+// ErrSecretNotFound is returned by a SecretProvider when the named secret does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider returns the value of the named secret.
+type SecretProvider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// ResolveSecret returns value unchanged unless it is a secret reference of the form
+// `secret://<name>#<key>`, in which case the secret is fetched from provider. If key is given the
+// secret must be a JSON object, as Secrets Manager stores database credentials, and the value of
+// key is returned. Otherwise the whole secret is returned.
+func ResolveSecret(ctx context.Context, provider SecretProvider, value string) (string, error) {
+	reference, ok := strings.CutPrefix(value, secretScheme)
+	if !ok {
+		return value, nil
+	}
+	if provider == nil {
+		return "", errors.New("no secret provider configured")
+	}
+
+	name, key, hasKey := strings.Cut(reference, "#")
+	if name == "" || (hasKey && key == "") {
+		return "", fmt.Errorf("malformed secret reference %q", value)
+	}
+
+	secret, err := provider.GetSecret(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %q: %w", name, err)
+	}
+	if !hasKey {
+		return secret, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret %q is not a JSON object", name)
+	}
+	field, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret %q has no key %q: %w", name, key, ErrSecretNotFound)
+	}
+
+	return jsonValue(field), nil
+}
+
+// secretName returns the name of the secret value references, if it is a secret reference.
+func secretName(value string) (string, bool) {
+	reference, ok := strings.CutPrefix(value, secretScheme)
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(reference, "#")
+	return name, true
+}
+
+// jsonValue returns a JSON string as its plain value, and any other JSON value as is.
+func jsonValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// resolveSecrets returns environment with every secret reference replaced by its value.
+func resolveSecrets(ctx context.Context, provider SecretProvider, environment map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(environment))
+	for name, value := range environment {
+		v, err := ResolveSecret(ctx, provider, value)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", name, err)
+		}
+		resolved[name] = v
+	}
+
+	return resolved, nil
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// SecretCache caches the secrets of another SecretProvider in memory for a fixed TTL, so a warm
+// Lambda or a long running process does not fetch the same secret again on every lookup.
+type SecretCache struct {
+	next    SecretProvider
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]cachedSecret
+}
+
+// NewSecretCache returns a SecretCache in front of next.
+func NewSecretCache(next SecretProvider, ttl time.Duration) *SecretCache {
+	return &SecretCache{
+		next:    next,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cachedSecret),
+	}
+}
+
+// GetSecret returns the cached secret or fetches it from the wrapped provider. Failures are not
+// cached.
+func (c *SecretCache) GetSecret(ctx context.Context, name string) (string, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := c.next.GetSecret(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.entries[name] = cachedSecret{value: value, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+
+	return value, nil
+}
+
+// Invalidate drops the cached value of the named secret, so the next lookup fetches it again.
+func (c *SecretCache) Invalidate(name string) {
+	c.mu.Lock()
+	delete(c.entries, name)
+	c.mu.Unlock()
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSecrets is a SecretProvider backed by a map that counts its lookups.
+type stubSecrets struct {
+	secrets map[string]string
+	calls   int
+}
+
+func (s *stubSecrets) GetSecret(_ context.Context, name string) (string, error) {
+	s.calls++
+	secret, ok := s.secrets[name]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+func TestResolveSecret(t *testing.T) {
+	provider := &stubSecrets{secrets: map[string]string{
+		"db":       `{"username": "db-user", "password": "db-password", "port": 5432}`,
+		"/app/key": "plain-value",
+	}}
+
+	tests := map[string]struct {
+		provider    SecretProvider
+		value       string
+		expected    string
+		expectedErr bool
+	}{
+		"plain value": {
+			provider: provider,
+			value:    "db-password",
+			expected: "db-password",
+		},
+		"plain value without provider": {
+			provider: nil,
+			value:    "db-password",
+			expected: "db-password",
+		},
+		"whole secret": {
+			provider: provider,
+			value:    "secret:///app/key",
+			expected: "plain-value",
+		},
+		"key of json secret": {
+			provider: provider,
+			value:    "secret://db#password",
+			expected: "db-password",
+		},
+		"non string key": {
+			provider: provider,
+			value:    "secret://db#port",
+			expected: "5432",
+		},
+		"missing key": {
+			provider:    provider,
+			value:       "secret://db#host",
+			expectedErr: true,
+		},
+		"key of non json secret": {
+			provider:    provider,
+			value:       "secret:///app/key#password",
+			expectedErr: true,
+		},
+		"unknown secret": {
+			provider:    provider,
+			value:       "secret://other",
+			expectedErr: true,
+		},
+		"malformed reference": {
+			provider:    provider,
+			value:       "secret://#password",
+			expectedErr: true,
+		},
+		"no provider": {
+			provider:    nil,
+			value:       "secret://db#password",
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ResolveSecret(context.Background(), tc.provider, tc.value)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestSecretCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("caches secrets until the ttl passes", func(t *testing.T) {
+		next := &stubSecrets{secrets: map[string]string{"db": "v1"}}
+		cache := NewSecretCache(next, time.Minute)
+		cache.now = func() time.Time { return now }
+
+		for range 3 {
+			got, err := cache.GetSecret(context.Background(), "db")
+			assert.NoError(t, err)
+			assert.Equal(t, "v1", got)
+		}
+		assert.Equal(t, 1, next.calls)
+
+		next.secrets["db"] = "v2"
+		cache.now = func() time.Time { return now.Add(time.Minute) }
+		got, err := cache.GetSecret(context.Background(), "db")
+		assert.NoError(t, err)
+		assert.Equal(t, "v2", got)
+		assert.Equal(t, 2, next.calls)
+	})
+
+	t.Run("does not cache failures", func(t *testing.T) {
+		next := &stubSecrets{}
+		cache := NewSecretCache(next, time.Minute)
+
+		for range 2 {
+			_, err := cache.GetSecret(context.Background(), "db")
+			assert.True(t, errors.Is(err, ErrSecretNotFound))
+		}
+		assert.Equal(t, 2, next.calls)
+	})
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	err := os.WriteFile(path, []byte(`{"db": {"password": "db-password"}, "token": "abc"}`), 0o600)
+	assert.NoError(t, err)
+
+	provider := NewFileSecretProvider(path)
+
+	got, err := provider.GetSecret(context.Background(), "token")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", got)
+
+	got, err = ResolveSecret(context.Background(), provider, "secret://db#password")
+	assert.NoError(t, err)
+	assert.Equal(t, "db-password", got)
+
+	_, err = provider.GetSecret(context.Background(), "missing")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+
+	_, err = NewFileSecretProvider(filepath.Join(t.TempDir(), "missing.json")).GetSecret(context.Background(), "token")
+	assert.Error(t, err)
+}
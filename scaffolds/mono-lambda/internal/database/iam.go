@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+const (
+	// iamTokenLifetime is how long RDS accepts an IAM auth token for.
+	iamTokenLifetime = 15 * time.Minute
+	// iamTokenRefresh is the age at which a token is replaced, leaving a margin before it expires.
+	iamTokenRefresh = 10 * time.Minute
+	// emptyPayloadHash is the SHA-256 hash of an empty request body.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// IAMCredentials authenticate to RDS or RDS Proxy with IAM auth tokens instead of a password. A
+// token is a SigV4 presigned `connect` request, generated locally from the AWS credentials of the
+// process, and is reused until it is close to expiring.
+type IAMCredentials struct {
+	endpoint    string
+	region      string
+	user        string
+	credentials aws.CredentialsProvider
+	now         func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	issued time.Time
+}
+
+// NewIAMCredentials returns IAMCredentials for user on the database at host and port, signed with
+// the default AWS credentials and region, e.g. those of the Lambda execution role.
+func NewIAMCredentials(ctx context.Context, host string, port string, user string) (*IAMCredentials, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in database.NewIAMCredentials] failed to load AWS config: %w", err)
+	}
+	if awsCfg.Region == "" {
+		return nil, fmt.Errorf("[in database.NewIAMCredentials] AWS region is not set")
+	}
+
+	return &IAMCredentials{
+		endpoint:    net.JoinHostPort(host, port),
+		region:      awsCfg.Region,
+		user:        user,
+		credentials: awsCfg.Credentials,
+		now:         time.Now,
+	}, nil
+}
+
+// Get returns the user and a token that is valid for at least another five minutes.
+func (c *IAMCredentials) Get(ctx context.Context) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && c.now().Sub(c.issued) < iamTokenRefresh {
+		return c.user, c.token, nil
+	}
+	return c.generate(ctx)
+}
+
+// Refresh returns the user and a newly generated token.
+func (c *IAMCredentials) Refresh(ctx context.Context) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generate(ctx)
+}
+
+// generate replaces the cached token. c.mu must be held.
+func (c *IAMCredentials) generate(ctx context.Context) (string, string, error) {
+	credentials, err := c.credentials.Retrieve(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("[in database.IAMCredentials.generate] failed to retrieve AWS credentials: %w", err)
+	}
+
+	issued := c.now()
+	token, err := buildIAMToken(ctx, c.endpoint, c.region, c.user, credentials, issued)
+	if err != nil {
+		return "", "", err
+	}
+	c.token, c.issued = token, issued
+
+	return c.user, token, nil
+}
+
+// buildIAMToken presigns an RDS `connect` request for user at endpoint, valid for
+// iamTokenLifetime from signingTime. The token is the presigned URL without its scheme.
+func buildIAMToken(
+	ctx context.Context,
+	endpoint string,
+	region string,
+	user string,
+	credentials aws.Credentials,
+	signingTime time.Time,
+) (string, error) {
+	query := url.Values{
+		"Action":        {"connect"},
+		"DBUser":        {user},
+		"X-Amz-Expires": {fmt.Sprint(int(iamTokenLifetime.Seconds()))},
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+endpoint+"/?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("[in database.buildIAMToken] failed to build request: %w", err)
+	}
+
+	signed, _, err := v4.NewSigner().PresignHTTP(ctx, credentials, request, emptyPayloadHash, "rds-db", region, signingTime)
+	if err != nil {
+		return "", fmt.Errorf("[in database.buildIAMToken] failed to sign request: %w", err)
+	}
+
+	return strings.TrimPrefix(signed, "https://"), nil
+}
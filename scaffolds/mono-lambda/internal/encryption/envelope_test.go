@@ -0,0 +1,219 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testIndexKey = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+
+// countingKeys counts the calls made to a KeyProvider.
+type countingKeys struct {
+	KeyProvider
+	generated int
+	decrypted int
+}
+
+func (c *countingKeys) GenerateDataKey(ctx context.Context) (DataKey, error) {
+	c.generated++
+	return c.KeyProvider.GenerateDataKey(ctx)
+}
+
+func (c *countingKeys) DecryptDataKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	c.decrypted++
+	return c.KeyProvider.DecryptDataKey(ctx, keyID, wrapped)
+}
+
+func newTestKeys(t *testing.T, current string) *LocalKeyProvider {
+	t.Helper()
+
+	keys, err := NewLocalKeyProvider(current, map[string][]byte{
+		"key-1": bytes.Repeat([]byte{1}, 32),
+		"key-2": bytes.Repeat([]byte{2}, 32),
+	})
+	if err != nil {
+		t.Fatalf("creating key provider: %v", err)
+	}
+
+	return keys
+}
+
+func newTestEnvelope(t *testing.T, keys KeyProvider) *Envelope {
+	t.Helper()
+
+	envelope, err := NewEnvelope(keys, testIndexKey)
+	if err != nil {
+		t.Fatalf("creating envelope: %v", err)
+	}
+
+	return envelope
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	envelope := newTestEnvelope(t, newTestKeys(t, "key-1"))
+
+	encrypted, err := envelope.Encrypt(ctx, "Ada", "tenant-a/first_name")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, envelopePrefix))
+	assert.NotContains(t, encrypted, "Ada")
+
+	tests := map[string]struct {
+		value       string
+		scope       string
+		expected    string
+		expectedErr bool
+	}{
+		"encrypted value": {
+			value:    encrypted,
+			scope:    "tenant-a/first_name",
+			expected: "Ada",
+		},
+		"other scope": {
+			value:       encrypted,
+			scope:       "tenant-b/first_name",
+			expectedErr: true,
+		},
+		"plaintext value": {
+			value:    "Grace",
+			scope:    "tenant-a/first_name",
+			expected: "Grace",
+		},
+		"malformed value": {
+			value:       envelopePrefix + "a:b",
+			scope:       "tenant-a/first_name",
+			expectedErr: true,
+		},
+		"tampered value": {
+			value:       encrypted[:len(encrypted)-2] + "AA",
+			scope:       "tenant-a/first_name",
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			decrypted, err := envelope.Decrypt(ctx, tc.value, tc.scope)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, decrypted)
+			}
+		})
+	}
+}
+
+func TestEnvelopeDataKeys(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	keys := &countingKeys{KeyProvider: newTestKeys(t, "key-1")}
+	envelope := newTestEnvelope(t, keys)
+	envelope.now = func() time.Time { return now }
+
+	first, _ := envelope.Encrypt(ctx, "Ada", "first_name")
+	second, _ := envelope.Encrypt(ctx, "Grace", "first_name")
+	assert.Equal(t, 1, keys.generated)
+
+	now = now.Add(dataKeyLifetime)
+	third, _ := envelope.Encrypt(ctx, "Linda", "first_name")
+	assert.Equal(t, 2, keys.generated)
+
+	// a fresh envelope unwraps each data key once
+	reader := &countingKeys{KeyProvider: keys.KeyProvider}
+	decrypter := newTestEnvelope(t, reader)
+	for _, value := range []string{first, second, third, first} {
+		_, err := decrypter.Decrypt(ctx, value, "first_name")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, reader.decrypted)
+}
+
+func TestEnvelopeKeyRotation(t *testing.T) {
+	ctx := context.Background()
+
+	old, err := newTestEnvelope(t, newTestKeys(t, "key-1")).Encrypt(ctx, "Ada", "first_name")
+	assert.NoError(t, err)
+
+	// key-2 becomes current, values written under key-1 stay readable
+	rotated := newTestEnvelope(t, newTestKeys(t, "key-2"))
+	decrypted, err := rotated.Decrypt(ctx, old, "first_name")
+	assert.NoError(t, err)
+	assert.Equal(t, "Ada", decrypted)
+
+	current, err := rotated.Encrypt(ctx, "Ada", "first_name")
+	assert.NoError(t, err)
+	keyID, _, _ := strings.Cut(strings.TrimPrefix(current, envelopePrefix), ":")
+	assert.Equal(t, encoding.EncodeToString([]byte("key-2")), keyID)
+
+	// once key-1 is retired, its values can no longer be read
+	retired, err := NewLocalKeyProvider("key-2", map[string][]byte{"key-2": bytes.Repeat([]byte{2}, 32)})
+	assert.NoError(t, err)
+	_, err = newTestEnvelope(t, retired).Decrypt(ctx, old, "first_name")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestEnvelopeBlindIndex(t *testing.T) {
+	envelope := newTestEnvelope(t, newTestKeys(t, "key-1"))
+
+	index := envelope.BlindIndex("Smith", "tenant-a/last_name")
+	assert.Len(t, index, 64)
+	assert.Equal(t, index, envelope.BlindIndex("  smith ", "tenant-a/last_name"))
+	assert.NotEqual(t, index, envelope.BlindIndex("Smith", "tenant-b/last_name"))
+	assert.NotEqual(t, index, envelope.BlindIndex("Smyth", "tenant-a/last_name"))
+}
+
+func TestNewEnvelope(t *testing.T) {
+	keys := newTestKeys(t, "key-1")
+
+	tests := map[string]struct {
+		indexKey    string
+		expectedErr bool
+	}{
+		"valid key":      {indexKey: testIndexKey},
+		"short key":      {indexKey: base64.StdEncoding.EncodeToString([]byte("short")), expectedErr: true},
+		"not base64 key": {indexKey: "not base64!", expectedErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewEnvelope(keys, tc.indexKey)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEnvelopeFields(t *testing.T) {
+	type record struct {
+		Name  string `encrypt:"name"`
+		Email string `encrypt:"email"`
+		Role  string
+	}
+	ctx := context.Background()
+	envelope := newTestEnvelope(t, newTestKeys(t, "key-1"))
+
+	r := record{Name: "Ada", Email: "ada@example.com", Role: "Customer"}
+	assert.NoError(t, envelope.EncryptFields(ctx, &r, "tenant-a"))
+	assert.True(t, strings.HasPrefix(r.Name, envelopePrefix))
+	assert.True(t, strings.HasPrefix(r.Email, envelopePrefix))
+	assert.Equal(t, "Customer", r.Role)
+
+	// fields are scoped by their column
+	swapped := record{Name: r.Email}
+	assert.Error(t, envelope.DecryptFields(ctx, &swapped, "tenant-a"))
+
+	assert.NoError(t, envelope.DecryptFields(ctx, &r, "tenant-a"))
+	assert.Equal(t, record{Name: "Ada", Email: "ada@example.com", Role: "Customer"}, r)
+
+	assert.Error(t, envelope.EncryptFields(ctx, r, "tenant-a"))
+}
@@ -0,0 +1,50 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// fieldTag marks string fields that are stored encrypted. Its value is the name of the column the
+// field is stored in, which scopes the ciphertext.
+const fieldTag = "encrypt"
+
+// EncryptFields encrypts, in place, the string fields tagged `encrypt:"<column>"` of the struct v
+// points to. Each field is encrypted under scope and its column, see Encrypt.
+func (e *Envelope) EncryptFields(ctx context.Context, v any, scope string) error {
+	return e.transformFields(v, func(value string, column string) (string, error) {
+		return e.Encrypt(ctx, value, scope+"/"+column)
+	})
+}
+
+// DecryptFields decrypts, in place, the fields EncryptFields encrypted with the same scope.
+func (e *Envelope) DecryptFields(ctx context.Context, v any, scope string) error {
+	return e.transformFields(v, func(value string, column string) (string, error) {
+		return e.Decrypt(ctx, value, scope+"/"+column)
+	})
+}
+
+// transformFields replaces the value of each tagged field of the struct v points to.
+func (e *Envelope) transformFields(v any, transform func(value string, column string) (string, error)) error {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Pointer || ptr.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("[in encryption.Envelope] %T is not a pointer to a struct", v)
+	}
+
+	s := ptr.Elem()
+	for i := range s.NumField() {
+		column, ok := s.Type().Field(i).Tag.Lookup(fieldTag)
+		if !ok || s.Field(i).Kind() != reflect.String {
+			continue
+		}
+
+		value, err := transform(s.Field(i).String(), column)
+		if err != nil {
+			return fmt.Errorf("[in encryption.Envelope] field %s: %w", s.Type().Field(i).Name, err)
+		}
+		s.Field(i).SetString(value)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package encryption
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// ErrUnknownKey is returned when a data key is wrapped by a master key the KeyProvider does not
+// have.
+var ErrUnknownKey = errors.New("unknown master key")
+
+// dataKeySize is the size of AES-256 data keys.
+const dataKeySize = 32
+
+// DataKey is a data key generated by a KeyProvider, in plaintext for encrypting values and wrapped
+// by the master key KeyID for storing alongside them.
+type DataKey struct {
+	KeyID     string
+	Plaintext []byte
+	Wrapped   []byte
+}
+
+// KeyProvider holds the master keys that wrap data keys. Master keys are rotated by making a new
+// key current; data keys wrapped by earlier keys remain readable for as long as those keys are kept.
+type KeyProvider interface {
+	// GenerateDataKey returns a new AES-256 data key wrapped by the current master key.
+	GenerateDataKey(ctx context.Context) (DataKey, error)
+	// DecryptDataKey unwraps a data key wrapped by the master key keyID.
+	DecryptDataKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// NewKeyProvider returns the KeyProvider named by provider: `kms`, using the KMS key kmsKeyID, or
+// `file`, using the keyfile at file.
+func NewKeyProvider(ctx context.Context, provider string, file string, kmsKeyID string) (KeyProvider, error) {
+	switch provider {
+	case "kms":
+		if kmsKeyID == "" {
+			return nil, errors.New("[in encryption.NewKeyProvider] a KMS key ID is required")
+		}
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("[in encryption.NewKeyProvider] failed to load AWS config: %w", err)
+		}
+		return NewKMSKeyProvider(kms.NewFromConfig(awsCfg), kmsKeyID), nil
+	case "file":
+		keys, err := LoadKeyFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("[in encryption.NewKeyProvider]: %w", err)
+		}
+		return keys, nil
+	default:
+		return nil, fmt.Errorf("[in encryption.NewKeyProvider] unknown key provider %q", provider)
+	}
+}
@@ -2,34 +2,154 @@ package handlers
 
 import (
 	"context"
-	"log/slog"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/middleware"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/services"
 )
 
 type userService interface {
 	ListUsers(ctx context.Context) ([]models.User, error)
+	GetUser(ctx context.Context, ID int) (models.User, error)
 	UpdateUser(ctx context.Context, ID int, user models.User) (models.User, error)
 }
 
+type apiKeyService interface {
+	CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, string, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, ID int) error
+}
+
+// route maps an HTTP method and API Gateway resource to a handler and the policy callers must
+// satisfy to reach it.
+type route struct {
+	method   string
+	resource string
+	policy   auth.Policy
+	handler  HandlerFunc
+}
+
 // API returns a HandlerFunc that handles incoming API Gateway proxy requests. It routes the
-// requests to the appropriate handler based on the HTTP method.
-func API(logger *slog.Logger, service userService) HandlerFunc {
+// requests to the appropriate handler based on the HTTP method and resource, after checking the
+// route's authorization policy against the claims in the request context. OPTIONS requests are
+// answered with the methods of the resource. The API key admin routes are only registered if keys
+// is not nil.
+func API(service userService, keys apiKeyService) HandlerFunc {
+	routes := []route{
+		{
+			method:   http.MethodGet,
+			resource: "/lambda/user",
+			policy: auth.Policy{
+				Roles:  []string{"Employee"},
+				Scopes: []string{"users:read"},
+			},
+			handler: HandleListUsers(service),
+		},
+		{
+			method:   http.MethodGet,
+			resource: "/lambda/user/{ID}",
+			policy: auth.Policy{
+				Roles:      []string{"Employee"},
+				Scopes:     []string{"users:read"},
+				OwnerRoles: []string{"Customer"},
+				OwnerParam: "ID",
+				Owner:      userOwner(service),
+			},
+			handler: HandleGetUser(service),
+		},
+		{
+			method:   http.MethodPut,
+			resource: "/lambda/user/{ID}",
+			policy: auth.Policy{
+				Roles:  []string{"Employee"},
+				Scopes: []string{"users:write"},
+			},
+			handler: HandleUpdateUser(service),
+		},
+	}
+	if keys != nil {
+		admin := auth.Policy{Roles: []string{"Admin"}}
+		routes = append(routes,
+			route{
+				method:   http.MethodPost,
+				resource: "/lambda/admin/api-keys",
+				policy:   admin,
+				handler:  HandleCreateAPIKey(keys),
+			},
+			route{
+				method:   http.MethodGet,
+				resource: "/lambda/admin/api-keys",
+				policy:   admin,
+				handler:  HandleListAPIKeys(keys),
+			},
+			route{
+				method:   http.MethodDelete,
+				resource: "/lambda/admin/api-keys/{ID}",
+				policy:   admin,
+				handler:  HandleRevokeAPIKey(keys),
+			},
+		)
+	}
+	for i, r := range routes {
+		routes[i].handler = middleware.Authorize(r.policy)(r.handler)
+	}
+
 	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-		switch request.HTTPMethod {
-		case http.MethodGet:
-			return HandleListUsers(logger, service)(ctx, request)
-		case http.MethodPut:
-			return HandleUpdateUser(logger, service)(ctx, request)
-		default:
-			logger.Warn("Unsupported route", "method", request.HTTPMethod, "path", request.Path)
+		logger := logging.FromContext(ctx)
+		allowed := []string{}
+		for _, r := range routes {
+			if r.resource != request.Resource {
+				continue
+			}
+			if r.method == request.HTTPMethod {
+				return r.handler(ctx, request)
+			}
+			allowed = append(allowed, r.method)
+		}
+
+		// CORS preflights are answered by middleware.CORS, plain OPTIONS requests list the
+		// methods of the resource
+		if request.HTTPMethod == http.MethodOptions && len(allowed) > 0 {
 			return events.APIGatewayProxyResponse{
-				StatusCode: http.StatusNotFound,
-				Headers:    map[string]string{"Content-Type": "application/json"},
-				Body:       http.StatusText(http.StatusNotFound),
+				StatusCode: http.StatusNoContent,
+				Headers:    map[string]string{"Allow": strings.Join(append(allowed, http.MethodOptions), ", ")},
 			}, nil
 		}
+
+		logger.WarnContext(ctx, "Unsupported route", "method", request.HTTPMethod, "path", request.Path)
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusNotFound,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       http.StatusText(http.StatusNotFound),
+		}, nil
+	}
+}
+
+// userOwner returns an auth.OwnerLookup that resolves the `user_id` of the user addressed by a
+// route's {ID} parameter.
+func userOwner(service userGetter) auth.OwnerLookup {
+	return func(ctx context.Context, resourceID string) (string, error) {
+		ID, err := strconv.Atoi(resourceID)
+		if err != nil {
+			return "", nil
+		}
+
+		user, err := service.GetUser(ctx, ID)
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			return "", nil
+		case err != nil:
+			return "", fmt.Errorf("[in handlers.userOwner]: %w", err)
+		}
+
+		return strconv.Itoa(int(user.UserID)), nil
 	}
 }
@@ -2,11 +2,12 @@ package handlers
 
 import (
 	"context"
-	"log/slog"
+	"encoding/json"
 	"net/http"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
 	"github.com/stretchr/testify/assert"
@@ -15,10 +16,6 @@ import (
 )
 
 func TestAPI(t *testing.T) {
-	mockService := new(serviceMock.MockUserService)
-	logger := slog.Default()
-	handler := API(logger, mockService)
-
 	users := []models.User{
 		{ID: 0, FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001},
 		{ID: 1, FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001},
@@ -27,25 +24,54 @@ func TestAPI(t *testing.T) {
 	userIn := inputUser{FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
 	usersOut := mapMultipleOutput(users)
 
-	ctx := context.Background()
+	withClaims := func(raw map[string]any) context.Context {
+		claims, err := auth.NewClaims(raw)
+		if err != nil {
+			t.Fatalf("building claims: %v", err)
+		}
+		return auth.WithClaims(context.Background(), claims)
+	}
+	employee := withClaims(map[string]any{
+		"roles":   []any{"Employee"},
+		"scope":   "users:read users:write",
+		"user_id": json.Number("1002"),
+	})
+	admin := withClaims(map[string]any{
+		"roles": []any{"Admin"},
+	})
+	customer := withClaims(map[string]any{
+		"roles":   []any{"Customer"},
+		"scope":   "users:read users:write",
+		"user_id": json.Number("1001"),
+	})
+
+	forbidden := events.APIGatewayProxyResponse{
+		StatusCode: http.StatusForbidden,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       `{"error": "forbidden"}`,
+	}
 
 	tests := map[string]struct {
 		mockCalled       bool
-		mockSetup        func()
+		mockSetup        func(mockService *serviceMock.MockUserService, ctx context.Context)
+		keysSetup        func(mockKeys *serviceMock.MockApiKeyService, ctx context.Context)
+		ctx              context.Context
 		request          events.APIGatewayProxyRequest
 		expectedResponse events.APIGatewayProxyResponse
 		expectedError    error
 	}{
 		"GET list users": {
 			mockCalled: true,
-			mockSetup: func() {
+			mockSetup: func(mockService *serviceMock.MockUserService, ctx context.Context) {
 				mockService.
 					On("ListUsers", ctx).
 					Return(users, nil).
 					Once()
 			},
+			ctx: employee,
 			request: events.APIGatewayProxyRequest{
 				HTTPMethod: http.MethodGet,
+				Resource:   "/lambda/user",
 			},
 			expectedResponse: events.APIGatewayProxyResponse{
 				StatusCode: http.StatusOK,
@@ -54,16 +80,88 @@ func TestAPI(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		"GET list users as customer": {
+			mockCalled: false,
+			ctx:        customer,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodGet,
+				Resource:   "/lambda/user",
+			},
+			expectedResponse: forbidden,
+			expectedError:    nil,
+		},
+		"GET user as employee": {
+			mockCalled: true,
+			mockSetup: func(mockService *serviceMock.MockUserService, ctx context.Context) {
+				mockService.
+					On("GetUser", ctx, 2).
+					Return(users[2], nil).
+					Once()
+			},
+			ctx: employee,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodGet,
+				Resource:       "/lambda/user/{ID}",
+				PathParameters: map[string]string{"ID": "2"},
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseUser{User: usersOut[2]}),
+			},
+			expectedError: nil,
+		},
+		"GET own user as customer": {
+			mockCalled: true,
+			mockSetup: func(mockService *serviceMock.MockUserService, ctx context.Context) {
+				// once for the ownership check, once for the handler
+				mockService.
+					On("GetUser", ctx, 1).
+					Return(users[1], nil).
+					Twice()
+			},
+			ctx: customer,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodGet,
+				Resource:       "/lambda/user/{ID}",
+				PathParameters: map[string]string{"ID": "1"},
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseUser{User: usersOut[1]}),
+			},
+			expectedError: nil,
+		},
+		"GET other user as customer": {
+			mockCalled: true,
+			mockSetup: func(mockService *serviceMock.MockUserService, ctx context.Context) {
+				mockService.
+					On("GetUser", ctx, 2).
+					Return(users[2], nil).
+					Once()
+			},
+			ctx: customer,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodGet,
+				Resource:       "/lambda/user/{ID}",
+				PathParameters: map[string]string{"ID": "2"},
+			},
+			expectedResponse: forbidden,
+			expectedError:    nil,
+		},
 		"PUT update user": {
 			mockCalled: true,
-			mockSetup: func() {
+			mockSetup: func(mockService *serviceMock.MockUserService, ctx context.Context) {
 				mockService.
 					On("UpdateUser", ctx, 1, users[0]).
 					Return(users[1], nil).
 					Once()
 			},
+			ctx: employee,
 			request: events.APIGatewayProxyRequest{
 				HTTPMethod:     http.MethodPut,
+				Resource:       "/lambda/user/{ID}",
 				PathParameters: map[string]string{"ID": "1"},
 				Body:           testutil.ToJSONString(userIn),
 			},
@@ -74,11 +172,99 @@ func TestAPI(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		"PUT update own user as customer": {
+			mockCalled: false,
+			ctx:        customer,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod:     http.MethodPut,
+				Resource:       "/lambda/user/{ID}",
+				PathParameters: map[string]string{"ID": "1"},
+				Body:           testutil.ToJSONString(userIn),
+			},
+			expectedResponse: forbidden,
+			expectedError:    nil,
+		},
+		"no claims": {
+			mockCalled: false,
+			ctx:        context.Background(),
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodGet,
+				Resource:   "/lambda/user",
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnauthorized,
+				Headers: map[string]string{
+					"Content-Type":     "application/json",
+					"WWW-Authenticate": `Bearer`,
+				},
+				Body: `{"error": "missing bearer token"}`,
+			},
+			expectedError: nil,
+		},
+		"GET list api keys as admin": {
+			mockCalled: false,
+			keysSetup: func(mockKeys *serviceMock.MockApiKeyService, ctx context.Context) {
+				mockKeys.
+					On("ListAPIKeys", ctx).
+					Return([]models.APIKey{}, nil).
+					Once()
+			},
+			ctx: admin,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodGet,
+				Resource:   "/lambda/admin/api-keys",
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseAPIKeys{APIKeys: []outputAPIKey{}}),
+			},
+			expectedError: nil,
+		},
+		"GET list api keys as employee": {
+			mockCalled: false,
+			ctx:        employee,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodGet,
+				Resource:   "/lambda/admin/api-keys",
+			},
+			expectedResponse: forbidden,
+			expectedError:    nil,
+		},
+		"OPTIONS lists resource methods": {
+			mockCalled: false,
+			ctx:        context.Background(),
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodOptions,
+				Resource:   "/lambda/user/{ID}",
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNoContent,
+				Headers:    map[string]string{"Allow": "GET, PUT, OPTIONS"},
+			},
+			expectedError: nil,
+		},
+		"OPTIONS unknown resource": {
+			mockCalled: false,
+			ctx:        context.Background(),
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodOptions,
+				Resource:   "/lambda/unknown",
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       http.StatusText(http.StatusNotFound),
+			},
+			expectedError: nil,
+		},
 		"POST method not found": {
 			mockCalled: false,
 			mockSetup:  nil,
+			ctx:        employee,
 			request: events.APIGatewayProxyRequest{
 				HTTPMethod: http.MethodPost,
+				Resource:   "/lambda/user",
 			},
 			expectedResponse: events.APIGatewayProxyResponse{
 				StatusCode: http.StatusNotFound,
@@ -91,11 +277,18 @@ func TestAPI(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
+			mockService := new(serviceMock.MockUserService)
+			mockKeys := new(serviceMock.MockApiKeyService)
+			handler := API(mockService, mockKeys)
+
 			if tc.mockCalled {
-				tc.mockSetup()
+				tc.mockSetup(mockService, tc.ctx)
+			}
+			if tc.keysSetup != nil {
+				tc.keysSetup(mockKeys, tc.ctx)
 			}
 
-			got, err := handler(ctx, tc.request)
+			got, err := handler(tc.ctx, tc.request)
 
 			assert.Equal(t, tc.expectedError, err, "Error expectations not met")
 			assert.Equal(t, tc.expectedResponse, got, "Wrong response body")
@@ -104,6 +297,12 @@ func TestAPI(t *testing.T) {
 				mockService.AssertExpectations(t)
 			} else {
 				mockService.AssertNotCalled(t, "ListUsers")
+				mockService.AssertNotCalled(t, "UpdateUser")
+			}
+			if tc.keysSetup != nil {
+				mockKeys.AssertExpectations(t)
+			} else {
+				mockKeys.AssertNotCalled(t, "ListAPIKeys")
 			}
 		})
 	}
@@ -8,3 +8,7 @@ import (
 
 // HandlerFunc is an alias for a lambda function that is used with API Gateway.
 type HandlerFunc = func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// AuthorizerFunc is an alias for a lambda function that is used as an API Gateway REQUEST
+// authorizer.
+type AuthorizerFunc = func(ctx context.Context, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error)
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
+)
+
+type apiKeyLister interface {
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+}
+
+// HandleListAPIKeys returns a HandlerFunc that handles GET requests for all API keys. It gets the
+// keys, including revoked and expired ones, from the database and returns them without their
+// secrets in the response.
+func HandleListAPIKeys(service apiKeyLister) HandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		logger := logging.FromContext(ctx)
+		// get values from database
+		keys, err := service.ListAPIKeys(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "error getting all api keys", "error", err)
+			return encodeResponse(logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+		}
+
+		// return response
+		return encodeResponse(logger, http.StatusOK, responseAPIKeys{
+			APIKeys: mapMultipleAPIKeyOutput(keys),
+		})
+	}
+}
@@ -2,10 +2,10 @@ package handlers
 
 import (
 	"context"
-	"log/slog"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
 )
 
@@ -15,12 +15,13 @@ type userLister interface {
 
 // HandleListUsers returns a HandlerFunc that handles GET requests to list users. It retrieves the
 // list of users from the provided service and returns them in the response.
-func HandleListUsers(logger *slog.Logger, service userLister) HandlerFunc {
+func HandleListUsers(service userLister) HandlerFunc {
 	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		logger := logging.FromContext(ctx)
 		// get values from database
 		users, err := service.ListUsers(ctx)
 		if err != nil {
-			logger.Error("error getting all locations", "err", err)
+			logger.ErrorContext(ctx, "error getting all locations", "err", err)
 			return encodeResponse(logger, http.StatusInternalServerError, responseErr{
 				Error: "Error retrieving data",
 			})
@@ -23,6 +23,63 @@ func (_m *MockUserService) EXPECT() *MockUserService_Expecter {
 	return &MockUserService_Expecter{mock: &_m.Mock}
 }
 
+// GetUser provides a mock function with given fields: ctx, ID
+func (_m *MockUserService) GetUser(ctx context.Context, ID int) (models.User, error) {
+	ret := _m.Called(ctx, ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.User, error)); ok {
+		return rf(ctx, ID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.User); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserService_GetUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUser'
+type MockUserService_GetUser_Call struct {
+	*mock.Call
+}
+
+// GetUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID int
+func (_e *MockUserService_Expecter) GetUser(ctx interface{}, ID interface{}) *MockUserService_GetUser_Call {
+	return &MockUserService_GetUser_Call{Call: _e.mock.On("GetUser", ctx, ID)}
+}
+
+func (_c *MockUserService_GetUser_Call) Run(run func(ctx context.Context, ID int)) *MockUserService_GetUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserService_GetUser_Call) Return(_a0 models.User, _a1 error) *MockUserService_GetUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserService_GetUser_Call) RunAndReturn(run func(context.Context, int) (models.User, error)) *MockUserService_GetUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListUsers provides a mock function with given fields: ctx
 func (_m *MockUserService) ListUsers(ctx context.Context) ([]models.User, error) {
 	ret := _m.Called(ctx)
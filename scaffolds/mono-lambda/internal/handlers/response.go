@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"log/slog"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
@@ -10,8 +11,8 @@ import (
 
 type outputUser struct {
 	ID        int    `json:"id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	FirstName string `json:"first_name" log:"sensitive"`
+	LastName  string `json:"last_name" log:"sensitive"`
 	Role      string `json:"role"`
 	UserID    int    `json:"user_id"`
 }
@@ -46,6 +47,61 @@ type responseUsers struct {
 	Users []outputUser `json:"users"`
 }
 
+type outputAPIKey struct {
+	ID         int        `json:"id"`
+	Prefix     string     `json:"prefix"`
+	Owner      string     `json:"owner"`
+	Roles      []string   `json:"roles"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// mapAPIKeyOutput maps a models.APIKey struct to an outputAPIKey struct.
+func mapAPIKeyOutput(key models.APIKey) outputAPIKey {
+	return outputAPIKey{
+		ID:         int(key.ID),
+		Prefix:     key.Prefix,
+		Owner:      key.Owner,
+		Roles:      key.Roles,
+		Scopes:     key.Scopes,
+		ExpiresAt:  optionalTime(key.ExpiresAt),
+		LastUsedAt: optionalTime(key.LastUsedAt),
+		RevokedAt:  optionalTime(key.RevokedAt),
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// mapMultipleAPIKeyOutput maps a slice of []models.APIKey to a slice of []outputAPIKey.
+func mapMultipleAPIKeyOutput(keys []models.APIKey) []outputAPIKey {
+	keysOut := make([]outputAPIKey, len(keys))
+	for i := 0; i < len(keys); i++ {
+		keysOut[i] = mapAPIKeyOutput(keys[i])
+	}
+
+	return keysOut
+}
+
+// optionalTime maps the zero time to nil.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+type responseCreatedAPIKey struct {
+	APIKey outputAPIKey `json:"api_key"`
+	// Key is the plain API key. It is only returned once and cannot be recovered.
+	Key string `json:"key"`
+}
+
+type responseAPIKeys struct {
+	APIKeys []outputAPIKey `json:"api_keys"`
+}
+
 type responseMsg struct {
 	Message string `json:"message"`
 }
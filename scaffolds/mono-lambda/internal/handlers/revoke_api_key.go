@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/services"
+)
+
+type apiKeyRevoker interface {
+	RevokeAPIKey(ctx context.Context, ID int) error
+}
+
+// HandleRevokeAPIKey returns a HandlerFunc that handles DELETE requests to revoke an API key. It
+// retrieves the key ID from the path parameters and revokes the key in the database. Cached
+// validations of the key expire within the API key cache TTL.
+func HandleRevokeAPIKey(service apiKeyRevoker) HandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		logger := logging.FromContext(ctx)
+		// get and validate ID
+		idString := request.PathParameters["ID"]
+		ID, err := strconv.Atoi(idString)
+		if err != nil {
+			logger.ErrorContext(ctx, "error getting ID", "error", err)
+			return encodeResponse(logger, http.StatusBadRequest, responseErr{
+				Error: "Not a valid ID",
+			})
+		}
+
+		// revoke object in database
+		if err := service.RevokeAPIKey(ctx, ID); err != nil {
+			if errors.Is(err, services.ErrNotFound) {
+				return encodeResponse(logger, http.StatusNotFound, responseErr{
+					Error: "API key not found",
+				})
+			}
+
+			logger.ErrorContext(ctx, "error revoking object in database", "error", err)
+			return encodeResponse(logger, http.StatusInternalServerError, responseErr{
+				Error: "Error revoking object",
+			})
+		}
+
+		// return response
+		return encodeResponse(logger, http.StatusOK, responseMsg{
+			Message: "API key revoked",
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	serviceMock "github.com/captechconsulting/go-microservice-templates/lambda/internal/handlers/mock"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/services"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleRevokeAPIKey(t *testing.T) {
+	mockService := new(serviceMock.MockApiKeyRevoker)
+	handler := HandleRevokeAPIKey(mockService)
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		mockCalled       bool
+		mockOutput       error
+		request          events.APIGatewayProxyRequest
+		expectedResponse events.APIGatewayProxyResponse
+		expectedError    error
+	}{
+		"valid request, api key revoked": {
+			mockCalled: true,
+			mockOutput: nil,
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ID": "1"},
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseMsg{Message: "API key revoked"}),
+			},
+			expectedError: nil,
+		},
+		"invalid ID": {
+			mockCalled: false,
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ID": "test"},
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseErr{Error: "Not a valid ID"}),
+			},
+			expectedError: nil,
+		},
+		"api key not found": {
+			mockCalled: true,
+			mockOutput: fmt.Errorf("[in services.RevokeAPIKey] api key 1: %w", services.ErrNotFound),
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ID": "1"},
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseErr{Error: "API key not found"}),
+			},
+			expectedError: nil,
+		},
+		"error revoking api key": {
+			mockCalled: true,
+			mockOutput: errors.New("test"),
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ID": "1"},
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseErr{Error: "Error revoking object"}),
+			},
+			expectedError: nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.mockCalled {
+				mockService.
+					On("RevokeAPIKey", ctx, 1).
+					Return(tc.mockOutput).
+					Once()
+			}
+
+			got, err := handler(ctx, tc.request)
+
+			assert.Equal(t, tc.expectedError, err, "Error expectations not met")
+			assert.Equal(t, tc.expectedResponse, got, "Wrong response body")
+
+			if tc.mockCalled {
+				mockService.AssertExpectations(t)
+			} else {
+				mockService.AssertNotCalled(t, "RevokeAPIKey")
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+)
+
+type tokenVerifier interface {
+	Verify(ctx context.Context, token string) (auth.Claims, error)
+}
+
+// Authenticate requires a valid bearer token on every request that has not been authenticated
+// already, e.g. by APIKey. The verified claims are stored in the request context for handlers, see
+// auth.ClaimsFromContext. Requests with a missing or invalid token are rejected with a 401.
+func Authenticate(verifier tokenVerifier) LambdaMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			logger := logging.FromContext(ctx)
+			if _, ok := auth.PrincipalFromContext(ctx); ok {
+				return next(ctx, request)
+			}
+
+			token, ok := auth.BearerToken(header(request, "Authorization"))
+			if !ok {
+				logger.WarnContext(ctx, "Request rejected, missing bearer token", "method", request.HTTPMethod, "path", request.Path)
+				return events.APIGatewayProxyResponse{
+					Headers: map[string]string{
+						"Content-Type":     "application/json",
+						"WWW-Authenticate": `Bearer`,
+					},
+					StatusCode: http.StatusUnauthorized,
+					Body:       `{"error": "missing bearer token"}`,
+				}, nil
+			}
+
+			claims, err := verifier.Verify(ctx, token)
+			if err != nil {
+				logger.WarnContext(ctx, "Request rejected, invalid bearer token", "err", err, "method", request.HTTPMethod, "path", request.Path)
+				return events.APIGatewayProxyResponse{
+					Headers: map[string]string{
+						"Content-Type":     "application/json",
+						"WWW-Authenticate": `Bearer error="invalid_token"`,
+					},
+					StatusCode: http.StatusUnauthorized,
+					Body:       `{"error": "invalid bearer token"}`,
+				}, nil
+			}
+
+			return next(auth.WithClaims(ctx, claims), request)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/tenant"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	issuer := testutil.NewJWTIssuer(t)
+	verifier := auth.NewVerifier(auth.NewJWKS(issuer.JWKSURL(), time.Hour), "https://issuer.test", "users-api", 0)
+
+	validClaims := map[string]any{
+		"sub":       "user-1",
+		"iss":       "https://issuer.test",
+		"aud":       "users-api",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"tenant_id": "tenant-a",
+	}
+	expiredClaims := map[string]any{
+		"sub": "user-1",
+		"iss": "https://issuer.test",
+		"aud": "users-api",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	}
+
+	tests := map[string]struct {
+		headers          map[string]string
+		expectedSubject  string
+		expectedTenant   string
+		expectedResponse events.APIGatewayProxyResponse
+	}{
+		"valid token": {
+			headers:          map[string]string{"Authorization": "Bearer " + issuer.Sign("RS256", "", validClaims)},
+			expectedSubject:  "user-1",
+			expectedTenant:   "tenant-a",
+			expectedResponse: events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		"lowercase header": {
+			headers:          map[string]string{"authorization": "Bearer " + issuer.Sign("ES256", "", validClaims)},
+			expectedSubject:  "user-1",
+			expectedTenant:   "tenant-a",
+			expectedResponse: events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		"missing token": {
+			headers: map[string]string{},
+			expectedResponse: events.APIGatewayProxyResponse{
+				Headers: map[string]string{
+					"Content-Type":     "application/json",
+					"WWW-Authenticate": `Bearer`,
+				},
+				StatusCode: http.StatusUnauthorized,
+				Body:       `{"error": "missing bearer token"}`,
+			},
+		},
+		"expired token": {
+			headers: map[string]string{"Authorization": "Bearer " + issuer.Sign("RS256", "", expiredClaims)},
+			expectedResponse: events.APIGatewayProxyResponse{
+				Headers: map[string]string{
+					"Content-Type":     "application/json",
+					"WWW-Authenticate": `Bearer error="invalid_token"`,
+				},
+				StatusCode: http.StatusUnauthorized,
+				Body:       `{"error": "invalid bearer token"}`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotSubject, gotTenant string
+			handler := func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				claims, _ := auth.ClaimsFromContext(ctx)
+				gotSubject = claims.Subject
+				gotTenant, _ = tenant.FromContext(ctx)
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+			}
+			combined := AddToHandler(
+				handler,
+				Authenticate(verifier),
+				Tenant(TenantFromClaim("tenant_id")),
+			)
+
+			resp, err := combined(context.Background(), events.APIGatewayProxyRequest{Headers: tt.headers})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSubject, gotSubject)
+			assert.Equal(t, tt.expectedTenant, gotTenant)
+			assert.Equal(t, tt.expectedResponse, resp)
+		})
+	}
+}
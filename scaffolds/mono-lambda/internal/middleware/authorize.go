@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+)
+
+// Authorize rejects requests whose caller does not satisfy policy. It must run after Authenticate
+// or Principal. Requests without a caller are rejected with a 401 and callers that do not satisfy
+// the policy with a 403. If the policy's owner lookup fails, a 500 is returned.
+func Authorize(policy auth.Policy) LambdaMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			logger := logging.FromContext(ctx)
+			var resourceID string
+			if policy.OwnerParam != "" {
+				resourceID = request.PathParameters[policy.OwnerParam]
+			}
+
+			err := policy.Authorize(ctx, resourceID)
+			switch {
+			case err == nil:
+				return next(ctx, request)
+			case errors.Is(err, auth.ErrUnauthenticated):
+				logger.WarnContext(ctx, "Request rejected, unauthenticated", "method", request.HTTPMethod, "path", request.Path)
+				return events.APIGatewayProxyResponse{
+					Headers: map[string]string{
+						"Content-Type":     "application/json",
+						"WWW-Authenticate": `Bearer`,
+					},
+					StatusCode: http.StatusUnauthorized,
+					Body:       `{"error": "missing bearer token"}`,
+				}, nil
+			case errors.Is(err, auth.ErrForbidden):
+				logger.WarnContext(ctx, "Request rejected, forbidden", "err", err, "method", request.HTTPMethod, "path", request.Path)
+				return events.APIGatewayProxyResponse{
+					Headers:    map[string]string{"Content-Type": "application/json"},
+					StatusCode: http.StatusForbidden,
+					Body:       `{"error": "forbidden"}`,
+				}, nil
+			default:
+				logger.ErrorContext(ctx, "Error authorizing request", "err", err, "method", request.HTTPMethod, "path", request.Path)
+				return events.APIGatewayProxyResponse{
+					Headers:    map[string]string{"Content-Type": "application/json"},
+					StatusCode: http.StatusInternalServerError,
+					Body:       `{"error": "internal server error"}`,
+				}, nil
+			}
+		}
+	}
+}
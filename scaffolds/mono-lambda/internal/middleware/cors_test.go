@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	policy := CORSPolicy{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "PUT"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"RateLimit-Limit"},
+		AllowCredentials: true,
+		MaxAge:           5 * time.Minute,
+	}
+
+	tests := map[string]struct {
+		policy           CORSPolicy
+		request          events.APIGatewayProxyRequest
+		nextVary         string
+		expectedNext     bool
+		expectedResponse events.APIGatewayProxyResponse
+	}{
+		"preflight from allowed origin": {
+			policy: policy,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodOptions,
+				Headers: map[string]string{
+					"origin":                        "https://app.example.com",
+					"access-control-request-method": "PUT",
+				},
+			},
+			expectedNext: false,
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNoContent,
+				Headers: map[string]string{
+					"Vary":                             "Origin, Access-Control-Request-Method, Access-Control-Request-Headers",
+					"Access-Control-Allow-Origin":      "https://app.example.com",
+					"Access-Control-Allow-Methods":     "GET, PUT",
+					"Access-Control-Allow-Headers":     "Authorization, Content-Type",
+					"Access-Control-Allow-Credentials": "true",
+					"Access-Control-Max-Age":           "300",
+				},
+			},
+		},
+		"preflight from unknown origin": {
+			policy: policy,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodOptions,
+				Headers: map[string]string{
+					"Origin":                        "https://evil.example.com",
+					"Access-Control-Request-Method": "PUT",
+				},
+			},
+			expectedNext: false,
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNoContent,
+				Headers:    map[string]string{"Vary": "Origin, Access-Control-Request-Method, Access-Control-Request-Headers"},
+			},
+		},
+		"preflight for disallowed method": {
+			policy: policy,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodOptions,
+				Headers: map[string]string{
+					"Origin":                        "https://app.example.com",
+					"Access-Control-Request-Method": "DELETE",
+				},
+			},
+			expectedNext: false,
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNoContent,
+				Headers:    map[string]string{"Vary": "Origin, Access-Control-Request-Method, Access-Control-Request-Headers"},
+			},
+		},
+		"request from allowed origin": {
+			policy: policy,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodGet,
+				Headers:    map[string]string{"Origin": "https://app.example.com"},
+			},
+			expectedNext: true,
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type":                     "application/json",
+					"Vary":                             "Origin",
+					"Access-Control-Allow-Origin":      "https://app.example.com",
+					"Access-Control-Expose-Headers":    "RateLimit-Limit",
+					"Access-Control-Allow-Credentials": "true",
+				},
+			},
+		},
+		"any origin": {
+			policy: CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}},
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodGet,
+				Headers:    map[string]string{"Origin": "https://other.example.com"},
+			},
+			expectedNext: true,
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type":                "application/json",
+					"Vary":                        "Origin",
+					"Access-Control-Allow-Origin": "*",
+				},
+			},
+		},
+		"any origin never allows credentials": {
+			policy: CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, AllowCredentials: true},
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodOptions,
+				Headers: map[string]string{
+					"Origin":                        "https://other.example.com",
+					"Access-Control-Request-Method": "GET",
+				},
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNoContent,
+				Headers: map[string]string{
+					"Vary":                         "Origin, Access-Control-Request-Method, Access-Control-Request-Headers",
+					"Access-Control-Allow-Origin":  "*",
+					"Access-Control-Allow-Methods": "GET",
+				},
+			},
+		},
+		"vary of the response is kept": {
+			policy: policy,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodGet,
+				Headers:    map[string]string{"Origin": "https://app.example.com"},
+			},
+			nextVary:     "Accept-Encoding",
+			expectedNext: true,
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type":                     "application/json",
+					"Vary":                             "Accept-Encoding, Origin",
+					"Access-Control-Allow-Origin":      "https://app.example.com",
+					"Access-Control-Expose-Headers":    "RateLimit-Limit",
+					"Access-Control-Allow-Credentials": "true",
+				},
+			},
+		},
+		"same origin request": {
+			policy: policy,
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodGet,
+			},
+			expectedNext: true,
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "application/json", "Vary": "Origin"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var called bool
+			handler := func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				called = true
+				headers := map[string]string{"Content-Type": "application/json"}
+				if tt.nextVary != "" {
+					headers["Vary"] = tt.nextVary
+				}
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Headers: headers}, nil
+			}
+
+			response, err := CORS(tt.policy)(handler)(context.Background(), tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedNext, called)
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+)
+
+// DebugLogging writes records of every level for invocations flagged by enabled, see
+// logging.WithDebug, so a single request can be debugged without redeploying the function with a
+// lower LOG_LEVEL.
+func DebugLogging[E any, R any](enabled func(ctx context.Context, event E) bool) LambdaMiddlewareT[E, R] {
+	return func(next HandlerFuncT[E, R]) HandlerFuncT[E, R] {
+		return func(ctx context.Context, event E) (R, error) {
+			if enabled(ctx, event) {
+				logging.FromContext(ctx).InfoContext(ctx, "Debug logging enabled for invocation")
+				ctx = logging.WithDebug(ctx)
+			}
+			return next(ctx, event)
+		}
+	}
+}
+
+// DebugBodies logs the body of every request and response at debug level under `body`, so the
+// redacting handler masks their sensitive fields before they are written. Bodies are only logged
+// while the request logger writes debug records, e.g. for invocations flagged by DebugLogging, so
+// it must run after it.
+func DebugBodies() LambdaMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			logger := logging.FromContext(ctx)
+			if !logger.Enabled(ctx, slog.LevelDebug) {
+				return next(ctx, request)
+			}
+
+			logger.DebugContext(ctx, "Request body", "method", request.HTTPMethod, "path", request.Path, "body", request.Body)
+			response, err := next(ctx, request)
+			logger.DebugContext(ctx, "Response body", "status", response.StatusCode, "body", response.Body)
+
+			return response, err
+		}
+	}
+}
+
+// DebugHeader flags requests whose named header is set to a true value, e.g. `true` or `1`, and
+// whose caller satisfies policy, so only trusted callers can raise the log volume. It must run
+// after Authenticate or Principal.
+func DebugHeader(name string, policy auth.Policy) func(ctx context.Context, request events.APIGatewayProxyRequest) bool {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) bool {
+		enabled, err := strconv.ParseBool(header(request, name))
+		if err != nil || !enabled {
+			return false
+		}
+		return policy.Authorize(ctx, "") == nil
+	}
+}
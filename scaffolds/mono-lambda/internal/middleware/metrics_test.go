@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	tests := map[string]struct {
+		handler     HandlerFunc
+		recover     bool
+		expectPanic bool
+		expected    map[string]any
+	}{
+		"ok response": {
+			handler: func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				metrics.Put(ctx, "UsersUpdated", 1, metrics.Count)
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+			},
+			expected: map[string]any{
+				"Invocations": 1.0, "Errors": 0.0, "Panics": 0.0, "UsersUpdated": 1.0,
+				"Status2xx": 1.0, "Status3xx": 0.0, "Status4xx": 0.0, "Status5xx": 0.0,
+			},
+		},
+		"client error": {
+			handler: func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusNotFound}, nil
+			},
+			expected: map[string]any{
+				"Invocations": 1.0, "Errors": 0.0, "Panics": 0.0,
+				"Status2xx": 0.0, "Status3xx": 0.0, "Status4xx": 1.0, "Status5xx": 0.0,
+			},
+		},
+		"handler error": {
+			handler: func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return events.APIGatewayProxyResponse{}, errors.New("test")
+			},
+			expected: map[string]any{
+				"Invocations": 1.0, "Errors": 1.0, "Panics": 0.0,
+				"Status2xx": 0.0, "Status3xx": 0.0, "Status4xx": 0.0, "Status5xx": 0.0,
+			},
+		},
+		"recovered panic": {
+			handler: func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				panic("test")
+			},
+			recover: true,
+			expected: map[string]any{
+				"Invocations": 1.0, "Errors": 0.0, "Panics": 1.0,
+				"Status2xx": 0.0, "Status3xx": 0.0, "Status4xx": 0.0, "Status5xx": 1.0,
+			},
+		},
+		"unrecovered panic": {
+			handler: func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				panic("test")
+			},
+			expectPanic: true,
+			expected: map[string]any{
+				"Invocations": 1.0, "Errors": 0.0, "Panics": 1.0,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			emitter := metrics.NewEmitter(&out, "Users", map[string]string{"FunctionName": "api"})
+
+			middlewares := []LambdaMiddleware{Metrics(emitter, StatusClasses)}
+			if tc.recover {
+				middlewares = append(middlewares, Recovery(&recordingReporter{}))
+			}
+			handler := AddToHandler(tc.handler, middlewares...)
+
+			invoke := func() { _, _ = handler(context.Background(), events.APIGatewayProxyRequest{}) }
+			if tc.expectPanic {
+				assert.Panics(t, invoke)
+			} else {
+				assert.NotPanics(t, invoke)
+			}
+
+			var line map[string]any
+			if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+				t.Fatalf("decoding metrics %q: %v", out.String(), err)
+			}
+			for metric, expected := range tc.expected {
+				assert.Equal(t, expected, line[metric], metric)
+			}
+			assert.Equal(t, 1.0, line["ColdStart"])
+			assert.Contains(t, line, "Duration")
+			assert.Equal(t, "api", line["FunctionName"])
+		})
+	}
+}
+
+func TestMetricsColdStart(t *testing.T) {
+	var out bytes.Buffer
+	emitter := metrics.NewEmitter(&out, "Users", nil)
+	handler := Metrics[events.SQSEvent, string](emitter, nil)(func(ctx context.Context, _ events.SQSEvent) (string, error) {
+		return "", nil
+	})
+
+	var coldStarts []any
+	for range 3 {
+		out.Reset()
+		_, _ = handler(context.Background(), events.SQSEvent{})
+
+		var line map[string]any
+		if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+			t.Fatalf("decoding metrics %q: %v", out.String(), err)
+		}
+		coldStarts = append(coldStarts, line["ColdStart"])
+		assert.NotContains(t, line, "Status2xx")
+	}
+
+	assert.Equal(t, []any{1.0, 0.0, 0.0}, coldStarts)
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipal(t *testing.T) {
+	tests := map[string]struct {
+		authorizer        map[string]any
+		expectedPrincipal auth.Principal
+		expectedTenant    string
+		expectedResponse  events.APIGatewayProxyResponse
+	}{
+		"authorizer context": {
+			authorizer: map[string]any{
+				"principalId": "user-1",
+				"subject":     "user-1",
+				"user_id":     "1001",
+				"tenant":      "tenant-a",
+				"roles":       "Customer",
+				"scopes":      "users:read",
+				"method":      auth.MethodBearer,
+			},
+			expectedPrincipal: auth.Principal{
+				Subject: "user-1",
+				UserID:  "1001",
+				Tenant:  "tenant-a",
+				Roles:   []string{"Customer"},
+				Scopes:  []string{"users:read"},
+				Method:  auth.MethodBearer,
+			},
+			expectedTenant:   "tenant-a",
+			expectedResponse: events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		"no authorizer context": {
+			authorizer: nil,
+			expectedResponse: events.APIGatewayProxyResponse{
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				StatusCode: http.StatusUnauthorized,
+				Body:       `{"error": "unauthenticated"}`,
+			},
+		},
+		"authorizer context without tenant": {
+			authorizer: map[string]any{"subject": "svc-reporting"},
+			expectedPrincipal: auth.Principal{
+				Subject: "svc-reporting",
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				StatusCode: http.StatusBadRequest,
+				Body:       `{"error": "missing tenant"}`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotPrincipal auth.Principal
+			var gotTenant string
+			handler := func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				gotPrincipal, _ = auth.PrincipalFromContext(ctx)
+				gotTenant, _ = tenant.FromContext(ctx)
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+			}
+			combined := AddToHandler(
+				handler,
+				Principal(),
+				Tenant(TenantFromPrincipal()),
+			)
+
+			resp, err := combined(context.Background(), events.APIGatewayProxyRequest{
+				RequestContext: events.APIGatewayProxyRequestContext{Authorizer: tt.authorizer},
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResponse, resp)
+			if resp.StatusCode == http.StatusOK {
+				assert.Equal(t, tt.expectedPrincipal, gotPrincipal)
+				assert.Equal(t, tt.expectedTenant, gotTenant)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(_ context.Context, _ string, _ ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	limits := ratelimit.Limits{
+		Default: ratelimit.Limit{Requests: 5, Period: time.Minute},
+		Routes: map[string]ratelimit.Limit{
+			"GET /lambda/user/{ID}": {Requests: 1, Period: time.Minute},
+		},
+	}
+
+	type call struct {
+		resource     string
+		sourceIP     string
+		principal    *auth.Principal
+		expectedCode int
+		expectedBody string
+		expectedHdrs map[string]string
+	}
+
+	tests := map[string]struct {
+		store ratelimit.Store
+		calls []call
+	}{
+		"route limit per source ip": {
+			store: ratelimit.NewMemoryStore(),
+			calls: []call{
+				{
+					resource:     "/lambda/user/{ID}",
+					sourceIP:     "10.0.0.1",
+					expectedCode: http.StatusOK,
+					expectedHdrs: map[string]string{"RateLimit-Limit": "1", "RateLimit-Remaining": "0", "RateLimit-Reset": "60"},
+				},
+				{
+					resource:     "/lambda/user/{ID}",
+					sourceIP:     "10.0.0.1",
+					expectedCode: http.StatusTooManyRequests,
+					expectedBody: `{"type":"about:blank","title":"Too Many Requests","status":429,"detail":"rate limit exceeded, retry after 60s"}`,
+					expectedHdrs: map[string]string{"RateLimit-Remaining": "0", "Retry-After": "60", "Content-Type": "application/problem+json"},
+				},
+				{
+					resource:     "/lambda/user/{ID}",
+					sourceIP:     "10.0.0.2",
+					expectedCode: http.StatusOK,
+				},
+				{
+					resource:     "/lambda/user",
+					sourceIP:     "10.0.0.1",
+					expectedCode: http.StatusOK,
+					expectedHdrs: map[string]string{"RateLimit-Limit": "5", "RateLimit-Remaining": "4"},
+				},
+			},
+		},
+		"authenticated clients limited by principal": {
+			store: ratelimit.NewMemoryStore(),
+			calls: []call{
+				{
+					resource:     "/lambda/user/{ID}",
+					sourceIP:     "10.0.0.1",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey, KeyPrefix: "0a1b2c3d"},
+					expectedCode: http.StatusOK,
+				},
+				{
+					resource:     "/lambda/user/{ID}",
+					sourceIP:     "10.0.0.2",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey, KeyPrefix: "0a1b2c3d"},
+					expectedCode: http.StatusTooManyRequests,
+				},
+				{
+					resource:     "/lambda/user/{ID}",
+					sourceIP:     "10.0.0.1",
+					principal:    &auth.Principal{Subject: "user-1", Method: auth.MethodBearer},
+					expectedCode: http.StatusOK,
+				},
+			},
+		},
+		"api keys limited each on their own": {
+			store: ratelimit.NewMemoryStore(),
+			calls: []call{
+				{
+					resource:     "/lambda/user/{ID}",
+					sourceIP:     "10.0.0.1",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey, KeyPrefix: "0a1b2c3d"},
+					expectedCode: http.StatusOK,
+				},
+				{
+					resource:     "/lambda/user/{ID}",
+					sourceIP:     "10.0.0.1",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey, KeyPrefix: "4e5f6a7b"},
+					expectedCode: http.StatusOK,
+				},
+				{
+					resource:     "/lambda/user/{ID}",
+					sourceIP:     "10.0.0.2",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-b", Method: auth.MethodAPIKey, KeyPrefix: "8c9d0e1f"},
+					expectedCode: http.StatusOK,
+				},
+				{
+					resource:     "/lambda/user/{ID}",
+					sourceIP:     "10.0.0.2",
+					principal:    &auth.Principal{Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey, KeyPrefix: "4e5f6a7b"},
+					expectedCode: http.StatusTooManyRequests,
+				},
+			},
+		},
+		"store failure lets requests through": {
+			store: failingStore{},
+			calls: []call{
+				{
+					resource:     "/lambda/user/{ID}",
+					sourceIP:     "10.0.0.1",
+					expectedCode: http.StatusOK,
+					expectedHdrs: map[string]string{"RateLimit-Limit": ""},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := RateLimit(tc.store, limits)(
+				func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+					return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+				},
+			)
+
+			for i, c := range tc.calls {
+				ctx := context.Background()
+				if c.principal != nil {
+					ctx = auth.WithPrincipal(ctx, *c.principal)
+				}
+				request := events.APIGatewayProxyRequest{
+					HTTPMethod: http.MethodGet,
+					Resource:   c.resource,
+					RequestContext: events.APIGatewayProxyRequestContext{
+						Identity: events.APIGatewayRequestIdentity{SourceIP: c.sourceIP},
+					},
+				}
+
+				response, err := handler(ctx, request)
+				assert.NoError(t, err)
+
+				assert.Equal(t, c.expectedCode, response.StatusCode, "call %d: wrong code received", i)
+				if c.expectedBody != "" {
+					assert.JSONEq(t, c.expectedBody, response.Body, "call %d: wrong response body", i)
+				}
+				for key, value := range c.expectedHdrs {
+					assert.Equal(t, value, response.Headers[key], "call %d: wrong %s header", i, key)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitIP(t *testing.T) {
+	// the API key validator is slow and costly, the IP limit must reject before it is called
+	var validated int
+	authenticate := func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			validated++
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusUnauthorized}, nil
+		}
+	}
+	handler := AddToHandler(
+		func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+		},
+		RateLimitIP(ratelimit.NewMemoryStore(), ratelimit.Limit{Requests: 2, Period: time.Minute}),
+		authenticate,
+	)
+
+	calls := []struct {
+		resource     string
+		sourceIP     string
+		expectedCode int
+	}{
+		{resource: "/lambda/user", sourceIP: "10.0.0.1", expectedCode: http.StatusUnauthorized},
+		{resource: "/lambda/user/{ID}", sourceIP: "10.0.0.1", expectedCode: http.StatusUnauthorized},
+		{resource: "/lambda/user/{ID}", sourceIP: "10.0.0.1", expectedCode: http.StatusTooManyRequests},
+		{resource: "/lambda/user", sourceIP: "10.0.0.2", expectedCode: http.StatusUnauthorized},
+	}
+
+	for i, c := range calls {
+		request := events.APIGatewayProxyRequest{
+			HTTPMethod: http.MethodGet,
+			Resource:   c.resource,
+			RequestContext: events.APIGatewayProxyRequestContext{
+				Identity: events.APIGatewayRequestIdentity{SourceIP: c.sourceIP},
+			},
+		}
+
+		response, err := handler(context.Background(), request)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expectedCode, response.StatusCode, "call %d: wrong code received", i)
+	}
+	assert.Equal(t, 3, validated, "limited requests must not be authenticated")
+}
+
+func TestRateLimitIPHeaders(t *testing.T) {
+	handler := AddToHandler(
+		func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+		},
+		RateLimitIP(ratelimit.NewMemoryStore(), ratelimit.Limit{Requests: 10, Period: time.Minute}),
+		RateLimit(ratelimit.NewMemoryStore(), ratelimit.Limits{Default: ratelimit.Limit{Requests: 5, Period: time.Minute}}),
+	)
+
+	response, err := handler(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Resource: "/lambda/user"})
+	assert.NoError(t, err)
+	assert.Equal(t, "5", response.Headers["RateLimit-Limit"], "route limit headers must take precedence")
+	assert.Equal(t, "4", response.Headers["RateLimit-Remaining"])
+}
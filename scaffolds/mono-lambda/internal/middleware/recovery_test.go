@@ -2,19 +2,28 @@ package middleware
 
 import (
 	"context"
-	"log/slog"
 	"net/http"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/reporting"
 	"github.com/stretchr/testify/assert"
 )
 
+// recordingReporter keeps the events reported to it.
+type recordingReporter struct {
+	events []reporting.Event
+}
+
+func (r *recordingReporter) Report(_ context.Context, event reporting.Event) {
+	r.events = append(r.events, event)
+}
+
 func TestRecovery(t *testing.T) {
 	tests := map[string]struct {
 		handler       HandlerFunc
 		expectPanic   bool
-		expectedLog   string
+		expectedErr   string
 		expectedEvent events.APIGatewayProxyResponse
 	}{
 		"handler does not panic": {
@@ -35,7 +44,7 @@ func TestRecovery(t *testing.T) {
 				panic("something went wrong")
 			},
 			expectPanic: true,
-			expectedLog: "Recovered from panic",
+			expectedErr: "panic: something went wrong",
 			expectedEvent: events.APIGatewayProxyResponse{
 				Headers:    map[string]string{"Content-Type": "application/json"},
 				StatusCode: http.StatusInternalServerError,
@@ -46,15 +55,71 @@ func TestRecovery(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			logger := slog.Default()
-
-			recoveryMiddleware := Recovery(logger)
+			reporter := &recordingReporter{}
+			recoveryMiddleware := Recovery(reporter)
 			handlerWithRecovery := recoveryMiddleware(tt.handler)
 
-			resp, err := handlerWithRecovery(context.Background(), events.APIGatewayProxyRequest{})
+			resp, err := handlerWithRecovery(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/lambda/user"})
 
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedEvent, resp)
+			if !tt.expectPanic {
+				assert.Empty(t, reporter.events)
+				return
+			}
+			if len(reporter.events) != 1 {
+				t.Fatalf("expected one reported event, got %d", len(reporter.events))
+			}
+			event := reporter.events[0]
+			assert.EqualError(t, event.Err, tt.expectedErr)
+			assert.Equal(t, map[string]string{"method": "GET", "path": "/lambda/user"}, event.Tags)
+			assert.Contains(t, reporting.FormatStack(event.Stack), "middleware.TestRecovery")
+		})
+	}
+}
+
+func TestAuthorizerRecovery(t *testing.T) {
+	tests := map[string]struct {
+		handler          HandlerFuncT[events.APIGatewayCustomAuthorizerRequestTypeRequest, events.APIGatewayCustomAuthorizerResponse]
+		expectPanic      bool
+		expectedErr      string
+		expectedResponse events.APIGatewayCustomAuthorizerResponse
+	}{
+		"handler does not panic": {
+			handler: func(ctx context.Context, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+				return events.APIGatewayCustomAuthorizerResponse{PrincipalID: "user-1"}, nil
+			},
+			expectedResponse: events.APIGatewayCustomAuthorizerResponse{PrincipalID: "user-1"},
+		},
+		"handler panics": {
+			handler: func(ctx context.Context, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+				panic("something went wrong")
+			},
+			expectPanic: true,
+			expectedErr: "Unauthorized",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			reporter := &recordingReporter{}
+			handlerWithRecovery := AuthorizerRecovery(reporter)(tt.handler)
+
+			resp, err := handlerWithRecovery(context.Background(), events.APIGatewayCustomAuthorizerRequestTypeRequest{HTTPMethod: "GET", Path: "/lambda/user"})
+
+			assert.Equal(t, tt.expectedResponse, resp)
+			if !tt.expectPanic {
+				assert.NoError(t, err)
+				assert.Empty(t, reporter.events)
+				return
+			}
+			assert.EqualError(t, err, tt.expectedErr)
+			if len(reporter.events) != 1 {
+				t.Fatalf("expected one reported event, got %d", len(reporter.events))
+			}
+			event := reporter.events[0]
+			assert.EqualError(t, event.Err, "panic: something went wrong")
+			assert.Equal(t, map[string]string{"method": "GET", "path": "/lambda/user"}, event.Tags)
 		})
 	}
 }
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// SecurityHeaders configures the response headers set by the Security middleware.
+type SecurityHeaders struct {
+	// HSTSMaxAge is the max-age of the Strict-Transport-Security header. HSTS is disabled if it
+	// is zero.
+	HSTSMaxAge time.Duration
+	// ContentSecurityPolicy is the Content-Security-Policy header. It is omitted if empty.
+	ContentSecurityPolicy string
+	// ReferrerPolicy is the Referrer-Policy header. It is omitted if empty.
+	ReferrerPolicy string
+}
+
+// Security sets the configured security headers, and `X-Content-Type-Options: nosniff`, on every
+// response. Headers the handler already set are kept.
+func Security(headers SecurityHeaders) LambdaMiddleware {
+	values := headers.values()
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			response, err := next(ctx, request)
+			if response.Headers == nil {
+				response.Headers = make(map[string]string, len(values))
+			}
+			for key, value := range values {
+				if _, ok := response.Headers[key]; !ok {
+					response.Headers[key] = value
+				}
+			}
+			return response, err
+		}
+	}
+}
+
+// values returns the headers to set on every response.
+func (h SecurityHeaders) values() map[string]string {
+	values := map[string]string{"X-Content-Type-Options": "nosniff"}
+	if h.HSTSMaxAge > 0 {
+		values["Strict-Transport-Security"] = "max-age=" + strconv.Itoa(int(h.HSTSMaxAge.Seconds())) + "; includeSubDomains"
+	}
+	if h.ContentSecurityPolicy != "" {
+		values["Content-Security-Policy"] = h.ContentSecurityPolicy
+	}
+	if h.ReferrerPolicy != "" {
+		values["Referrer-Policy"] = h.ReferrerPolicy
+	}
+	return values
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+	tests := map[string]struct {
+		sources          []TenantSource
+		request          events.APIGatewayProxyRequest
+		expectedTenant   string
+		expectedResponse events.APIGatewayProxyResponse
+	}{
+		"tenant from header": {
+			sources: []TenantSource{TenantFromHeader("X-Tenant-ID")},
+			request: events.APIGatewayProxyRequest{
+				Headers: map[string]string{"X-Tenant-ID": "tenant-a"},
+			},
+			expectedTenant:   "tenant-a",
+			expectedResponse: events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		"header matched case-insensitively": {
+			sources: []TenantSource{TenantFromHeader("X-Tenant-ID")},
+			request: events.APIGatewayProxyRequest{
+				Headers: map[string]string{"x-tenant-id": "tenant-a"},
+			},
+			expectedTenant:   "tenant-a",
+			expectedResponse: events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		"tenant from multi value header": {
+			sources: []TenantSource{TenantFromHeader("X-Tenant-ID")},
+			request: events.APIGatewayProxyRequest{
+				MultiValueHeaders: map[string][]string{"X-Tenant-Id": {"tenant-b"}},
+			},
+			expectedTenant:   "tenant-b",
+			expectedResponse: events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		"missing tenant rejected": {
+			sources:        []TenantSource{TenantFromHeader("X-Tenant-ID")},
+			request:        events.APIGatewayProxyRequest{},
+			expectedTenant: "",
+			expectedResponse: events.APIGatewayProxyResponse{
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				StatusCode: http.StatusBadRequest,
+				Body:       `{"error": "missing tenant"}`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotTenant string
+			handler := func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				gotTenant, _ = tenant.FromContext(ctx)
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+			}
+
+			resp, err := Tenant(tt.sources...)(handler)(context.Background(), tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedTenant, gotTenant)
+			assert.Equal(t, tt.expectedResponse, resp)
+		})
+	}
+}
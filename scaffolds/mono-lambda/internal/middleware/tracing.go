@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span for every request, continuing the trace of the caller when the
+// request carries a W3C `traceparent` or an `X-Amzn-Trace-Id` header. The execution environment
+// is frozen between invocations, so the spans of provider are flushed before the response is
+// returned.
+func Tracing(provider *sdktrace.TracerProvider) LambdaMiddleware {
+	tracer := provider.Tracer(telemetry.TracerName)
+	propagator := telemetry.Propagator()
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			headers := make(http.Header, len(request.Headers))
+			for key, value := range request.Headers {
+				headers.Set(key, value)
+			}
+			ctx = propagator.Extract(ctx, propagation.HeaderCarrier(headers))
+
+			ctx, span := tracer.Start(ctx, request.HTTPMethod+" "+request.Resource,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(request.HTTPMethod),
+					semconv.HTTPRoute(request.Resource),
+					semconv.URLPath(request.Path),
+				),
+			)
+			defer func() {
+				_ = provider.ForceFlush(context.WithoutCancel(ctx))
+			}()
+
+			response, err := next(ctx, request)
+			span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+			if response.StatusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+			}
+			telemetry.End(span, err)
+
+			return response, err
+		}
+	}
+}
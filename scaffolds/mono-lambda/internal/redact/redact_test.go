@@ -0,0 +1,106 @@
+package redact
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type customer struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name" log:"sensitive"`
+	LastName  string `json:"last_name" log:"sensitive"`
+	Email     string `json:"email"`
+	Role      string
+	internal  string
+}
+
+func TestPolicyValue(t *testing.T) {
+	policy := NewPolicy([]string{"email", "password"}, []string{"last_name"})
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := errors.New("boom")
+
+	tests := map[string]struct {
+		value    any
+		expected any
+	}{
+		"tagged and denied fields": {
+			value: customer{ID: 1, FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Role: "Customer"},
+			expected: map[string]any{
+				"id":         1,
+				"first_name": Mask,
+				"last_name":  "Lovelace",
+				"email":      Mask,
+				"Role":       "Customer",
+			},
+		},
+		"nested in pointers and slices": {
+			value: []*customer{{ID: 2, FirstName: "Grace"}, nil},
+			expected: []any{
+				map[string]any{"id": 2, "first_name": Mask, "last_name": "", "email": Mask, "Role": ""},
+				nil,
+			},
+		},
+		"map keys": {
+			value:    map[string]any{"Password": "hunter2", "user": map[string]string{"email": "a@b.c"}},
+			expected: map[string]any{"Password": Mask, "user": map[string]any{"email": Mask}},
+		},
+		"opaque values": {
+			value:    map[string]any{"created": created, "err": err},
+			expected: map[string]any{"created": created, "err": err},
+		},
+		"plain value": {
+			value:    "hello",
+			expected: "hello",
+		},
+		"nil": {
+			value:    nil,
+			expected: nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, policy.Value(tc.value))
+		})
+	}
+}
+
+func TestPolicyAttr(t *testing.T) {
+	policy := NewPolicy([]string{"first_name", "authorization"}, nil)
+
+	tests := map[string]struct {
+		attr     slog.Attr
+		expected slog.Attr
+	}{
+		"denied key": {
+			attr:     slog.String("firstName", "Ada"),
+			expected: slog.String("firstName", Mask),
+		},
+		"other key": {
+			attr:     slog.Int("status", 200),
+			expected: slog.Int("status", 200),
+		},
+		"group": {
+			attr:     slog.Group("request", slog.String("Authorization", "Bearer token"), slog.String("method", "GET")),
+			expected: slog.Group("request", slog.String("Authorization", Mask), slog.String("method", "GET")),
+		},
+		"json body": {
+			attr:     slog.String("body", `{"user":{"first_name":"Ada","role":"Customer"}}`),
+			expected: slog.String("body", `{"user":{"first_name":"[REDACTED]","role":"Customer"}}`),
+		},
+		"non json body": {
+			attr:     slog.String("body", "first_name=Ada"),
+			expected: slog.String("body", Mask),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected.String(), policy.Attr(tc.attr).String())
+		})
+	}
+}
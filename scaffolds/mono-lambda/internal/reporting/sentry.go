@@ -0,0 +1,198 @@
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/tenant"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sentryClient identifies the reporter to Sentry.
+const sentryClient = "go-microservice-templates/1.0"
+
+// SentryReporter sends events to Sentry, or any service accepting Sentry envelopes, e.g.
+// GlitchTip. Events are sent synchronously, so a client timeout bounds the time Report takes.
+type SentryReporter struct {
+	client      *http.Client
+	dsn         string
+	endpoint    string
+	auth        string
+	environment string
+	now         func() time.Time
+}
+
+// NewSentryReporter returns a SentryReporter sending events with client to the project of dsn,
+// e.g. `https://<key>@o0.ingest.sentry.io/<project>`, tagged with environment.
+func NewSentryReporter(client *http.Client, dsn string, environment string) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("[in reporting.NewSentryReporter] invalid dsn: %w", err)
+	}
+	key := u.User.Username()
+	slash := strings.LastIndex(u.Path, "/")
+	if u.Scheme == "" || u.Host == "" || key == "" || slash < 0 || u.Path[slash+1:] == "" {
+		return nil, fmt.Errorf("[in reporting.NewSentryReporter] dsn must be of the form scheme://key@host/project")
+	}
+
+	return &SentryReporter{
+		client:      client,
+		dsn:         dsn,
+		endpoint:    fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, u.Path[:slash], u.Path[slash+1:]),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_key=%s, sentry_client=%s", key, sentryClient),
+		environment: environment,
+		now:         time.Now,
+	}, nil
+}
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Environment string            `json:"environment,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Exception   sentryExceptions  `json:"exception"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type       string           `json:"type"`
+	Value      string           `json:"value"`
+	Mechanism  sentryMechanism  `json:"mechanism"`
+	Stacktrace sentryStacktrace `json:"stacktrace"`
+}
+
+type sentryMechanism struct {
+	Type    string `json:"type"`
+	Handled bool   `json:"handled"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// Report sends event to Sentry. Failures are logged, as there is nowhere else to report them.
+func (s *SentryReporter) Report(ctx context.Context, event Event) {
+	logger := logging.FromContext(ctx)
+
+	body, eventID, err := s.envelope(ctx, event)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to encode error report", "err", err)
+		return
+	}
+
+	// the request may already be canceled, the report is sent anyway
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to create error report request", "err", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to send error report", "err", err, "event_id", eventID)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		logger.ErrorContext(ctx, "Error report rejected", "status", resp.StatusCode, "event_id", eventID)
+	}
+}
+
+// envelope encodes event as a Sentry envelope holding a single event item.
+func (s *SentryReporter) envelope(ctx context.Context, event Event) ([]byte, string, error) {
+	eventID := logging.NewRequestID()
+	now := s.now().UTC().Format(time.RFC3339Nano)
+
+	level, mechanism := "error", "generic"
+	if event.Panic {
+		level, mechanism = "fatal", "recovery"
+	}
+
+	// Sentry expects the outermost frame first
+	frames := make([]sentryFrame, len(event.Stack))
+	for i, frame := range event.Stack {
+		frames[len(frames)-1-i] = sentryFrame{
+			Function: frame.Function,
+			AbsPath:  frame.File,
+			Lineno:   frame.Line,
+			InApp:    strings.HasPrefix(frame.Function, "main.") || strings.Contains(frame.Function, "/internal/"),
+		}
+	}
+
+	payload, err := json.Marshal(sentryEvent{
+		EventID:     eventID,
+		Timestamp:   now,
+		Platform:    "go",
+		Level:       level,
+		Environment: s.environment,
+		Tags:        contextTags(ctx, event.Tags),
+		Exception: sentryExceptions{Values: []sentryException{{
+			Type:       errorType(event.Err),
+			Value:      event.Err.Error(),
+			Mechanism:  sentryMechanism{Type: mechanism, Handled: !event.Panic},
+			Stacktrace: sentryStacktrace{Frames: frames},
+		}}},
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("[in reporting.SentryReporter.envelope]: %w", err)
+	}
+
+	header, err := json.Marshal(map[string]string{"event_id": eventID, "dsn": s.dsn, "sent_at": now})
+	if err != nil {
+		return nil, "", fmt.Errorf("[in reporting.SentryReporter.envelope]: %w", err)
+	}
+
+	var b bytes.Buffer
+	b.Write(header)
+	fmt.Fprintf(&b, "\n{\"type\":\"event\",\"length\":%d}\n", len(payload))
+	b.Write(payload)
+	b.WriteByte('\n')
+	return b.Bytes(), eventID, nil
+}
+
+// contextTags returns tags with the request ID, Lambda request ID, trace ID, route and tenant
+// carried by ctx added.
+func contextTags(ctx context.Context, tags map[string]string) map[string]string {
+	all := make(map[string]string, len(tags)+5)
+	for key, value := range tags {
+		all[key] = value
+	}
+	if id, ok := logging.RequestID(ctx); ok {
+		all["request_id"] = id
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok && lc.AwsRequestID != "" {
+		all["aws_request_id"] = lc.AwsRequestID
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		all["trace_id"] = spanContext.TraceID().String()
+	}
+	if route, ok := logging.Route(ctx); ok {
+		all["route"] = route
+	}
+	if id, ok := tenant.FromContext(ctx); ok {
+		all["tenant_id"] = id
+	}
+	return all
+}
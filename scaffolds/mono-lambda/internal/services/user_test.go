@@ -1,24 +1,30 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"database/sql/driver"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"regexp"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/encryption"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/tenant"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type testSuit struct {
 	suite.Suite
-	service *UserService
-	dbMock  sqlmock.Sqlmock
+	service  *UserService
+	envelope *encryption.Envelope
+	dbMock   sqlmock.Sqlmock
 }
 
 func TestTestSuit(t *testing.T) {
@@ -29,8 +35,39 @@ func (s *testSuit) SetupSuite() {
 	db, mock, err := sqlmock.New()
 	assert.NoError(s.T(), err)
 
+	keys, err := encryption.NewLocalKeyProvider("key-1", map[string][]byte{"key-1": bytes.Repeat([]byte{1}, 32)})
+	assert.NoError(s.T(), err)
+	s.envelope, err = encryption.NewEnvelope(keys, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32)))
+	assert.NoError(s.T(), err)
+
 	s.dbMock = mock
-	s.service = NewUserService(db)
+	s.service = NewUserService(db, s.envelope)
+}
+
+// encrypted returns user with the fields tagged `encrypt` encrypted for tenantID.
+func (s *testSuit) encrypted(tenantID string, users ...models.User) []models.User {
+	encrypted := make([]models.User, len(users))
+	for i, user := range users {
+		assert.NoError(s.T(), s.envelope.EncryptFields(context.Background(), &user, tenantID))
+		encrypted[i] = user
+	}
+	return encrypted
+}
+
+// decryptsTo matches a query argument that decrypts to value in scope.
+type decryptsTo struct {
+	envelope *encryption.Envelope
+	scope    string
+	value    string
+}
+
+func (d decryptsTo) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	if !ok || s == d.value {
+		return false
+	}
+	decrypted, err := d.envelope.Decrypt(context.Background(), s, d.scope)
+	return err == nil && decrypted == d.value
 }
 
 func (s *testSuit) TearDownSuite() {
@@ -46,33 +83,153 @@ func (s *testSuit) TestListUsers() {
 	}
 
 	testCases := map[string]struct {
+		mockCalled     bool
 		mockReturn     *sqlmock.Rows
 		mockReturnErr  error
+		ctx            context.Context
 		expectedReturn []models.User
 		expectedError  error
 	}{
 		"Return slice of users": {
+			mockCalled:     true,
 			mockReturn:     testutil.MustStructsToRows(users),
 			mockReturnErr:  nil,
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			expectedReturn: users,
+			expectedError:  nil,
+		},
+		"Return slice of encrypted users": {
+			mockCalled:     true,
+			mockReturn:     testutil.MustStructsToRows(s.encrypted("tenant-a", users...)),
+			mockReturnErr:  nil,
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
 			expectedReturn: users,
 			expectedError:  nil,
 		},
 		"Error getting users": {
+			mockCalled:     true,
 			mockReturn:     &sqlmock.Rows{},
 			mockReturnErr:  errors.New("test"),
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
 			expectedReturn: []models.User{},
 			expectedError:  fmt.Errorf("[in services.ListUsers] failed to get users: %w", errors.New("test")),
 		},
+		"Missing tenant": {
+			mockCalled:     false,
+			ctx:            context.Background(),
+			expectedReturn: []models.User{},
+			expectedError:  fmt.Errorf("[in services.ListUsers]: %w", tenant.ErrMissing),
+		},
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
-			exp := `SELECT * FROM "users"`
-			s.dbMock.
-				ExpectQuery(regexp.QuoteMeta(exp)).
-				WillReturnRows(tc.mockReturn).
-				WillReturnError(tc.mockReturnErr)
+			exp := `
+				SELECT
+					"id",
+					"first_name",
+					"last_name",
+					"role",
+					"user_id"
+				FROM
+					"users"
+				WHERE
+					"tenant_id" = $1
+			`
+			if tc.mockCalled {
+				s.dbMock.
+					ExpectQuery(regexp.QuoteMeta(exp)).
+					WithArgs("tenant-a").
+					WillReturnRows(tc.mockReturn).
+					WillReturnError(tc.mockReturnErr)
+			}
+
+			actualReturn, err := s.service.ListUsers(tc.ctx)
+
+			assert.Equal(t, tc.expectedError, err, "errors did not match")
+			assert.Equal(t, tc.expectedReturn, actualReturn, "returned data does not match")
+
+			err = s.dbMock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func (s *testSuit) TestGetUser() {
+	t := s.T()
+
+	user := models.User{ID: 1, FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
+
+	testCases := map[string]struct {
+		mockCalled     bool
+		mockReturn     *sqlmock.Rows
+		mockReturnErr  error
+		ctx            context.Context
+		expectedReturn models.User
+		expectedError  error
+	}{
+		"Return user": {
+			mockCalled:     true,
+			mockReturn:     testutil.MustStructsToRows([]models.User{user}),
+			mockReturnErr:  nil,
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			expectedReturn: user,
+			expectedError:  nil,
+		},
+		"Return encrypted user": {
+			mockCalled:     true,
+			mockReturn:     testutil.MustStructsToRows(s.encrypted("tenant-a", user)),
+			mockReturnErr:  nil,
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			expectedReturn: user,
+			expectedError:  nil,
+		},
+		"User not found": {
+			mockCalled:     true,
+			mockReturn:     testutil.MustStructToEmptyRow(user),
+			mockReturnErr:  nil,
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			expectedReturn: models.User{},
+			expectedError:  fmt.Errorf("[in services.GetUser] user 1: %w", ErrNotFound),
+		},
+		"Error getting user": {
+			mockCalled:     true,
+			mockReturn:     &sqlmock.Rows{},
+			mockReturnErr:  errors.New("test"),
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			expectedReturn: models.User{},
+			expectedError:  fmt.Errorf("[in services.GetUser] failed to get user: %w", errors.New("test")),
+		},
+		"Missing tenant": {
+			mockCalled:     false,
+			ctx:            context.Background(),
+			expectedReturn: models.User{},
+			expectedError:  fmt.Errorf("[in services.GetUser]: %w", tenant.ErrMissing),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			exp := `
+				SELECT
+					"id",
+					"first_name",
+					"last_name",
+					"role",
+					"user_id"
+				FROM
+					"users"
+				WHERE
+					"id" = $1
+					AND "tenant_id" = $2
+			`
+			if tc.mockCalled {
+				s.dbMock.
+					ExpectQuery(regexp.QuoteMeta(exp)).
+					WithArgs(1, "tenant-a").
+					WillReturnRows(tc.mockReturn).
+					WillReturnError(tc.mockReturnErr)
+			}
 
-			actualReturn, err := s.service.ListUsers(context.Background())
+			actualReturn, err := s.service.GetUser(tc.ctx, 1)
 
 			assert.Equal(t, tc.expectedError, err, "errors did not match")
 			assert.Equal(t, tc.expectedReturn, actualReturn, "returned data does not match")
@@ -89,33 +246,63 @@ func (s *testSuit) TestUpdateUser() {
 	userIn := models.User{ID: 0, FirstName: "John", LastName: "Doe", Role: "Admin", UserID: 1001}
 	userOut := models.User{ID: 1, FirstName: "John", LastName: "Doe", Role: "Admin", UserID: 1001}
 
+	// names are stored encrypted, the last name also by blind index
+	firstName := decryptsTo{envelope: s.envelope, scope: "tenant-a/first_name", value: userIn.FirstName}
+	lastName := decryptsTo{envelope: s.envelope, scope: "tenant-a/last_name", value: userIn.LastName}
+	lastNameIndex := s.envelope.BlindIndex(userIn.LastName, "tenant-a/last_name")
+
 	testCases := map[string]struct {
+		mockCalled     bool
 		mockInputArgs  []driver.Value
 		mockReturn     driver.Result
 		mockReturnErr  error
+		ctx            context.Context
 		inputID        int
 		inputUser      models.User
 		expectedReturn models.User
 		expectedError  error
 	}{
 		"user updated by ID": {
-			mockInputArgs:  []driver.Value{userIn.FirstName, userIn.LastName, userIn.Role, userIn.UserID, int(userOut.ID)},
+			mockCalled:     true,
+			mockInputArgs:  []driver.Value{firstName, lastName, userIn.Role, userIn.UserID, lastNameIndex, int(userOut.ID), "tenant-a"},
 			mockReturn:     sqlmock.NewResult(1, 1),
 			mockReturnErr:  nil,
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
 			inputID:        int(userOut.ID),
 			inputUser:      userIn,
 			expectedReturn: userOut,
 			expectedError:  nil,
 		},
+		"user missing or of another tenant": {
+			mockCalled:     true,
+			mockInputArgs:  []driver.Value{firstName, lastName, userIn.Role, userIn.UserID, lastNameIndex, int(userOut.ID), "tenant-a"},
+			mockReturn:     sqlmock.NewResult(0, 0),
+			mockReturnErr:  nil,
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			inputID:        int(userOut.ID),
+			inputUser:      userIn,
+			expectedReturn: models.User{},
+			expectedError:  fmt.Errorf("[in services.UpdateUser] user %d: %w", userOut.ID, ErrNotFound),
+		},
 		"Error updating user": {
-			mockInputArgs:  []driver.Value{userIn.FirstName, userIn.LastName, userIn.Role, userIn.UserID, 0},
+			mockCalled:     true,
+			mockInputArgs:  []driver.Value{firstName, lastName, userIn.Role, userIn.UserID, lastNameIndex, 0, "tenant-a"},
 			mockReturn:     nil,
 			mockReturnErr:  errors.New("test"),
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
 			inputID:        0,
 			inputUser:      userIn,
 			expectedReturn: models.User{},
 			expectedError:  fmt.Errorf("[in services.UpdateUser] failed to update user: %w", errors.New("test")),
 		},
+		"Missing tenant": {
+			mockCalled:     false,
+			ctx:            context.Background(),
+			inputID:        int(userOut.ID),
+			inputUser:      userIn,
+			expectedReturn: models.User{},
+			expectedError:  fmt.Errorf("[in services.UpdateUser]: %w", tenant.ErrMissing),
+		},
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
@@ -126,17 +313,21 @@ func (s *testSuit) TestUpdateUser() {
 					"first_name" = $1,
 					"last_name" = $2,
 					"role" = $3,
-					"user_id" = $4
+					"user_id" = $4,
+					"last_name_index" = $5
 				WHERE
-					"id" = $5
+					"id" = $6
+					AND "tenant_id" = $7
 			`
-			s.dbMock.
-				ExpectExec(regexp.QuoteMeta(exp)).
-				WithArgs(tc.mockInputArgs...).
-				WillReturnResult(tc.mockReturn).
-				WillReturnError(tc.mockReturnErr)
+			if tc.mockCalled {
+				s.dbMock.
+					ExpectExec(regexp.QuoteMeta(exp)).
+					WithArgs(tc.mockInputArgs...).
+					WillReturnResult(tc.mockReturn).
+					WillReturnError(tc.mockReturnErr)
+			}
 
-			actualReturn, err := s.service.UpdateUser(context.Background(), tc.inputID, tc.inputUser)
+			actualReturn, err := s.service.UpdateUser(tc.ctx, tc.inputID, tc.inputUser)
 
 			assert.Equal(t, tc.expectedError, err, "errors did not match")
 			assert.Equal(t, tc.expectedReturn, actualReturn, "returned data does not match")
@@ -146,3 +337,100 @@ func (s *testSuit) TestUpdateUser() {
 		})
 	}
 }
+
+func (s *testSuit) TestFindUsersByLastName() {
+	t := s.T()
+
+	users := []models.User{
+		{ID: 2, FirstName: "Jane", LastName: "Smith", Role: "User", UserID: 1002},
+	}
+
+	testCases := map[string]struct {
+		mockCalled     bool
+		mockReturn     *sqlmock.Rows
+		mockReturnErr  error
+		ctx            context.Context
+		expectedReturn []models.User
+		expectedError  error
+	}{
+		"Return slice of users": {
+			mockCalled:     true,
+			mockReturn:     testutil.MustStructsToRows(s.encrypted("tenant-a", users...)),
+			mockReturnErr:  nil,
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			expectedReturn: users,
+			expectedError:  nil,
+		},
+		"Error getting users": {
+			mockCalled:     true,
+			mockReturn:     &sqlmock.Rows{},
+			mockReturnErr:  errors.New("test"),
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			expectedReturn: []models.User{},
+			expectedError:  fmt.Errorf("[in services.FindUsersByLastName] failed to get users: %w", errors.New("test")),
+		},
+		"Missing tenant": {
+			mockCalled:     false,
+			ctx:            context.Background(),
+			expectedReturn: []models.User{},
+			expectedError:  fmt.Errorf("[in services.FindUsersByLastName]: %w", tenant.ErrMissing),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			exp := `
+				SELECT
+					"id",
+					"first_name",
+					"last_name",
+					"role",
+					"user_id"
+				FROM
+					"users"
+				WHERE
+					"tenant_id" = $1
+					AND "last_name_index" = $2
+			`
+			if tc.mockCalled {
+				s.dbMock.
+					ExpectQuery(regexp.QuoteMeta(exp)).
+					WithArgs("tenant-a", s.envelope.BlindIndex("smith", "tenant-a/last_name")).
+					WillReturnRows(tc.mockReturn).
+					WillReturnError(tc.mockReturnErr)
+			}
+
+			actualReturn, err := s.service.FindUsersByLastName(tc.ctx, "SMITH")
+
+			assert.Equal(t, tc.expectedError, err, "errors did not match")
+			assert.Equal(t, tc.expectedReturn, actualReturn, "returned data does not match")
+
+			err = s.dbMock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func (s *testSuit) TestUserSpans() {
+	t := s.T()
+	recorder := testutil.NewSpanRecorder(t)
+
+	user := models.User{ID: 1, FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
+	s.dbMock.
+		ExpectQuery(regexp.QuoteMeta(`FROM`)).
+		WithArgs(1, "tenant-a").
+		WillReturnRows(testutil.MustStructsToRows([]models.User{user}))
+
+	_, err := s.service.GetUser(tenant.WithID(context.Background(), "tenant-a"), 1)
+	assert.NoError(t, err)
+	_, err = s.service.GetUser(context.Background(), 1)
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "UserService.GetUser", spans[0].Name())
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+		assert.Equal(t, "UserService.GetUser", spans[1].Name())
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+	}
+	assert.NoError(t, s.dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,31 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWTIssuer(t *testing.T) {
+	issuer := NewJWTIssuer(t)
+
+	resp, err := http.Get(issuer.JWKSURL())
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var document struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&document))
+	assert.Len(t, document.Keys, 2)
+	assert.Equal(t, int64(1), issuer.Requests())
+
+	for _, alg := range []string{"RS256", "ES256"} {
+		token := issuer.Sign(alg, "", map[string]any{"sub": "user"})
+		assert.Len(t, strings.Split(token, "."), 3)
+	}
+	assert.Panics(t, func() { issuer.Sign("HS256", "", nil) })
+}
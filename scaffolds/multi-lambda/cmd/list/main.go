@@ -5,15 +5,27 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
 	"time"
 
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/config"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/database"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/encryption"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/handlers"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/metrics"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/middleware"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/ratelimit"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/redact"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/reporting"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/services"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/telemetry"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -29,22 +41,72 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("[in main.run]: %w", err)
 	}
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: cfg.LogLevel,
-	}))
+	// sensitive attributes are masked before they reach CloudWatch, and every line logged with a
+	// request's context is tagged with its request ID, trace ID and route
+	logHandler, err := logging.NewHandler(os.Stdout, logging.Options{
+		Format:    cfg.LogFormat,
+		Level:     cfg.LogLevel,
+		AddSource: cfg.LogAddSource,
+	})
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
+	logger := slog.New(redact.NewHandler(logHandler, redact.NewPolicy(cfg.LogRedactKeys, cfg.LogRedactAllow)))
+
+	// handlers and middleware log with logging.FromContext, which falls back to the default logger
+	slog.SetDefault(logger)
+
+	// spans are exported as configured by TRACING_EXPORTER, see telemetry.NewTracerProvider
+	tracerProvider, err := telemetry.NewTracerProvider(ctx, cfg.TracingExporter, cfg.TracingServiceName)
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
+	defer func() {
+		if err = tracerProvider.Shutdown(ctx); err != nil {
+			logger.Error("Error shutting down tracer provider", "err", err)
+		}
+	}()
+
+	// panics are logged with their stack, and sent to Sentry when ERROR_REPORTING_DSN is set
+	var reporter reporting.ErrorReporter = reporting.NewLogReporter()
+	if cfg.ErrorReportingDSN != "" {
+		sentry, err := reporting.NewSentryReporter(
+			&http.Client{Timeout: time.Duration(cfg.ErrorReportingTimeout) * time.Second},
+			cfg.ErrorReportingDSN,
+			cfg.Env,
+		)
+		if err != nil {
+			return fmt.Errorf("[in main.run]: %w", err)
+		}
+		reporter = reporting.Multi(reporter, sentry)
+	}
+
+	// metrics are written to stdout in CloudWatch Embedded Metric Format, see metrics.Emitter
+	emitter := metrics.NewEmitter(os.Stdout, cfg.MetricsNamespace, map[string]string{
+		"FunctionName": lambdacontext.FunctionName,
+	})
+
+	// with IAM auth, connections authenticate with short-lived tokens instead of DATABASE_PASSWORD
+	var dbCredentials database.Credentials = cfg.DBCredentials
+	if cfg.DBIAMAuth {
+		if dbCredentials, err = database.NewIAMCredentials(ctx, cfg.DBHost, cfg.DBPort, cfg.DBUser); err != nil {
+			return fmt.Errorf("[in main.run]: %w", err)
+		}
+	}
 
 	db, err := database.New(
 		ctx,
-		fmt.Sprintf(
-			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-			cfg.DBHost,
-			cfg.DBUser,
-			cfg.DBPassword,
-			cfg.DBName,
-			cfg.DBPort,
-		),
+		database.DSN{
+			Host:        cfg.DBHost,
+			Port:        cfg.DBPort,
+			Name:        cfg.DBName,
+			SSLMode:     cfg.DBSSLMode,
+			SSLRootCert: cfg.DBSSLRootCert,
+		},
+		dbCredentials,
 		logger,
 		time.Duration(cfg.DBRetryDuration)*time.Second,
+		database.WithSlowQueryThreshold(time.Duration(cfg.DBSlowQueryThreshold)*time.Millisecond),
 	)
 	if err != nil {
 		return fmt.Errorf("[in main.run]: %w", err)
@@ -56,15 +118,115 @@ func run(ctx context.Context) error {
 		}
 	}()
 
-	service := services.NewUserService(db)
+	// with gateway authentication the authorizer in cmd/authorizer has already verified the caller
+	// and resolved the tenant
+	authenticate := []middleware.LambdaMiddleware{middleware.Principal()}
+	tenantSources := []middleware.TenantSource{middleware.TenantFromPrincipal()}
+	if !cfg.AuthGateway {
+		verifier := auth.NewVerifier(
+			auth.NewJWKS(cfg.AuthJWKSURL, time.Duration(cfg.AuthJWKSRefresh)*time.Second),
+			cfg.AuthIssuer,
+			cfg.AuthAudience,
+			time.Duration(cfg.AuthClockSkew)*time.Second,
+		)
+
+		// validated API keys are cached for the lifetime of the execution environment, so
+		// revocations and expiry take effect within the cache TTL
+		apiKeys := auth.NewAPIKeyCache(services.NewAPIKeyService(db), time.Duration(cfg.APIKeyCacheTTL)*time.Second)
+		authenticate = []middleware.LambdaMiddleware{
+			middleware.APIKey(apiKeys, cfg.APIKeyHeader),
+			middleware.Authenticate(verifier),
+		}
+
+		// the tenant comes from a claim of the verified token, so callers cannot choose their
+		// tenant. API keys always carry their own tenant.
+		tenantSources = append(tenantSources, middleware.TenantFromClaim(cfg.TenantClaim))
+	}
+
+	rateLimits, err := ratelimit.ParseLimits(cfg.RateLimitDefault, cfg.RateLimitRoutes)
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
+	ipRateLimit, err := ratelimit.ParseLimit(cfg.RateLimitIP)
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
+
+	// in-memory buckets only live as long as the execution environment and are not shared between
+	// concurrent ones, so configure Redis for limits that hold across the whole function
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimitRedisURL != "" {
+		redisOptions, err := redis.ParseURL(cfg.RateLimitRedisURL)
+		if err != nil {
+			return fmt.Errorf("[in main.run] invalid rate limit redis url: %w", err)
+		}
+		redisClient := redis.NewClient(redisOptions)
+		defer func() {
+			if err = redisClient.Close(); err != nil {
+				logger.Error("Error closing redis connection", "err", err)
+			}
+		}()
+		rateLimitStore = ratelimit.NewRedisStore(redisClient)
+	}
 
-	handler := handlers.HandleListUsers(logger, service)
+	// user names are encrypted at rest under data keys wrapped by the configured key provider
+	keys, err := encryption.NewKeyProvider(ctx, cfg.EncryptionKeyProvider, cfg.EncryptionKeyFile, cfg.EncryptionKMSKeyID)
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
+	envelope, err := encryption.NewEnvelope(keys, cfg.EncryptionIndexKey)
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
 
-	handler = middleware.AddToHandler(
-		handler,
-		middleware.Recovery(logger),
+	service := services.NewUserService(db, envelope)
+
+	handler := handlers.HandleListUsers(service)
+
+	middlewares := []middleware.LambdaMiddleware{
+		middleware.Logger[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](logger),
+		middleware.Tracing(tracerProvider),
+		middleware.RequestID(),
+		middleware.Metrics(emitter, middleware.StatusClasses),
+		middleware.Security(middleware.SecurityHeaders{
+			HSTSMaxAge:            time.Duration(cfg.SecurityHSTSMaxAge) * time.Second,
+			ContentSecurityPolicy: cfg.SecurityCSP,
+			ReferrerPolicy:        cfg.SecurityReferrer,
+		}),
+		// the tenant and API key headers are always allowed, as every route needs them
+		middleware.CORS(middleware.CORSPolicy{
+			AllowedOrigins:   cfg.CORSAllowedOrigins,
+			AllowedMethods:   cfg.CORSAllowedMethods,
+			AllowedHeaders:   append(cfg.CORSAllowedHeaders, cfg.APIKeyHeader),
+			ExposedHeaders:   []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", middleware.RequestIDHeader},
+			AllowCredentials: cfg.CORSAllowCredentials,
+			MaxAge:           time.Duration(cfg.CORSMaxAge) * time.Second,
+		}),
+		// after Security and CORS, which add their headers to the response once it is returned, so the
+		// 500 written for a panic carries them too
+		middleware.Recovery(reporter),
+		// every source IP is limited before authentication, so invalid credentials cannot flood the
+		// database
+		middleware.RateLimitIP(rateLimitStore, ipRateLimit),
+	}
+	middlewares = append(middlewares, authenticate...)
+	middlewares = append(middlewares,
+		middleware.Tenant(tenantSources...),
+		// admins can have every record of a single invocation logged by setting LOG_DEBUG_HEADER
+		middleware.DebugLogging[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](
+			middleware.DebugHeader(cfg.LogDebugHeader, auth.Policy{Roles: []string{"Admin"}}),
+		),
+		// bodies of flagged invocations are logged too, with their sensitive fields masked
+		middleware.DebugBodies(),
+		middleware.RateLimit(rateLimitStore, rateLimits),
+		middleware.Authorize(auth.Policy{
+			Roles:  []string{"Employee"},
+			Scopes: []string{"users:read"},
+		}),
 	)
 
+	handler = middleware.AddToHandler(handler, middlewares...)
+
 	lambda.Start(handler)
 
 	return nil
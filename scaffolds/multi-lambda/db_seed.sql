@@ -1,28 +1,55 @@
 -- Drop the users table if it already exists
 DROP TABLE IF EXISTS users;
 
--- Create the users table
+-- Create the users table. Names are encrypted by the service, see internal/encryption, and last
+-- names are looked up by their blind index.
 CREATE TABLE users
 (
-    id         SERIAL PRIMARY KEY,
-    first_name VARCHAR(50)                                          NOT NULL,
-    last_name  VARCHAR(50)                                          NOT NULL,
-    role       VARCHAR(10) CHECK (role IN ('Customer', 'Employee')) NOT NULL,
-    user_id    INTEGER UNIQUE                                       NOT NULL
+    id              SERIAL PRIMARY KEY,
+    tenant_id       VARCHAR(64)                                          NOT NULL,
+    first_name      TEXT                                                 NOT NULL,
+    last_name       TEXT                                                 NOT NULL,
+    last_name_index CHAR(64)                                             NOT NULL,
+    role            VARCHAR(10) CHECK (role IN ('Customer', 'Employee')) NOT NULL,
+    user_id         INTEGER                                              NOT NULL,
+    UNIQUE (tenant_id, user_id)
 );
 
--- Insert 10 records into the users table
-INSERT INTO users (first_name, last_name, role, user_id)
-VALUES ('John', 'Doe', 'Customer', 1001),
-       ('Jane', 'Smith', 'Employee', 1002),
-       ('Robert', 'Johnson', 'Employee', 1003),
-       ('Emily', 'Davis', 'Customer', 1004),
-       ('Michael', 'Brown', 'Employee', 1005),
-       ('Linda', 'Wilson', 'Employee', 1006),
-       ('David', 'Martinez', 'Customer', 1007),
-       ('Elizabeth', 'Taylor', 'Employee', 1008),
-       ('Richard', 'Anderson', 'Employee', 1009),
-       ('Susan', 'Thomas', 'Customer', 1010);
+CREATE INDEX users_last_name_index ON users (tenant_id, last_name_index);
+
+-- Insert 10 records into the users table, split across two tenants. The names are encrypted with
+-- the development keys in keys.local.json and ENCRYPTION_INDEX_KEY.
+INSERT INTO users (tenant_id, first_name, last_name, last_name_index, role, user_id)
+VALUES ('tenant-a', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:pbwg56k__Hkqkr6pWDMqBwdphPyUWq9O_Xzv2G7ooDs', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:RUbINHpI3gUZJB8i97IyLqY1x7aiyHEf90_XeL1x5Q', 'afd50e1fd55260de8da5d5b5c420d3387a90f39a67726fd68e12a1b10e49ab8f', 'Customer', 1001),
+       ('tenant-a', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:0Uim7hLozf6RH55yHJAG1dl1JgAG5Oe8axfP_Hr-YvA', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:cIu-3CYGy_-OWFVwyg5JpGw_-D-k901AF9-UTMsclJny', 'c3b3fec37f858b632da7ed9234163920e3fc8a39dae3f50a16ac9e816d12e47d', 'Employee', 1002),
+       ('tenant-a', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:u9iL6OxPM0W42Q_VBdFGl_wFPnNwq9nm630E2mx0F_td5w', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:xpyWA_UkX1Ed-7tzefmRKjxozMRBJnhZHUmCvy_a7xL2GW4', '4892a08f2c486407d5e9b1783b2f6978bb027f1315a23d8d514dd7fc529cdc34', 'Employee', 1003),
+       ('tenant-a', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:5-Vzl1wzg0MDenVOkrfWKXnVgEuIihC1Y4OPbSqOwI0x', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:v3yJxF3Xl-FnDldHK4k-Mq8ZLvtJsASMNDjKiAu7O8hC', 'af156f82b5fdf342aaddbd32f6b36c9d09d0f49b2a97aebc2a3a5c54886b1e74', 'Customer', 1004),
+       ('tenant-a', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:YY0bAPWsHI7CnZi3ydzD7J43CHapR5nVmf7yM7gEdx7Bzng', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:JPi4n16ENfNe5DhbplI-bFvSe4jMXzcz4ylZapsKXCbR', 'e30c307dcb3b24f13d0f55349718038ef438980792bb880e92c96cdda2047b98', 'Employee', 1005),
+       ('tenant-b', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:w2JrkeakPB-J9DC0pB2dE1yODL3UBFxTgTdeg-GeoS-d', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:5ae8JcVRw2aedfOMqdn897RTuyJu_SlAm05-aDtA3hL3Tg', 'f1a2e1a7022473e0213181e862718c953980dd4dc884d0a34a7f7614935520d4', 'Employee', 1001),
+       ('tenant-b', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:XkwOvjxEfgBe0tUJ6MY8ncgdbEeWZPL5f5Lzv31cpj5V', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:u2tbGHt_8moKr1AdI6RfQqasl-B4eK0MSGFHLxlifhlgSmT9', 'fb2ad775755cfd20bf73392c08ba8cc53830db46dfc2cf8d03c2e7b84b4a40b0', 'Customer', 1002),
+       ('tenant-b', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:2UbYtCqqVLoXzJpBsiF1-gREz-b1or3VWR56IRbQaSkkeP-DtA', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:_aKfXL7ONJXrh-rw-P-cl-HtBprCJJQtpivUCjbT7n7fkw', '0ccbf118f60b749cb9387bf3d09ac62468b0480089153366b1e9fcec06f5a079', 'Employee', 1003),
+       ('tenant-b', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:gSguEpQmcR2TqodL3EH-zAa-sSA0uUfRJdTKWk6zhi4jiRQ', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:3fqrdwaIGct-oJx1O0_9fhExvFcRQHGEgo1Nzsg1xmPvSg9P', 'efdb551e0dc221d6ed2a216a8cb7d3712fddad0fcb9fcdc509c3b0a2eabde004', 'Employee', 1004),
+       ('tenant-b', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:VPblXhS4MugkIuccz2BkZ1j-4K1BucLwpLfUCDAKciLg', 'enc:v1:bG9jYWwtMQ:oyP2msmZogLWzHzX5Rkt0K3zXWOT0YKI62iqSnfAdVwvFZ-x5EHRgWzun3gK7X9MMw3atfsv7SZ-u_TD:dTwlcAMx20tCdVy0cQFv8MMZXjjmIzJBfaLZS0KwJC89RQ', 'eec3e6a21273bc28160ef5e43812a54885e10f378c8d339fd5fa00e377d91caa', 'Customer', 1005);
+
+-- Drop the api_keys table if it already exists
+DROP TABLE IF EXISTS api_keys;
+
+-- Create the api_keys table. Only the SHA-256 hash of each key's secret is stored, the key itself
+-- is shown once on creation. Roles and scopes are space separated.
+CREATE TABLE api_keys
+(
+    id           SERIAL PRIMARY KEY,
+    tenant_id    VARCHAR(64)  NOT NULL,
+    prefix       CHAR(8)      NOT NULL UNIQUE,
+    hash         BYTEA        NOT NULL,
+    owner        VARCHAR(100) NOT NULL,
+    roles        TEXT         NOT NULL DEFAULT '',
+    scopes       TEXT         NOT NULL DEFAULT '',
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
 
 -- Select all records to verify the insertion
 SELECT *
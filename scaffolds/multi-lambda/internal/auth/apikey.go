@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiKeyScheme starts every API key so leaked keys are easy to recognize, e.g. by secret scanners.
+const apiKeyScheme = "ak"
+
+// maxCachedAPIKeys bounds the number of validated keys an APIKeyCache holds.
+const maxCachedAPIKeys = 1024
+
+// ErrInvalidAPIKey is returned when an API key is malformed, unknown, revoked or expired.
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// APIKeyValidator resolves an API key to the principal it authenticates.
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key string) (Principal, error)
+}
+
+// GenerateAPIKey returns a new API key of the form `ak_<prefix>_<secret>`. The prefix is public
+// and identifies the key, hash is the SHA-256 hash of the secret. Only prefix and hash should be
+// stored; key must be handed to its owner and then discarded.
+func GenerateAPIKey() (key string, prefix string, hash []byte, err error) {
+	prefixBytes := make([]byte, 4)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", nil, fmt.Errorf("[in auth.GenerateAPIKey] failed to generate prefix: %w", err)
+	}
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", nil, fmt.Errorf("[in auth.GenerateAPIKey] failed to generate secret: %w", err)
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
+	digest := sha256.Sum256([]byte(secret))
+
+	return apiKeyScheme + "_" + prefix + "_" + secret, prefix, digest[:], nil
+}
+
+// ParseAPIKey splits key into its prefix and the SHA-256 hash of its secret.
+func ParseAPIKey(key string) (prefix string, hash []byte, err error) {
+	scheme, rest, ok := strings.Cut(key, "_")
+	if !ok || scheme != apiKeyScheme {
+		return "", nil, fmt.Errorf("unknown key scheme: %w", ErrInvalidAPIKey)
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 8 || secret == "" {
+		return "", nil, fmt.Errorf("malformed key: %w", ErrInvalidAPIKey)
+	}
+
+	digest := sha256.Sum256([]byte(secret))
+	return prefix, digest[:], nil
+}
+
+// MatchAPIKeyHash reports whether hash equals stored. The comparison takes constant time.
+func MatchAPIKeyHash(hash []byte, stored []byte) bool {
+	return subtle.ConstantTimeCompare(hash, stored) == 1
+}
+
+type cachedAPIKey struct {
+	principal Principal
+	expires   time.Time
+}
+
+// valid reports whether neither the entry nor the key it holds has expired at now.
+func (e cachedAPIKey) valid(now time.Time) bool {
+	if !e.principal.ExpiresAt.IsZero() && !now.Before(e.principal.ExpiresAt) {
+		return false
+	}
+	return now.Before(e.expires)
+}
+
+// APIKeyCache caches successful validations of another APIKeyValidator in memory for a fixed
+// TTL, so repeated requests with the same key do not hit the database. An entry never outlives the
+// expiry of its key, but revoked keys keep working until their cache entry expires.
+type APIKeyCache struct {
+	next    APIKeyValidator
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]cachedAPIKey
+}
+
+// NewAPIKeyCache returns an APIKeyCache in front of next.
+func NewAPIKeyCache(next APIKeyValidator, ttl time.Duration) *APIKeyCache {
+	return &APIKeyCache{
+		next:    next,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[[sha256.Size]byte]cachedAPIKey),
+	}
+}
+
+// ValidateAPIKey returns the cached principal for key or validates it with the wrapped
+// validator. Keys are cached by their SHA-256 hash, the plain key is never held.
+func (c *APIKeyCache) ValidateAPIKey(ctx context.Context, key string) (Principal, error) {
+	digest := sha256.Sum256([]byte(key))
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[digest]
+	c.mu.Unlock()
+	if ok && entry.valid(now) {
+		return entry.principal, nil
+	}
+
+	principal, err := c.next.ValidateAPIKey(ctx, key)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedAPIKeys {
+		for k, e := range c.entries {
+			if !e.valid(now) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCachedAPIKeys {
+			clear(c.entries)
+		}
+	}
+	expires := now.Add(c.ttl)
+	if !principal.ExpiresAt.IsZero() && principal.ExpiresAt.Before(expires) {
+		expires = principal.ExpiresAt
+	}
+	c.entries[digest] = cachedAPIKey{principal: principal, expires: expires}
+
+	return principal, nil
+}
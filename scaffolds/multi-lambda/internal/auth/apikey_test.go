@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+	assert.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(key, "ak_"+prefix+"_"), "key %q does not start with its prefix", key)
+	assert.Len(t, prefix, 8)
+	assert.Len(t, hash, sha256.Size)
+	assert.NotContains(t, string(hash), key)
+
+	parsedPrefix, parsedHash, err := ParseAPIKey(key)
+	assert.NoError(t, err)
+	assert.Equal(t, prefix, parsedPrefix)
+	assert.True(t, MatchAPIKeyHash(parsedHash, hash))
+
+	other, _, _, err := GenerateAPIKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestParseAPIKey(t *testing.T) {
+	secretHash := sha256.Sum256([]byte("c2VjcmV0_with-url_chars"))
+
+	tests := map[string]struct {
+		key            string
+		expectedPrefix string
+		expectedHash   []byte
+		expectedErr    error
+	}{
+		"valid key": {
+			key:            "ak_0a1b2c3d_c2VjcmV0_with-url_chars",
+			expectedPrefix: "0a1b2c3d",
+			expectedHash:   secretHash[:],
+		},
+		"wrong scheme": {
+			key:         "sk_0a1b2c3d_c2VjcmV0",
+			expectedErr: ErrInvalidAPIKey,
+		},
+		"short prefix": {
+			key:         "ak_0a1b_c2VjcmV0",
+			expectedErr: ErrInvalidAPIKey,
+		},
+		"missing secret": {
+			key:         "ak_0a1b2c3d_",
+			expectedErr: ErrInvalidAPIKey,
+		},
+		"no separators": {
+			key:         "ak0a1b2c3dc2VjcmV0",
+			expectedErr: ErrInvalidAPIKey,
+		},
+		"empty": {
+			key:         "",
+			expectedErr: ErrInvalidAPIKey,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			prefix, hash, err := ParseAPIKey(tc.key)
+
+			if tc.expectedErr != nil {
+				assert.True(t, errors.Is(err, tc.expectedErr), "expected %v, got %v", tc.expectedErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPrefix, prefix)
+			assert.Equal(t, tc.expectedHash, hash)
+		})
+	}
+}
+
+func TestMatchAPIKeyHash(t *testing.T) {
+	a := sha256.Sum256([]byte("a"))
+	b := sha256.Sum256([]byte("b"))
+
+	assert.True(t, MatchAPIKeyHash(a[:], a[:]))
+	assert.False(t, MatchAPIKeyHash(a[:], b[:]))
+	assert.False(t, MatchAPIKeyHash(a[:], a[:16]))
+	assert.False(t, MatchAPIKeyHash(a[:], nil))
+}
+
+type countingValidator struct {
+	calls     int
+	principal Principal
+	err       error
+}
+
+func (v *countingValidator) ValidateAPIKey(_ context.Context, _ string) (Principal, error) {
+	v.calls++
+	return v.principal, v.err
+}
+
+func TestAPIKeyCache(t *testing.T) {
+	principal := Principal{Subject: "billing-batch", Tenant: "tenant-a", Method: MethodAPIKey}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("caches valid keys until the ttl passes", func(t *testing.T) {
+		next := &countingValidator{principal: principal}
+		cache := NewAPIKeyCache(next, time.Minute)
+		cache.now = func() time.Time { return now }
+
+		for range 3 {
+			got, err := cache.ValidateAPIKey(context.Background(), "ak_0a1b2c3d_secret")
+			assert.NoError(t, err)
+			assert.Equal(t, principal, got)
+		}
+		assert.Equal(t, 1, next.calls)
+
+		cache.now = func() time.Time { return now.Add(time.Minute) }
+		_, err := cache.ValidateAPIKey(context.Background(), "ak_0a1b2c3d_secret")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, next.calls)
+	})
+
+	t.Run("caches keys no longer than their own expiry", func(t *testing.T) {
+		expiring := principal
+		expiring.ExpiresAt = now.Add(10 * time.Second)
+		next := &countingValidator{principal: expiring}
+		cache := NewAPIKeyCache(next, time.Minute)
+		cache.now = func() time.Time { return now }
+
+		_, err := cache.ValidateAPIKey(context.Background(), "ak_0a1b2c3d_secret")
+		assert.NoError(t, err)
+		assert.Equal(t, now.Add(10*time.Second), cache.entries[sha256.Sum256([]byte("ak_0a1b2c3d_secret"))].expires)
+
+		next.principal, next.err = Principal{}, ErrInvalidAPIKey
+		cache.now = func() time.Time { return now.Add(10 * time.Second) }
+		_, err = cache.ValidateAPIKey(context.Background(), "ak_0a1b2c3d_secret")
+		assert.Equal(t, ErrInvalidAPIKey, err)
+		assert.Equal(t, 2, next.calls)
+	})
+
+	t.Run("rejects cached keys past their own expiry", func(t *testing.T) {
+		expiring := principal
+		expiring.ExpiresAt = now.Add(10 * time.Second)
+		next := &countingValidator{err: ErrInvalidAPIKey}
+		cache := NewAPIKeyCache(next, time.Minute)
+		cache.now = func() time.Time { return now.Add(time.Minute) }
+		cache.entries[sha256.Sum256([]byte("ak_0a1b2c3d_secret"))] = cachedAPIKey{
+			principal: expiring,
+			expires:   now.Add(2 * time.Minute),
+		}
+
+		_, err := cache.ValidateAPIKey(context.Background(), "ak_0a1b2c3d_secret")
+		assert.Equal(t, ErrInvalidAPIKey, err)
+		assert.Equal(t, 1, next.calls)
+	})
+
+	t.Run("keys are cached separately", func(t *testing.T) {
+		next := &countingValidator{principal: principal}
+		cache := NewAPIKeyCache(next, time.Minute)
+
+		_, _ = cache.ValidateAPIKey(context.Background(), "ak_0a1b2c3d_secret")
+		_, _ = cache.ValidateAPIKey(context.Background(), "ak_0a1b2c3d_other")
+		assert.Equal(t, 2, next.calls)
+	})
+
+	t.Run("does not cache failures", func(t *testing.T) {
+		next := &countingValidator{err: ErrInvalidAPIKey}
+		cache := NewAPIKeyCache(next, time.Minute)
+
+		for range 2 {
+			_, err := cache.ValidateAPIKey(context.Background(), "ak_0a1b2c3d_secret")
+			assert.Equal(t, ErrInvalidAPIKey, err)
+		}
+		assert.Equal(t, 2, next.calls)
+	})
+
+	t.Run("stays bounded", func(t *testing.T) {
+		next := &countingValidator{principal: principal}
+		cache := NewAPIKeyCache(next, time.Minute)
+
+		for i := range maxCachedAPIKeys + 10 {
+			_, err := cache.ValidateAPIKey(context.Background(), "ak_0a1b2c3d_"+strings.Repeat("x", i+1))
+			assert.NoError(t, err)
+		}
+		assert.LessOrEqual(t, len(cache.entries), maxCachedAPIKeys)
+	})
+}
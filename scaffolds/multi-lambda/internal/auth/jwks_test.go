@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWKSKey(t *testing.T) {
+	issuer := testutil.NewJWTIssuer(t)
+
+	now := time.Now()
+	jwks := NewJWKS(issuer.JWKSURL(), time.Hour)
+	jwks.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// first use fetches the key set
+	key, err := jwks.Key(ctx, testutil.RSAKeyID)
+	assert.NoError(t, err)
+	assert.IsType(t, &rsa.PublicKey{}, key)
+	assert.Equal(t, int64(1), issuer.Requests())
+
+	// cached keys are served without refetching
+	key, err = jwks.Key(ctx, testutil.ECKeyID)
+	assert.NoError(t, err)
+	assert.IsType(t, &ecdsa.PublicKey{}, key)
+	assert.Equal(t, int64(1), issuer.Requests())
+
+	// unknown key IDs do not refetch more than once per minRefreshInterval
+	_, err = jwks.Key(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, int64(1), issuer.Requests())
+
+	// after minRefreshInterval an unknown key ID triggers a refetch to pick up rotated keys
+	now = now.Add(minRefreshInterval)
+	_, err = jwks.Key(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, int64(2), issuer.Requests())
+
+	// stale key sets are refetched
+	now = now.Add(time.Hour)
+	_, err = jwks.Key(ctx, testutil.RSAKeyID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), issuer.Requests())
+}
+
+func TestJWKSKeyServesStaleOnError(t *testing.T) {
+	issuer := testutil.NewJWTIssuer(t)
+	var available atomic.Bool
+	available.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		http.Redirect(w, r, issuer.JWKSURL(), http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+
+	now := time.Now()
+	jwks := NewJWKS(server.URL, time.Hour)
+	jwks.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := jwks.Key(ctx, testutil.RSAKeyID)
+	assert.NoError(t, err)
+
+	available.Store(false)
+	now = now.Add(2 * time.Hour)
+	key, err := jwks.Key(ctx, testutil.RSAKeyID)
+	assert.NoError(t, err, "cached key should be served when refresh fails")
+	assert.NotNil(t, key)
+
+	_, err = jwks.Key(ctx, testutil.ECKeyID)
+	assert.NoError(t, err)
+
+	jwks = NewJWKS(server.URL, time.Hour)
+	_, err = jwks.Key(ctx, testutil.RSAKeyID)
+	assert.ErrorContains(t, err, "unexpected status 503")
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	issuer := testutil.NewJWTIssuer(t)
+	verifier := NewVerifier(NewJWKS(issuer.JWKSURL(), time.Hour), "https://issuer.test", "users-api", 30*time.Second)
+
+	now := time.Now()
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"sub":       "user-1",
+			"iss":       "https://issuer.test",
+			"aud":       "users-api",
+			"exp":       now.Add(time.Hour).Unix(),
+			"nbf":       now.Add(-time.Minute).Unix(),
+			"iat":       now.Add(-time.Minute).Unix(),
+			"scope":     "users:read users:write",
+			"roles":     []string{"Employee"},
+			"tenant_id": "tenant-a",
+		}
+		for key, value := range overrides {
+			if value == nil {
+				delete(c, key)
+				continue
+			}
+			c[key] = value
+		}
+		return c
+	}
+
+	tests := map[string]struct {
+		token           string
+		expectedSubject string
+		expectedErr     string
+	}{
+		"valid RS256": {
+			token:           issuer.Sign("RS256", "", claims(nil)),
+			expectedSubject: "user-1",
+		},
+		"valid ES256": {
+			token:           issuer.Sign("ES256", "", claims(nil)),
+			expectedSubject: "user-1",
+		},
+		"audience array": {
+			token:           issuer.Sign("RS256", "", claims(map[string]any{"aud": []string{"other", "users-api"}})),
+			expectedSubject: "user-1",
+		},
+		"expired within leeway": {
+			token:           issuer.Sign("RS256", "", claims(map[string]any{"exp": now.Add(-10 * time.Second).Unix()})),
+			expectedSubject: "user-1",
+		},
+		"expired": {
+			token:       issuer.Sign("RS256", "", claims(map[string]any{"exp": now.Add(-time.Minute).Unix()})),
+			expectedErr: "token expired",
+		},
+		"missing exp": {
+			token:       issuer.Sign("RS256", "", claims(map[string]any{"exp": nil})),
+			expectedErr: "missing exp claim",
+		},
+		"not yet valid": {
+			token:       issuer.Sign("RS256", "", claims(map[string]any{"nbf": now.Add(time.Minute).Unix()})),
+			expectedErr: "token not valid before",
+		},
+		"wrong issuer": {
+			token:       issuer.Sign("RS256", "", claims(map[string]any{"iss": "https://evil.test"})),
+			expectedErr: "unexpected issuer",
+		},
+		"wrong audience": {
+			token:       issuer.Sign("RS256", "", claims(map[string]any{"aud": "other"})),
+			expectedErr: "does not contain",
+		},
+		"unknown key": {
+			token:       issuer.Sign("RS256", "rotated-away", claims(nil)),
+			expectedErr: "unknown signing key",
+		},
+		"key type does not match alg": {
+			token:       issuer.Sign("RS256", testutil.ECKeyID, claims(nil)),
+			expectedErr: "non RSA key",
+		},
+		"tampered payload": {
+			token: func() string {
+				parts := strings.Split(issuer.Sign("RS256", "", claims(nil)), ".")
+				parts[1] = base64.RawURLEncoding.EncodeToString([]byte(testutil.ToJSONString(claims(map[string]any{"sub": "admin"}))))
+				return strings.Join(parts, ".")
+			}(),
+			expectedErr: "signature verification failed",
+		},
+		"alg none": {
+			token: func() string {
+				parts := strings.Split(issuer.Sign("RS256", "", claims(nil)), ".")
+				parts[0] = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"test-rsa"}`))
+				return parts[0] + "." + parts[1] + "."
+			}(),
+			expectedErr: `unsupported alg "none"`,
+		},
+		"malformed": {
+			token:       "not-a-token",
+			expectedErr: "malformed token",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := verifier.Verify(context.Background(), tc.token)
+
+			if tc.expectedErr != "" {
+				assert.True(t, errors.Is(err, ErrInvalidToken), "error should wrap ErrInvalidToken")
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedSubject, got.Subject)
+			assert.Equal(t, []string{"users:read", "users:write"}, got.Scopes)
+			assert.Equal(t, []string{"Employee"}, got.Roles)
+			assert.Equal(t, "tenant-a", got.String("tenant_id"))
+		})
+	}
+}
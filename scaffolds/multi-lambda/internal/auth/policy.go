@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// OwnerClaim is the claim compared against the owner of the target resource for ownership rules.
+const OwnerClaim = "user_id"
+
+var (
+	// ErrUnauthenticated is returned by Policy.Authorize when no caller is present in the context.
+	ErrUnauthenticated = errors.New("unauthenticated")
+
+	// ErrForbidden is returned by Policy.Authorize when the caller does not satisfy the policy.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// OwnerLookup resolves the OwnerClaim value of the owner of the resource identified by
+// resourceID. It returns an empty string if the resource does not exist.
+type OwnerLookup func(ctx context.Context, resourceID string) (string, error)
+
+// Policy declares what a caller needs to access a route.
+//
+// A caller is allowed when they hold every scope in Scopes and either hold one of Roles, or hold
+// one of OwnerRoles and own the target resource. The target resource is identified by the
+// OwnerParam path parameter. Its owner is resolved with Owner, or is the parameter value itself
+// if Owner is nil. A policy without Roles or OwnerRoles only checks scopes.
+type Policy struct {
+	Roles      []string
+	Scopes     []string
+	OwnerRoles []string
+	OwnerParam string
+	Owner      OwnerLookup
+}
+
+// Authorize checks the caller in ctx, see PrincipalFromContext, against the policy. resourceID is
+// the value of the OwnerParam path parameter of the current request, or empty if the route has
+// none.
+func (p Policy) Authorize(ctx context.Context, resourceID string) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	for _, scope := range p.Scopes {
+		if !slices.Contains(principal.Scopes, scope) {
+			return fmt.Errorf("missing scope %q: %w", scope, ErrForbidden)
+		}
+	}
+
+	if len(p.Roles) == 0 && len(p.OwnerRoles) == 0 {
+		return nil
+	}
+	if hasAny(principal.Roles, p.Roles) {
+		return nil
+	}
+	if !hasAny(principal.Roles, p.OwnerRoles) {
+		return fmt.Errorf("missing role: %w", ErrForbidden)
+	}
+
+	caller := principal.UserID
+	if caller == "" || resourceID == "" {
+		return fmt.Errorf("cannot check ownership of %q: %w", resourceID, ErrForbidden)
+	}
+
+	owner := resourceID
+	if p.Owner != nil {
+		var err error
+		if owner, err = p.Owner(ctx, resourceID); err != nil {
+			return fmt.Errorf("looking up owner of %q: %w", resourceID, err)
+		}
+	}
+	if owner != caller {
+		return fmt.Errorf("caller does not own %q: %w", resourceID, ErrForbidden)
+	}
+
+	return nil
+}
+
+// hasAny reports whether have contains at least one of want.
+func hasAny(have []string, want []string) bool {
+	for _, w := range want {
+		if slices.Contains(have, w) {
+			return true
+		}
+	}
+	return false
+}
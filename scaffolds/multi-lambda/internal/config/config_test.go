@@ -3,12 +3,19 @@ package config
 import (
 	"log/slog"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNewConfiguration(t *testing.T) {
+	secretsFile := filepath.Join(t.TempDir(), "secrets.json")
+	err := os.WriteFile(secretsFile, []byte(`{"test/db": {"username": "test_user", "password": "test_password"}}`), 0o600)
+	if err != nil {
+		t.Fatalf("writing secrets file: %v", err)
+	}
+
 	tests := map[string]struct {
 		envVars       map[string]string
 		expectedCfg   Configuration
@@ -24,19 +31,281 @@ func TestNewConfiguration(t *testing.T) {
 				"DATABASE_HOST":                   "localhost",
 				"DATABASE_PORT":                   "5432",
 				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"AUTH_ISSUER":                     "https://issuer.test",
+				"AUTH_AUDIENCE":                   "users-api",
+				"AUTH_JWKS_URL":                   "https://issuer.test/.well-known/jwks.json",
+			},
+			expectedCfg: Configuration{
+				Env:                   "development",
+				LogLevel:              slog.LevelInfo,
+				LogFormat:             "json",
+				LogDebugHeader:        "X-Debug-Log",
+				LogRedactKeys:         []string{"password", "secret", "token", "authorization", "cookie", "api_key", "first_name", "last_name"},
+				TracingExporter:       "none",
+				TracingServiceName:    "user-microservice",
+				ErrorReportingTimeout: 2,
+				MetricsNamespace:      "UserMicroservice",
+				DBName:                "test_db",
+				DBUser:                "test_user",
+				DBPassword:            "test_password",
+				DBHost:                "localhost",
+				DBPort:                "5432",
+				DBRetryDuration:       10,
+				DBSlowQueryThreshold:  200,
+				DBSSLMode:             "disable",
+				TenantClaim:           "tenant_id",
+				AuthIssuer:            "https://issuer.test",
+				AuthAudience:          "users-api",
+				AuthJWKSURL:           "https://issuer.test/.well-known/jwks.json",
+				AuthJWKSRefresh:       900,
+				AuthClockSkew:         30,
+				APIKeyHeader:          "X-API-Key",
+				APIKeyCacheTTL:        60,
+				RateLimitDefault:      "100/1m",
+				RateLimitIP:           "1000/1m",
+				CORSAllowedOrigins:    []string{"*"},
+				CORSAllowedMethods:    []string{"GET", "PUT", "POST", "DELETE"},
+				CORSAllowedHeaders:    []string{"Accept", "Authorization", "Content-Type"},
+				CORSMaxAge:            300,
+				SecurityHSTSMaxAge:    31536000,
+				SecurityCSP:           "default-src 'none'; frame-ancestors 'none'",
+				SecurityReferrer:      "no-referrer",
+				EncryptionKeyProvider: "file",
+				EncryptionKeyFile:     "keys.local.json",
+			},
+			expectedError: false,
+		},
+		"secret references resolved": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "secret://test/db#username",
+				"DATABASE_PASSWORD":               "secret://test/db#password",
+				"SECRETS_PROVIDER":                "file",
+				"SECRETS_FILE":                    secretsFile,
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"AUTH_ISSUER":                     "https://issuer.test",
+				"AUTH_AUDIENCE":                   "users-api",
+				"AUTH_JWKS_URL":                   "https://issuer.test/.well-known/jwks.json",
 			},
 			expectedCfg: Configuration{
-				Env:             "development",
-				LogLevel:        slog.LevelInfo,
-				DBName:          "test_db",
-				DBUser:          "test_user",
-				DBPassword:      "test_password",
-				DBHost:          "localhost",
-				DBPort:          "5432",
-				DBRetryDuration: 10,
+				Env:                   "development",
+				LogLevel:              slog.LevelInfo,
+				LogFormat:             "json",
+				LogDebugHeader:        "X-Debug-Log",
+				LogRedactKeys:         []string{"password", "secret", "token", "authorization", "cookie", "api_key", "first_name", "last_name"},
+				TracingExporter:       "none",
+				TracingServiceName:    "user-microservice",
+				ErrorReportingTimeout: 2,
+				MetricsNamespace:      "UserMicroservice",
+				DBName:                "test_db",
+				DBUser:                "test_user",
+				DBPassword:            "test_password",
+				DBHost:                "localhost",
+				DBPort:                "5432",
+				DBRetryDuration:       10,
+				DBSlowQueryThreshold:  200,
+				DBSSLMode:             "disable",
+				TenantClaim:           "tenant_id",
+				AuthIssuer:            "https://issuer.test",
+				AuthAudience:          "users-api",
+				AuthJWKSURL:           "https://issuer.test/.well-known/jwks.json",
+				AuthJWKSRefresh:       900,
+				AuthClockSkew:         30,
+				APIKeyHeader:          "X-API-Key",
+				APIKeyCacheTTL:        60,
+				RateLimitDefault:      "100/1m",
+				RateLimitIP:           "1000/1m",
+				CORSAllowedOrigins:    []string{"*"},
+				CORSAllowedMethods:    []string{"GET", "PUT", "POST", "DELETE"},
+				CORSAllowedHeaders:    []string{"Accept", "Authorization", "Content-Type"},
+				CORSMaxAge:            300,
+				SecurityHSTSMaxAge:    31536000,
+				SecurityCSP:           "default-src 'none'; frame-ancestors 'none'",
+				SecurityReferrer:      "no-referrer",
+				EncryptionKeyProvider: "file",
+				EncryptionKeyFile:     "keys.local.json",
 			},
 			expectedError: false,
 		},
+		"unknown secret": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_PASSWORD":               "secret://test/missing#password",
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"SECRETS_PROVIDER":                "file",
+				"SECRETS_FILE":                    secretsFile,
+			},
+			expectedCfg:   Configuration{},
+			expectedError: true,
+		},
+		"gateway authentication": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_PASSWORD":               "test_password",
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"AUTH_ISSUER":                     "https://issuer.test",
+				"AUTH_AUDIENCE":                   "users-api",
+				"AUTH_JWKS_URL":                   "https://issuer.test/.well-known/jwks.json",
+				"AUTH_GATEWAY":                    "true",
+				"API_KEY_HEADER":                  "X-Service-Key",
+				"API_KEY_CACHE_TTL_SECONDS":       "5",
+				"RATE_LIMIT_DEFAULT":              "50/1s",
+				"RATE_LIMIT_ROUTES":               "GET /lambda/user=10/1s,PUT /lambda/user/{ID}=5/1m",
+				"CORS_ALLOWED_ORIGINS":            "https://app.example.com,https://admin.example.com",
+				"CORS_ALLOW_CREDENTIALS":          "true",
+				"LOG_REDACT_KEYS":                 "password,email",
+				"LOG_REDACT_ALLOW":                "last_name",
+			},
+			expectedCfg: Configuration{
+				Env:                   "development",
+				LogLevel:              slog.LevelInfo,
+				LogFormat:             "json",
+				LogDebugHeader:        "X-Debug-Log",
+				LogRedactKeys:         []string{"password", "email"},
+				LogRedactAllow:        []string{"last_name"},
+				TracingExporter:       "none",
+				TracingServiceName:    "user-microservice",
+				ErrorReportingTimeout: 2,
+				MetricsNamespace:      "UserMicroservice",
+				DBName:                "test_db",
+				DBUser:                "test_user",
+				DBPassword:            "test_password",
+				DBHost:                "localhost",
+				DBPort:                "5432",
+				DBRetryDuration:       10,
+				DBSlowQueryThreshold:  200,
+				DBSSLMode:             "disable",
+				TenantClaim:           "tenant_id",
+				AuthIssuer:            "https://issuer.test",
+				AuthAudience:          "users-api",
+				AuthJWKSURL:           "https://issuer.test/.well-known/jwks.json",
+				AuthJWKSRefresh:       900,
+				AuthClockSkew:         30,
+				AuthGateway:           true,
+				APIKeyHeader:          "X-Service-Key",
+				APIKeyCacheTTL:        5,
+				RateLimitDefault:      "50/1s",
+				RateLimitIP:           "1000/1m",
+				RateLimitRoutes: map[string]string{
+					"GET /lambda/user":      "10/1s",
+					"PUT /lambda/user/{ID}": "5/1m",
+				},
+				CORSAllowedOrigins:    []string{"https://app.example.com", "https://admin.example.com"},
+				CORSAllowCredentials:  true,
+				CORSAllowedMethods:    []string{"GET", "PUT", "POST", "DELETE"},
+				CORSAllowedHeaders:    []string{"Accept", "Authorization", "Content-Type"},
+				CORSMaxAge:            300,
+				SecurityHSTSMaxAge:    31536000,
+				SecurityCSP:           "default-src 'none'; frame-ancestors 'none'",
+				SecurityReferrer:      "no-referrer",
+				EncryptionKeyProvider: "file",
+				EncryptionKeyFile:     "keys.local.json",
+			},
+			expectedError: false,
+		},
+		"iam authentication": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_IAM_AUTH":               "true",
+				"DATABASE_HOST":                   "proxy.example.com",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"DATABASE_SSL_MODE":               "verify-full",
+				"AUTH_ISSUER":                     "https://issuer.test",
+				"AUTH_AUDIENCE":                   "users-api",
+				"AUTH_JWKS_URL":                   "https://issuer.test/.well-known/jwks.json",
+			},
+			expectedCfg: Configuration{
+				Env:                   "development",
+				LogLevel:              slog.LevelInfo,
+				LogFormat:             "json",
+				LogDebugHeader:        "X-Debug-Log",
+				LogRedactKeys:         []string{"password", "secret", "token", "authorization", "cookie", "api_key", "first_name", "last_name"},
+				TracingExporter:       "none",
+				TracingServiceName:    "user-microservice",
+				ErrorReportingTimeout: 2,
+				MetricsNamespace:      "UserMicroservice",
+				DBName:                "test_db",
+				DBUser:                "test_user",
+				DBHost:                "proxy.example.com",
+				DBPort:                "5432",
+				DBRetryDuration:       10,
+				DBSlowQueryThreshold:  200,
+				DBSSLMode:             "verify-full",
+				DBIAMAuth:             true,
+				TenantClaim:           "tenant_id",
+				AuthIssuer:            "https://issuer.test",
+				AuthAudience:          "users-api",
+				AuthJWKSURL:           "https://issuer.test/.well-known/jwks.json",
+				AuthJWKSRefresh:       900,
+				AuthClockSkew:         30,
+				APIKeyHeader:          "X-API-Key",
+				APIKeyCacheTTL:        60,
+				RateLimitDefault:      "100/1m",
+				RateLimitIP:           "1000/1m",
+				CORSAllowedOrigins:    []string{"*"},
+				CORSAllowedMethods:    []string{"GET", "PUT", "POST", "DELETE"},
+				CORSAllowedHeaders:    []string{"Accept", "Authorization", "Content-Type"},
+				CORSMaxAge:            300,
+				SecurityHSTSMaxAge:    31536000,
+				SecurityCSP:           "default-src 'none'; frame-ancestors 'none'",
+				SecurityReferrer:      "no-referrer",
+				EncryptionKeyProvider: "file",
+				EncryptionKeyFile:     "keys.local.json",
+			},
+			expectedError: false,
+		},
+		"missing password": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"AUTH_ISSUER":                     "https://issuer.test",
+				"AUTH_AUDIENCE":                   "users-api",
+				"AUTH_JWKS_URL":                   "https://issuer.test/.well-known/jwks.json",
+			},
+			expectedCfg:   Configuration{},
+			expectedError: true,
+		},
+		"credentials with any origin": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_PASSWORD":               "test_password",
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"AUTH_ISSUER":                     "https://issuer.test",
+				"AUTH_AUDIENCE":                   "users-api",
+				"AUTH_JWKS_URL":                   "https://issuer.test/.well-known/jwks.json",
+				"CORS_ALLOW_CREDENTIALS":          "true",
+			},
+			expectedCfg:   Configuration{},
+			expectedError: true,
+		},
 		"missing required env": {
 			envVars: map[string]string{
 				"DATABASE_NAME":                   "test_db",
@@ -87,6 +356,8 @@ func TestNewConfiguration(t *testing.T) {
 				assert.Equal(t, tc.expectedCfg, cfg)
 			} else {
 				assert.NoError(t, err)
+				assert.NotNil(t, cfg.DBCredentials)
+				cfg.DBCredentials = nil
 				assert.Equal(t, tc.expectedCfg, cfg)
 			}
 		})
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAWSStandIn starts a server that answers AWS JSON protocol requests for the operation named
+// target. respond maps the decoded request body to a status and response body.
+func newAWSStandIn(t *testing.T, target string, respond func(body map[string]any) (int, any)) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != target {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		status, response := respond(body)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestSecretsManagerProvider(t *testing.T) {
+	server := newAWSStandIn(t, "secretsmanager.GetSecretValue", func(body map[string]any) (int, any) {
+		switch body["SecretId"] {
+		case "user-microservice/db":
+			return http.StatusOK, map[string]any{
+				"Name":         "user-microservice/db",
+				"SecretString": `{"password": "db-password"}`,
+			}
+		case "binary":
+			return http.StatusOK, map[string]any{"Name": "binary", "SecretBinary": "AAEC"}
+		default:
+			return http.StatusBadRequest, map[string]any{
+				"__type":  "ResourceNotFoundException",
+				"message": "Secrets Manager can't find the specified secret.",
+			}
+		}
+	})
+
+	provider := NewSecretsManagerProvider(secretsmanager.New(secretsmanager.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	}))
+
+	tests := map[string]struct {
+		name             string
+		expected         string
+		expectedErr      bool
+		expectedNotFound bool
+	}{
+		"secret string": {
+			name:     "user-microservice/db",
+			expected: `{"password": "db-password"}`,
+		},
+		"binary secret": {
+			name:        "binary",
+			expectedErr: true,
+		},
+		"unknown secret": {
+			name:             "missing",
+			expectedErr:      true,
+			expectedNotFound: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := provider.GetSecret(context.Background(), tc.name)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedNotFound, errors.Is(err, ErrSecretNotFound), "wrong error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestSSMProvider(t *testing.T) {
+	server := newAWSStandIn(t, "AmazonSSM.GetParameter", func(body map[string]any) (int, any) {
+		if body["Name"] != "/user-microservice/db-password" {
+			return http.StatusBadRequest, map[string]any{"__type": "ParameterNotFound"}
+		}
+		if body["WithDecryption"] != true {
+			return http.StatusOK, map[string]any{"Parameter": map[string]any{"Value": "encrypted"}}
+		}
+		return http.StatusOK, map[string]any{"Parameter": map[string]any{
+			"Name":  "/user-microservice/db-password",
+			"Type":  "SecureString",
+			"Value": "db-password",
+		}}
+	})
+
+	provider := NewSSMProvider(ssm.New(ssm.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	}))
+
+	got, err := provider.GetSecret(context.Background(), "/user-microservice/db-password")
+	assert.NoError(t, err)
+	assert.Equal(t, "db-password", got)
+
+	_, err = provider.GetSecret(context.Background(), "/user-microservice/missing")
+	assert.True(t, errors.Is(err, ErrSecretNotFound), "expected %v, got %v", ErrSecretNotFound, err)
+}
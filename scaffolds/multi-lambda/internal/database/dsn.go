@@ -0,0 +1,49 @@
+package database
+
+import (
+	"strings"
+)
+
+// DSN describes a Postgres connection. SSLMode is one of the libpq modes, e.g. `disable`,
+// `require` or `verify-full`; SSLRootCert is the CA bundle server certificates are verified
+// against in the `verify-ca` and `verify-full` modes.
+type DSN struct {
+	Host        string
+	Port        string
+	User        string
+	Password    string
+	Name        string
+	SSLMode     string
+	SSLRootCert string
+}
+
+// ConnectionString returns the connection string for lib/pq. Values are quoted, so they may
+// contain spaces and quotes. Empty optional settings are left out.
+func (d DSN) ConnectionString() string {
+	settings := []struct{ key, value string }{
+		{"host", d.Host},
+		{"port", d.Port},
+		{"user", d.User},
+		{"password", d.Password},
+		{"dbname", d.Name},
+		{"sslmode", d.SSLMode},
+		{"sslrootcert", d.SSLRootCert},
+	}
+
+	parts := make([]string, 0, len(settings))
+	for _, s := range settings {
+		if s.value == "" {
+			continue
+		}
+		parts = append(parts, s.key+"="+quoteDSNValue(s.value))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// quoteDSNValue quotes value as a libpq connection string value.
+func quoteDSNValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
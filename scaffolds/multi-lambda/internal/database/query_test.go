@@ -0,0 +1,185 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// queryConn answers every query with rows rows, sleeping for delay first, and fails if err is set.
+type queryConn struct {
+	fakeConn
+	rows  int
+	delay time.Duration
+	err   error
+}
+
+func (c *queryConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	time.Sleep(c.delay)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &queryRows{remaining: c.rows}, nil
+}
+
+func (c *queryConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	time.Sleep(c.delay)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return driver.RowsAffected(c.rows), nil
+}
+
+func (c *queryConn) Close() error {
+	return nil
+}
+
+type queryRows struct {
+	remaining int
+}
+
+func (r *queryRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *queryRows) Close() error {
+	return nil
+}
+
+func (r *queryRows) Next(dest []driver.Value) error {
+	if r.remaining == 0 {
+		return io.EOF
+	}
+	r.remaining--
+	dest[0] = int64(r.remaining)
+	return nil
+}
+
+func TestQueryLog(t *testing.T) {
+	tests := map[string]struct {
+		conn            *queryConn
+		exec            bool
+		level           slog.Level
+		expectedLines   []string
+		expectedMetrics map[string]float64
+	}{
+		"fast query": {
+			conn:            &queryConn{rows: 3},
+			level:           slog.LevelDebug,
+			expectedLines:   []string{"level=DEBUG", `msg=Query`, `statement="SELECT id FROM users WHERE tenant_id = $1 AND role = ?"`, "rows=3"},
+			expectedMetrics: map[string]float64{"DatabaseQueries": 1},
+		},
+		"fast query not logged above debug": {
+			conn:            &queryConn{rows: 3},
+			level:           slog.LevelInfo,
+			expectedMetrics: map[string]float64{"DatabaseQueries": 1},
+		},
+		"slow query": {
+			conn:            &queryConn{rows: 2, delay: 20 * time.Millisecond},
+			level:           slog.LevelInfo,
+			expectedLines:   []string{"level=WARN", `msg="Slow query"`, "rows=2", "duration_ms="},
+			expectedMetrics: map[string]float64{"DatabaseQueries": 1, "SlowQueries": 1},
+		},
+		"failed exec": {
+			conn:            &queryConn{err: errors.New("deadlock detected")},
+			exec:            true,
+			level:           slog.LevelDebug,
+			expectedLines:   []string{"level=DEBUG", `err="deadlock detected"`, "rows=0"},
+			expectedMetrics: map[string]float64{"DatabaseQueries": 1, "DatabaseQueryErrors": 1},
+		},
+		"exec": {
+			conn:            &queryConn{rows: 5},
+			exec:            true,
+			level:           slog.LevelDebug,
+			expectedLines:   []string{"rows=5"},
+			expectedMetrics: map[string]float64{"DatabaseQueries": 1},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: tc.level}))
+			recorder := metrics.NewRecorder()
+			ctx := metrics.NewContext(logging.WithLogger(context.Background(), logger), recorder)
+
+			c := newConnector(DSN{}, staticCredentials{}, logger)
+			c.open = func(context.Context, string) (driver.Conn, error) { return tc.conn, nil }
+			c.queries = &queryLog{slowThreshold: 10 * time.Millisecond}
+			db := sql.OpenDB(c)
+			defer db.Close()
+
+			query := "SELECT id\n  FROM users -- by role\n WHERE tenant_id = $1 AND role = 'Admin'"
+			if tc.exec {
+				_, _ = db.ExecContext(ctx, query, "tenant-a")
+			} else {
+				rows, err := db.QueryContext(ctx, query, "tenant-a")
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				for rows.Next() {
+				}
+				rows.Close()
+			}
+
+			for _, line := range tc.expectedLines {
+				assert.Contains(t, out.String(), line)
+			}
+			if len(tc.expectedLines) == 0 {
+				assert.Empty(t, out.String())
+			}
+			assert.NotContains(t, out.String(), "Admin")
+
+			for _, metric := range []string{"DatabaseQueries", "DatabaseQueryErrors", "SlowQueries"} {
+				value, _ := recorder.Value(metric)
+				assert.Equal(t, tc.expectedMetrics[metric], value, metric)
+			}
+			_, ok := recorder.Value("DatabaseQueryDuration")
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		expected string
+	}{
+		"placeholders kept": {
+			query:    "SELECT id FROM users WHERE id = $1 AND tenant_id = $2",
+			expected: "SELECT id FROM users WHERE id = $1 AND tenant_id = $2",
+		},
+		"whitespace collapsed": {
+			query:    "\n\tSELECT id,\n\t       first_name\n\t  FROM users\n",
+			expected: "SELECT id, first_name FROM users",
+		},
+		"literals replaced": {
+			query:    "SELECT id FROM users WHERE role = 'O''Brien' AND user_id > 1001 LIMIT 2.5",
+			expected: "SELECT id FROM users WHERE role = ? AND user_id > ? LIMIT ?",
+		},
+		"identifiers with digits kept": {
+			query:    "SELECT sha256_hash FROM table2",
+			expected: "SELECT sha256_hash FROM table2",
+		},
+		"comments removed": {
+			query:    "SELECT /* list */ id -- first\nFROM users",
+			expected: "SELECT id FROM users",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Normalize(tc.query))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package encryption
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("writing keyfile: %v", err)
+		}
+		return path
+	}
+
+	tests := map[string]struct {
+		path        string
+		expectedErr bool
+	}{
+		"valid keyfile": {
+			path: write("valid.json", `{"current": "key-2", "keys": {
+				"key-1": "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=",
+				"key-2": "AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI="
+			}}`),
+		},
+		"unknown current key": {
+			path:        write("unknown.json", `{"current": "key-3", "keys": {"key-1": "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE="}}`),
+			expectedErr: true,
+		},
+		"short key": {
+			path:        write("short.json", `{"current": "key-1", "keys": {"key-1": "AQEB"}}`),
+			expectedErr: true,
+		},
+		"invalid json": {
+			path:        write("invalid.json", `{`),
+			expectedErr: true,
+		},
+		"missing file": {
+			path:        filepath.Join(dir, "missing.json"),
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			keys, err := LoadKeyFile(tc.path)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			key, err := keys.GenerateDataKey(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "key-2", key.KeyID)
+			assert.Len(t, key.Plaintext, dataKeySize)
+
+			unwrapped, err := keys.DecryptDataKey(context.Background(), key.KeyID, key.Wrapped)
+			assert.NoError(t, err)
+			assert.Equal(t, key.Plaintext, unwrapped)
+
+			_, err = keys.DecryptDataKey(context.Background(), "key-1", key.Wrapped)
+			assert.Error(t, err)
+		})
+	}
+}
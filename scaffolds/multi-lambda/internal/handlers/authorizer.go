@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+)
+
+// errUnauthorized is the error API Gateway maps to a 401 response when returned by an authorizer.
+var errUnauthorized = errors.New("Unauthorized")
+
+type tokenVerifier interface {
+	Verify(ctx context.Context, token string) (auth.Claims, error)
+}
+
+type apiKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key string) (auth.Principal, error)
+}
+
+// AuthorizerSettings names the headers and claims HandleAuthorizer reads.
+type AuthorizerSettings struct {
+	// APIKeyHeader is the header carrying an API key.
+	APIKeyHeader string
+	// TenantClaim is the claim the tenant is read from for bearer tokens. Callers cannot choose
+	// their tenant with a header.
+	TenantClaim string
+}
+
+// HandleAuthorizer returns an AuthorizerFunc that authenticates API Gateway requests with either
+// a bearer token or an API key. Authenticated callers are allowed to invoke the requested method
+// and their principal is passed on as authorizer context, see auth.PrincipalFromAuthorizer. All
+// other requests are answered with a 401 by API Gateway.
+func HandleAuthorizer(verifier tokenVerifier, keys apiKeyValidator, settings AuthorizerSettings) AuthorizerFunc {
+	return func(ctx context.Context, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+		logger := logging.FromContext(ctx)
+		var principal auth.Principal
+		var err error
+
+		authorization := authorizerHeader(request, "Authorization")
+		apiKey := authorizerHeader(request, settings.APIKeyHeader)
+		switch {
+		case authorization != "":
+			principal, err = bearerPrincipal(ctx, verifier, authorization, settings)
+		case apiKey != "":
+			principal, err = keys.ValidateAPIKey(ctx, apiKey)
+		default:
+			err = errors.New("no credentials")
+		}
+		if err != nil {
+			logger.WarnContext(ctx, "Request denied", "err", err, "method", request.HTTPMethod, "path", request.Path)
+			return events.APIGatewayCustomAuthorizerResponse{}, errUnauthorized
+		}
+
+		logger.DebugContext(ctx, "Request allowed", "subject", principal.Subject, "method", principal.Method, "tenant", principal.Tenant)
+		return events.APIGatewayCustomAuthorizerResponse{
+			PrincipalID: principal.Subject,
+			PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+				Version: "2012-10-17",
+				Statement: []events.IAMPolicyStatement{
+					{
+						Action:   []string{"execute-api:Invoke"},
+						Effect:   "Allow",
+						Resource: []string{request.MethodArn},
+					},
+				},
+			},
+			Context: principal.AuthorizerContext(),
+		}, nil
+	}
+}
+
+// bearerPrincipal verifies the bearer token in authorization and maps its claims to a principal.
+// Tokens without a tenant claim are rejected.
+func bearerPrincipal(
+	ctx context.Context,
+	verifier tokenVerifier,
+	authorization string,
+	settings AuthorizerSettings,
+) (auth.Principal, error) {
+	token, ok := auth.BearerToken(authorization)
+	if !ok {
+		return auth.Principal{}, errors.New("malformed authorization header")
+	}
+
+	claims, err := verifier.Verify(ctx, token)
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	if claims.Subject == "" {
+		return auth.Principal{}, errors.New("token has no subject")
+	}
+
+	tenantID := claims.String(settings.TenantClaim)
+	if tenantID == "" {
+		return auth.Principal{}, fmt.Errorf("token has no %q claim", settings.TenantClaim)
+	}
+
+	return auth.Principal{
+		Subject: claims.Subject,
+		UserID:  claims.String(auth.OwnerClaim),
+		Tenant:  tenantID,
+		Roles:   claims.Roles,
+		Scopes:  claims.Scopes,
+		Method:  auth.MethodBearer,
+	}, nil
+}
+
+// authorizerHeader returns the first value of the named header, matching the name
+// case-insensitively as API Gateway does not normalize header casing.
+func authorizerHeader(request events.APIGatewayCustomAuthorizerRequestTypeRequest, name string) string {
+	if name == "" {
+		return ""
+	}
+	for key, value := range request.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	for key, values := range request.MultiValueHeaders {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
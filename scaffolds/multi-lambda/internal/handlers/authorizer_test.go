@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubAPIKeys map[string]auth.Principal
+
+func (s stubAPIKeys) ValidateAPIKey(_ context.Context, key string) (auth.Principal, error) {
+	principal, ok := s[key]
+	if !ok {
+		return auth.Principal{}, auth.ErrInvalidAPIKey
+	}
+	return principal, nil
+}
+
+func TestHandleAuthorizer(t *testing.T) {
+	issuer := testutil.NewJWTIssuer(t)
+	verifier := auth.NewVerifier(auth.NewJWKS(issuer.JWKSURL(), time.Hour), "https://issuer.test", "users-api", 0)
+	keys := stubAPIKeys{
+		"key-reporting": {
+			Subject: "svc-reporting",
+			Tenant:  "tenant-b",
+			Roles:   []string{"Employee"},
+			Scopes:  []string{"users:read"},
+			Method:  auth.MethodAPIKey,
+		},
+	}
+
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"sub":       "user-1",
+			"iss":       "https://issuer.test",
+			"aud":       "users-api",
+			"exp":       time.Now().Add(time.Hour).Unix(),
+			"scope":     "users:read",
+			"roles":     []string{"Customer"},
+			"user_id":   1001,
+			"tenant_id": "tenant-a",
+		}
+		for key, value := range overrides {
+			if value == nil {
+				delete(c, key)
+				continue
+			}
+			c[key] = value
+		}
+		return c
+	}
+
+	methodArn := "arn:aws:execute-api:us-east-1:123456789012:abcdef1234/dev/GET/lambda/user"
+	allow := func(principal auth.Principal) events.APIGatewayCustomAuthorizerResponse {
+		return events.APIGatewayCustomAuthorizerResponse{
+			PrincipalID: principal.Subject,
+			PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+				Version: "2012-10-17",
+				Statement: []events.IAMPolicyStatement{
+					{
+						Action:   []string{"execute-api:Invoke"},
+						Effect:   "Allow",
+						Resource: []string{methodArn},
+					},
+				},
+			},
+			Context: principal.AuthorizerContext(),
+		}
+	}
+	customer := auth.Principal{
+		Subject: "user-1",
+		UserID:  "1001",
+		Tenant:  "tenant-a",
+		Roles:   []string{"Customer"},
+		Scopes:  []string{"users:read"},
+		Method:  auth.MethodBearer,
+	}
+
+	tests := map[string]struct {
+		settings         AuthorizerSettings
+		headers          map[string]string
+		expectedResponse events.APIGatewayCustomAuthorizerResponse
+		expectedError    error
+	}{
+		"bearer token, tenant from claim": {
+			settings: AuthorizerSettings{APIKeyHeader: "X-API-Key", TenantClaim: "tenant_id"},
+			headers: map[string]string{
+				"authorization": "Bearer " + issuer.Sign("RS256", "", claims(nil)),
+				"x-tenant-id":   "tenant-b",
+			},
+			expectedResponse: allow(customer),
+		},
+		"bearer token, ES256": {
+			settings: AuthorizerSettings{APIKeyHeader: "X-API-Key", TenantClaim: "tenant_id"},
+			headers: map[string]string{
+				"Authorization": "Bearer " + issuer.Sign("ES256", "", claims(nil)),
+			},
+			expectedResponse: allow(customer),
+		},
+		"bearer token without tenant claim, header ignored": {
+			settings: AuthorizerSettings{APIKeyHeader: "X-API-Key", TenantClaim: "tenant_id"},
+			headers: map[string]string{
+				"Authorization": "Bearer " + issuer.Sign("RS256", "", claims(map[string]any{"tenant_id": nil})),
+				"X-Tenant-ID":   "tenant-a",
+			},
+			expectedError: errUnauthorized,
+		},
+		"api key": {
+			settings: AuthorizerSettings{APIKeyHeader: "X-API-Key", TenantClaim: "tenant_id"},
+			headers: map[string]string{
+				"x-api-key":   "key-reporting",
+				"x-tenant-id": "tenant-a",
+			},
+			expectedResponse: allow(auth.Principal{
+				Subject: "svc-reporting",
+				Tenant:  "tenant-b",
+				Roles:   []string{"Employee"},
+				Scopes:  []string{"users:read"},
+				Method:  auth.MethodAPIKey,
+			}),
+		},
+		"invalid token": {
+			settings: AuthorizerSettings{APIKeyHeader: "X-API-Key"},
+			headers: map[string]string{
+				"authorization": "Bearer " + issuer.Sign("RS256", "", claims(map[string]any{"aud": "other"})),
+			},
+			expectedError: errUnauthorized,
+		},
+		"token without subject": {
+			settings: AuthorizerSettings{APIKeyHeader: "X-API-Key"},
+			headers: map[string]string{
+				"authorization": "Bearer " + issuer.Sign("RS256", "", claims(map[string]any{"sub": nil})),
+			},
+			expectedError: errUnauthorized,
+		},
+		"wrong scheme": {
+			settings:      AuthorizerSettings{APIKeyHeader: "X-API-Key"},
+			headers:       map[string]string{"authorization": "Basic dXNlcjpwYXNz"},
+			expectedError: errUnauthorized,
+		},
+		"invalid api key": {
+			settings:      AuthorizerSettings{APIKeyHeader: "X-API-Key"},
+			headers:       map[string]string{"x-api-key": "key-unknown"},
+			expectedError: errUnauthorized,
+		},
+		"no credentials": {
+			settings:      AuthorizerSettings{APIKeyHeader: "X-API-Key"},
+			headers:       map[string]string{"x-tenant-id": "tenant-a"},
+			expectedError: errUnauthorized,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := HandleAuthorizer(verifier, keys, tc.settings)
+
+			got, err := handler(context.Background(), events.APIGatewayCustomAuthorizerRequestTypeRequest{
+				Type:       "REQUEST",
+				MethodArn:  methodArn,
+				HTTPMethod: "GET",
+				Path:       "/lambda/user",
+				Headers:    tc.headers,
+			})
+
+			assert.Equal(t, tc.expectedError, err, "Error expectations not met")
+			assert.Equal(t, tc.expectedResponse, got, "Wrong response")
+		})
+	}
+}
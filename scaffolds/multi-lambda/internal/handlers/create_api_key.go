@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
+)
+
+type apiKeyCreator interface {
+	CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, string, error)
+}
+
+// HandleCreateAPIKey returns a HandlerFunc that handles POST requests to create an API key. It
+// decodes and validates the request body, creates the key in the database, and returns it in the
+// response together with the plain key, which is not returned again.
+func HandleCreateAPIKey(service apiKeyCreator) HandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		logger := logging.FromContext(ctx)
+		// get and validate body as object
+		keyIn, problems, err := decodeValidateBody[inputAPIKey, models.APIKey](request.Body)
+		if err != nil {
+			switch {
+			case len(problems) > 0:
+				logger.ErrorContext(ctx, "Problems validating input", "error", err, "problems", problems)
+				return encodeResponse(logger, http.StatusBadRequest, responseErr{
+					ValidationErrors: problems,
+				})
+			default:
+				logger.ErrorContext(ctx, "BodyParser error", "error", err)
+				return encodeResponse(logger, http.StatusBadRequest, responseErr{
+					Error: "missing values or malformed body",
+				})
+			}
+		}
+
+		// create object in database
+		key, plain, err := service.CreateAPIKey(ctx, keyIn)
+		if err != nil {
+			logger.ErrorContext(ctx, "error creating object in database", "error", err)
+			return encodeResponse(logger, http.StatusInternalServerError, responseErr{
+				Error: "Error creating object",
+			})
+		}
+
+		// return response
+		return encodeResponse(logger, http.StatusCreated, responseCreatedAPIKey{
+			APIKey: mapAPIKeyOutput(key),
+			Key:    plain,
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	serviceMock "github.com/captechconsulting/go-microservice-templates/lambda/internal/handlers/mock"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleCreateAPIKey(t *testing.T) {
+	mockService := new(serviceMock.MockApiKeyCreator)
+	handler := HandleCreateAPIKey(mockService)
+
+	expiresAt := time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC)
+	keyIn := models.APIKey{Owner: "billing-batch", Roles: []string{"Employee"}, Scopes: []string{"users:read"}, ExpiresAt: expiresAt}
+	keyOut := keyIn
+	keyOut.ID = 1
+	keyOut.Prefix = "0a1b2c3d"
+	keyOut.CreatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		mockCalled       bool
+		mockOutput       []any
+		request          events.APIGatewayProxyRequest
+		expectedResponse events.APIGatewayProxyResponse
+		expectedError    error
+	}{
+		"valid request, api key created": {
+			mockCalled: true,
+			mockOutput: []any{keyOut, "ak_0a1b2c3d_secret", nil},
+			request: events.APIGatewayProxyRequest{
+				Body: `{"owner":"billing-batch","roles":["Employee"],"scopes":["users:read"],"expires_at":"2999-01-01T00:00:00Z"}`,
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusCreated,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body: testutil.ToJSONString(responseCreatedAPIKey{
+					APIKey: mapAPIKeyOutput(keyOut),
+					Key:    "ak_0a1b2c3d_secret",
+				}),
+			},
+			expectedError: nil,
+		},
+		"invalid request body": {
+			mockCalled: false,
+			request: events.APIGatewayProxyRequest{
+				Body: `{"roles":["Employee"],"scopes":["users read"],"expires_at":"2000-01-01T00:00:00Z"}`,
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body: testutil.ToJSONString(responseErr{
+					ValidationErrors: []problem{
+						{
+							Name:        "owner",
+							Description: "must not be blank",
+						},
+						{
+							Name:        "scopes",
+							Description: "must not be blank or contain whitespace",
+						},
+						{
+							Name:        "expires_at",
+							Description: "must be in the future",
+						},
+					},
+				}),
+			},
+			expectedError: nil,
+		},
+		"malformed request body": {
+			mockCalled: false,
+			request: events.APIGatewayProxyRequest{
+				Body: `{"owner":`,
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseErr{Error: "missing values or malformed body"}),
+			},
+			expectedError: nil,
+		},
+		"error creating api key": {
+			mockCalled: true,
+			mockOutput: []any{models.APIKey{}, "", errors.New("creation error")},
+			request: events.APIGatewayProxyRequest{
+				Body: `{"owner":"billing-batch","roles":["Employee"],"scopes":["users:read"],"expires_at":"2999-01-01T00:00:00Z"}`,
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseErr{Error: "Error creating object"}),
+			},
+			expectedError: nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.mockCalled {
+				mockService.
+					On("CreateAPIKey", ctx, keyIn).
+					Return(tc.mockOutput...).
+					Once()
+			}
+
+			got, err := handler(ctx, tc.request)
+
+			assert.Equal(t, tc.expectedError, err, "Error expectations not met")
+			assert.Equal(t, tc.expectedResponse, got, "Wrong response body")
+
+			if tc.mockCalled {
+				mockService.AssertExpectations(t)
+			} else {
+				mockService.AssertNotCalled(t, "CreateAPIKey")
+			}
+		})
+	}
+}
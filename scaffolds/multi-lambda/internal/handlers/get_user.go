@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/services"
+)
+
+type userGetter interface {
+	GetUser(ctx context.Context, ID int) (models.User, error)
+}
+
+// HandleGetUser returns a HandlerFunc that handles GET requests for a single user. It retrieves
+// the user ID from the path parameters, gets the user from the database, and returns it in the
+// response.
+func HandleGetUser(service userGetter) HandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		logger := logging.FromContext(ctx)
+		// get and validate ID
+		idString := request.PathParameters["ID"]
+		ID, err := strconv.Atoi(idString)
+		if err != nil {
+			logger.ErrorContext(ctx, "error getting ID", "error", err)
+			return encodeResponse(logger, http.StatusBadRequest, responseErr{
+				Error: "Not a valid ID",
+			})
+		}
+
+		// get object from database
+		user, err := service.GetUser(ctx, ID)
+		if err != nil {
+			if errors.Is(err, services.ErrNotFound) {
+				return encodeResponse(logger, http.StatusNotFound, responseErr{
+					Error: "User not found",
+				})
+			}
+
+			logger.ErrorContext(ctx, "error getting object from database", "error", err)
+			return encodeResponse(logger, http.StatusInternalServerError, responseErr{
+				Error: "Error retrieving data",
+			})
+		}
+
+		// return response
+		userOut := mapOutput(user)
+		return encodeResponse(logger, http.StatusOK, responseUser{
+			User: userOut,
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	serviceMock "github.com/captechconsulting/go-microservice-templates/lambda/internal/handlers/mock"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/services"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleGetUser(t *testing.T) {
+	mockService := new(serviceMock.MockUserGetter)
+	handler := HandleGetUser(mockService)
+
+	user := models.User{ID: 1, FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
+	userOut := mapOutput(user)
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		mockCalled       bool
+		mockOutput       []any
+		request          events.APIGatewayProxyRequest
+		expectedResponse events.APIGatewayProxyResponse
+		expectedError    error
+	}{
+		"valid request, user returned": {
+			mockCalled: true,
+			mockOutput: []any{user, nil},
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ID": "1"},
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseUser{User: userOut}),
+			},
+			expectedError: nil,
+		},
+		"invalid ID": {
+			mockCalled: false,
+			mockOutput: nil,
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ID": "test"},
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseErr{Error: "Not a valid ID"}),
+			},
+			expectedError: nil,
+		},
+		"user not found": {
+			mockCalled: true,
+			mockOutput: []any{models.User{}, fmt.Errorf("[in services.GetUser] user 1: %w", services.ErrNotFound)},
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ID": "1"},
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseErr{Error: "User not found"}),
+			},
+			expectedError: nil,
+		},
+		"error getting user": {
+			mockCalled: true,
+			mockOutput: []any{models.User{}, errors.New("test")},
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ID": "1"},
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseErr{Error: "Error retrieving data"}),
+			},
+			expectedError: nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.mockCalled {
+				mockService.
+					On("GetUser", ctx, 1).
+					Return(tc.mockOutput...).
+					Once()
+			}
+
+			got, err := handler(ctx, tc.request)
+
+			assert.Equal(t, tc.expectedError, err, "Error expectations not met")
+			assert.Equal(t, tc.expectedResponse, got, "Wrong response body")
+
+			if tc.mockCalled {
+				mockService.AssertExpectations(t)
+			} else {
+				mockService.AssertNotCalled(t, "GetUser")
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	serviceMock "github.com/captechconsulting/go-microservice-templates/lambda/internal/handlers/mock"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleListAPIKeys(t *testing.T) {
+	mockService := new(serviceMock.MockApiKeyLister)
+	handler := HandleListAPIKeys(mockService)
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := []models.APIKey{
+		{ID: 1, Prefix: "0a1b2c3d", Owner: "billing-batch", Scopes: []string{"users:read"}, CreatedAt: createdAt},
+		{ID: 2, Prefix: "4e5f6a7b", Owner: "reporting", Scopes: []string{"users:read"}, RevokedAt: createdAt.Add(time.Hour), CreatedAt: createdAt},
+	}
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		mockOutput       []any
+		expectedResponse events.APIGatewayProxyResponse
+		expectedError    error
+	}{
+		"api keys returned": {
+			mockOutput: []any{keys, nil},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseAPIKeys{APIKeys: mapMultipleAPIKeyOutput(keys)}),
+			},
+			expectedError: nil,
+		},
+		"no api keys found": {
+			mockOutput: []any{[]models.APIKey{}, nil},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseAPIKeys{APIKeys: []outputAPIKey{}}),
+			},
+			expectedError: nil,
+		},
+		"internal server error": {
+			mockOutput: []any{[]models.APIKey{}, errors.New("test error")},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseErr{Error: "Error retrieving data"}),
+			},
+			expectedError: nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockService.
+				On("ListAPIKeys", ctx).
+				Return(tc.mockOutput...).
+				Once()
+
+			got, err := handler(ctx, events.APIGatewayProxyRequest{})
+
+			assert.Equal(t, tc.expectedError, err, "Error expectations not met")
+			assert.Equal(t, tc.expectedResponse, got, "Wrong response body")
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"context"
 	"errors"
-	"log/slog"
 	"net/http"
 	"testing"
 
@@ -17,8 +16,7 @@ import (
 
 func TestHandleListUsers(t *testing.T) {
 	mockService := new(serviceMock.MockUserLister)
-	logger := slog.Default()
-	handler := HandleListUsers(logger, mockService)
+	handler := HandleListUsers(mockService)
 
 	users := []models.User{
 		{ID: 1, FirstName: "John", LastName: "Doe", Role: "Admin", UserID: 1001},
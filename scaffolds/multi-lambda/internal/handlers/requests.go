@@ -3,13 +3,16 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
 )
 
 type inputUser struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	FirstName string `json:"first_name" log:"sensitive"`
+	LastName  string `json:"last_name" log:"sensitive"`
 	Role      string `json:"role"`
 	UserID    int    `json:"user_id"`
 }
@@ -69,6 +72,70 @@ func (user inputUser) Valid() []problem {
 	return problems
 }
 
+type inputAPIKey struct {
+	Owner     string     `json:"owner"`
+	Roles     []string   `json:"roles"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// MapTo maps a inputAPIKey to a models.APIKey object.
+func (key inputAPIKey) MapTo() (models.APIKey, error) {
+	var expiresAt time.Time
+	if key.ExpiresAt != nil {
+		expiresAt = *key.ExpiresAt
+	}
+
+	return models.APIKey{
+		Owner:     key.Owner,
+		Roles:     key.Roles,
+		Scopes:    key.Scopes,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Valid validates all fields of an inputAPIKey struct.
+func (key inputAPIKey) Valid() []problem {
+	var problems []problem
+
+	// validate Owner is not blank
+	if key.Owner == "" {
+		problems = append(problems, problem{
+			Name:        "owner",
+			Description: "must not be blank",
+		})
+	}
+
+	// validate roles and scopes hold no blanks or whitespace, as they are stored space separated
+	if slices.ContainsFunc(key.Roles, notAToken) {
+		problems = append(problems, problem{
+			Name:        "roles",
+			Description: "must not be blank or contain whitespace",
+		})
+	}
+	if slices.ContainsFunc(key.Scopes, notAToken) {
+		problems = append(problems, problem{
+			Name:        "scopes",
+			Description: "must not be blank or contain whitespace",
+		})
+	}
+
+	// validate ExpiresAt is in the future, if set
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		problems = append(problems, problem{
+			Name:        "expires_at",
+			Description: "must be in the future",
+		})
+	}
+
+	return problems
+}
+
+// notAToken reports whether value is blank or contains whitespace.
+func notAToken(value string) bool {
+	return value == "" || strings.ContainsAny(value, " \t\n")
+}
+
 // problem represents an issue found during validation.
 type problem struct {
 	Name        string `json:"name"`
@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"context"
-	"log/slog"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/metrics"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/services"
 )
 
 type userUpdater interface {
@@ -17,13 +20,14 @@ type userUpdater interface {
 // HandleUpdateUser returns a HandlerFunc that handles POST requests to update a user. It retrieves
 // the user ID from the path parameters, decodes and validates the request body, updates the user
 // in the database, and returns the updated user in the response.
-func HandleUpdateUser(logger *slog.Logger, service userUpdater) HandlerFunc {
+func HandleUpdateUser(service userUpdater) HandlerFunc {
 	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		logger := logging.FromContext(ctx)
 		// get and validate ID
 		idString := request.PathParameters["ID"]
 		ID, err := strconv.Atoi(idString)
 		if err != nil {
-			logger.Error("error getting ID", "error", err)
+			logger.ErrorContext(ctx, "error getting ID", "error", err)
 			return encodeResponse(logger, http.StatusBadRequest, responseErr{
 				Error: "Not a valid ID",
 			})
@@ -34,12 +38,12 @@ func HandleUpdateUser(logger *slog.Logger, service userUpdater) HandlerFunc {
 		if err != nil {
 			switch {
 			case len(problems) > 0:
-				logger.Error("Problems validating input", "error", err, "problems", problems)
+				logger.ErrorContext(ctx, "Problems validating input", "error", err, "problems", problems)
 				return encodeResponse(logger, http.StatusBadRequest, responseErr{
 					ValidationErrors: problems,
 				})
 			default:
-				logger.Error("BodyParser error", "error", err)
+				logger.ErrorContext(ctx, "BodyParser error", "error", err)
 				return encodeResponse(logger, http.StatusBadRequest, responseErr{
 					Error: "missing values or malformed body",
 				})
@@ -49,11 +53,18 @@ func HandleUpdateUser(logger *slog.Logger, service userUpdater) HandlerFunc {
 		// update object in database
 		user, err := service.UpdateUser(ctx, ID, userIn)
 		if err != nil {
-			logger.Error("error updating object in database", "error", err)
+			if errors.Is(err, services.ErrNotFound) {
+				return encodeResponse(logger, http.StatusNotFound, responseErr{
+					Error: "User not found",
+				})
+			}
+
+			logger.ErrorContext(ctx, "error updating object in database", "error", err)
 			return encodeResponse(logger, http.StatusInternalServerError, responseErr{
 				Error: "Error updating object",
 			})
 		}
+		metrics.Put(ctx, "UsersUpdated", 1, metrics.Count)
 
 		// return response
 		userOut := mapOutput(user)
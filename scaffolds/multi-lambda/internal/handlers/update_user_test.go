@@ -3,21 +3,21 @@ package handlers
 import (
 	"context"
 	"errors"
-	"log/slog"
+	"fmt"
 	"net/http"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
 	serviceMock "github.com/captechconsulting/go-microservice-templates/lambda/internal/handlers/mock"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/services"
 	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestHandleUpdateUser(t *testing.T) {
 	mockService := new(serviceMock.MockUserUpdater)
-	logger := slog.Default()
-	handler := HandleUpdateUser(logger, mockService)
+	handler := HandleUpdateUser(mockService)
 
 	user := models.User{FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
 	userIn := inputUser{FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
@@ -93,6 +93,21 @@ func TestHandleUpdateUser(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		"user not found": {
+			mockCalled: true,
+			mockInput:  []any{ctx, 1, user},
+			mockOutput: []any{models.User{}, fmt.Errorf("[in services.UpdateUser] user 1: %w", services.ErrNotFound)},
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ID": "1"},
+				Body:           testutil.ToJSONString(userIn),
+			},
+			expectedResponse: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       testutil.ToJSONString(responseErr{Error: "User not found"}),
+			},
+			expectedError: nil,
+		},
 		"error creating user": {
 			mockCalled: true,
 			mockInput:  []any{ctx, 1, user},
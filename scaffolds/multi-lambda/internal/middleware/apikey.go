@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+)
+
+// APIKey authenticates requests that carry an API key in the named header. The principal of a
+// valid key is stored in the request context, see auth.PrincipalFromContext, and Authenticate then
+// lets the request through without a bearer token. Requests without the header are passed on
+// unchanged, requests with an invalid key are rejected with a 401.
+func APIKey(validator auth.APIKeyValidator, name string) LambdaMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			logger := logging.FromContext(ctx)
+			key := header(request, name)
+			if key == "" {
+				return next(ctx, request)
+			}
+
+			principal, err := validator.ValidateAPIKey(ctx, key)
+			if err != nil {
+				logger.WarnContext(ctx, "Request rejected, invalid api key", "err", err, "method", request.HTTPMethod, "path", request.Path)
+				return events.APIGatewayProxyResponse{
+					Headers:    map[string]string{"Content-Type": "application/json"},
+					StatusCode: http.StatusUnauthorized,
+					Body:       `{"error": "invalid api key"}`,
+				}, nil
+			}
+
+			return next(auth.WithPrincipal(ctx, principal), request)
+		}
+	}
+}
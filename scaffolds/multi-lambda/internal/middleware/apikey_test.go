@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/tenant"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubAPIKeys map[string]auth.Principal
+
+func (s stubAPIKeys) ValidateAPIKey(_ context.Context, key string) (auth.Principal, error) {
+	principal, ok := s[key]
+	if !ok {
+		return auth.Principal{}, auth.ErrInvalidAPIKey
+	}
+	return principal, nil
+}
+
+func TestAPIKey(t *testing.T) {
+	issuer := testutil.NewJWTIssuer(t)
+	verifier := auth.NewVerifier(auth.NewJWKS(issuer.JWKSURL(), time.Hour), "https://issuer.test", "users-api", 0)
+	keys := stubAPIKeys{
+		"ak_0a1b2c3d_secret": {Subject: "billing-batch", Tenant: "tenant-a", Method: auth.MethodAPIKey},
+	}
+
+	validToken := issuer.Sign("RS256", "", map[string]any{
+		"sub":       "user-1",
+		"iss":       "https://issuer.test",
+		"aud":       "users-api",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"tenant_id": "tenant-b",
+	})
+
+	tests := map[string]struct {
+		headers          map[string]string
+		expectedSubject  string
+		expectedMethod   string
+		expectedTenant   string
+		expectedResponse events.APIGatewayProxyResponse
+	}{
+		"valid api key": {
+			headers:          map[string]string{"x-api-key": "ak_0a1b2c3d_secret"},
+			expectedSubject:  "billing-batch",
+			expectedMethod:   auth.MethodAPIKey,
+			expectedTenant:   "tenant-a",
+			expectedResponse: events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		"invalid api key": {
+			headers: map[string]string{"X-API-Key": "ak_0a1b2c3d_wrong"},
+			expectedResponse: events.APIGatewayProxyResponse{
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				StatusCode: http.StatusUnauthorized,
+				Body:       `{"error": "invalid api key"}`,
+			},
+		},
+		"no api key falls back to bearer token": {
+			headers:          map[string]string{"Authorization": "Bearer " + validToken},
+			expectedSubject:  "user-1",
+			expectedMethod:   auth.MethodBearer,
+			expectedTenant:   "tenant-b",
+			expectedResponse: events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		"no credentials": {
+			headers: map[string]string{},
+			expectedResponse: events.APIGatewayProxyResponse{
+				Headers: map[string]string{
+					"Content-Type":     "application/json",
+					"WWW-Authenticate": `Bearer`,
+				},
+				StatusCode: http.StatusUnauthorized,
+				Body:       `{"error": "missing bearer token"}`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotPrincipal auth.Principal
+			var gotTenant string
+			handler := func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				gotPrincipal, _ = auth.PrincipalFromContext(ctx)
+				gotTenant, _ = tenant.FromContext(ctx)
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+			}
+			combined := AddToHandler(
+				handler,
+				APIKey(keys, "X-API-Key"),
+				Authenticate(verifier),
+				Tenant(TenantFromPrincipal(), TenantFromClaim("tenant_id")),
+			)
+
+			resp, err := combined(context.Background(), events.APIGatewayProxyRequest{Headers: tt.headers})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResponse, resp)
+			assert.Equal(t, tt.expectedSubject, gotPrincipal.Subject)
+			assert.Equal(t, tt.expectedMethod, gotPrincipal.Method)
+			assert.Equal(t, tt.expectedTenant, gotTenant)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	claims := func(roles ...any) *auth.Claims {
+		c, err := auth.NewClaims(map[string]any{
+			"roles":   roles,
+			"scope":   "users:read",
+			"user_id": json.Number("1001"),
+		})
+		if err != nil {
+			t.Fatalf("building claims: %v", err)
+		}
+		return &c
+	}
+
+	policy := auth.Policy{
+		Roles:      []string{"Employee"},
+		Scopes:     []string{"users:read"},
+		OwnerRoles: []string{"Customer"},
+		OwnerParam: "ID",
+	}
+	lookupPolicy := policy
+	lookupPolicy.Owner = func(ctx context.Context, resourceID string) (string, error) {
+		if resourceID == "7" {
+			return "1001", nil
+		}
+		return "", errors.New("db down")
+	}
+
+	ok := events.APIGatewayProxyResponse{StatusCode: http.StatusOK}
+	forbidden := events.APIGatewayProxyResponse{
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		StatusCode: http.StatusForbidden,
+		Body:       `{"error": "forbidden"}`,
+	}
+
+	tests := map[string]struct {
+		policy           *auth.Policy
+		claims           *auth.Claims
+		ID               string
+		expectedResponse events.APIGatewayProxyResponse
+	}{
+		"employee": {
+			claims:           claims("Employee"),
+			ID:               "1002",
+			expectedResponse: ok,
+		},
+		"owner": {
+			claims:           claims("Customer"),
+			ID:               "1001",
+			expectedResponse: ok,
+		},
+		"not owner": {
+			claims:           claims("Customer"),
+			ID:               "1002",
+			expectedResponse: forbidden,
+		},
+		"no role": {
+			claims:           claims(),
+			ID:               "1001",
+			expectedResponse: forbidden,
+		},
+		"owner by lookup": {
+			policy:           &lookupPolicy,
+			claims:           claims("Customer"),
+			ID:               "7",
+			expectedResponse: ok,
+		},
+		"owner lookup fails": {
+			policy: &lookupPolicy,
+			claims: claims("Customer"),
+			ID:     "8",
+			expectedResponse: events.APIGatewayProxyResponse{
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				StatusCode: http.StatusInternalServerError,
+				Body:       `{"error": "internal server error"}`,
+			},
+		},
+		"no claims": {
+			claims: nil,
+			ID:     "1001",
+			expectedResponse: events.APIGatewayProxyResponse{
+				Headers: map[string]string{
+					"Content-Type":     "application/json",
+					"WWW-Authenticate": `Bearer`,
+				},
+				StatusCode: http.StatusUnauthorized,
+				Body:       `{"error": "missing bearer token"}`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := policy
+			if tt.policy != nil {
+				p = *tt.policy
+			}
+
+			handler := func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return ok, nil
+			}
+			combined := AddToHandler(handler, Authorize(p))
+
+			ctx := context.Background()
+			if tt.claims != nil {
+				ctx = auth.WithClaims(ctx, *tt.claims)
+			}
+			resp, err := combined(ctx, events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ID": tt.ID},
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResponse, resp)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// CORSPolicy configures which cross-origin requests the CORS middleware allows.
+type CORSPolicy struct {
+	// AllowedOrigins lists the origins allowed to call the API. `*` allows any origin, which is
+	// answered with a literal `*` and never with credentials.
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+	// AllowCredentials allows requests with credentials from the listed origins. It is ignored
+	// when any origin is allowed.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+// CORS adds CORS headers to the responses of requests from allowed origins. Preflight requests
+// are answered directly with a 204, so they never reach authentication. It must run before
+// authentication for browsers to see error responses too.
+func CORS(policy CORSPolicy) LambdaMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			origin := header(request, "Origin")
+			requestMethod := header(request, "Access-Control-Request-Method")
+
+			if request.HTTPMethod == http.MethodOptions && requestMethod != "" {
+				response := events.APIGatewayProxyResponse{
+					StatusCode: http.StatusNoContent,
+					Headers:    map[string]string{"Vary": "Origin, Access-Control-Request-Method, Access-Control-Request-Headers"},
+				}
+				allowedOrigin, ok := policy.allowedOrigin(origin)
+				if !ok || !policy.allowsMethod(requestMethod) {
+					return response, nil
+				}
+
+				response.Headers["Access-Control-Allow-Origin"] = allowedOrigin
+				response.Headers["Access-Control-Allow-Methods"] = strings.Join(policy.AllowedMethods, ", ")
+				if len(policy.AllowedHeaders) > 0 {
+					response.Headers["Access-Control-Allow-Headers"] = strings.Join(policy.AllowedHeaders, ", ")
+				}
+				if policy.credentials() {
+					response.Headers["Access-Control-Allow-Credentials"] = "true"
+				}
+				if policy.MaxAge > 0 {
+					response.Headers["Access-Control-Max-Age"] = strconv.Itoa(int(policy.MaxAge.Seconds()))
+				}
+				return response, nil
+			}
+
+			response, err := next(ctx, request)
+			if response.Headers == nil {
+				response.Headers = make(map[string]string)
+			}
+			addVary(response.Headers, "Origin")
+			allowedOrigin, ok := policy.allowedOrigin(origin)
+			if !ok {
+				return response, err
+			}
+
+			response.Headers["Access-Control-Allow-Origin"] = allowedOrigin
+			if len(policy.ExposedHeaders) > 0 {
+				response.Headers["Access-Control-Expose-Headers"] = strings.Join(policy.ExposedHeaders, ", ")
+			}
+			if policy.credentials() {
+				response.Headers["Access-Control-Allow-Credentials"] = "true"
+			}
+			return response, err
+		}
+	}
+}
+
+// allowedOrigin returns the Access-Control-Allow-Origin value for origin and whether origin may
+// call the API at all. Requests without an origin are not cross-origin requests and get no CORS
+// headers.
+func (p CORSPolicy) allowedOrigin(origin string) (string, bool) {
+	switch {
+	case origin == "":
+		return "", false
+	case p.anyOrigin():
+		return "*", true
+	case slices.ContainsFunc(p.AllowedOrigins, func(allowed string) bool { return strings.EqualFold(allowed, origin) }):
+		return origin, true
+	default:
+		return "", false
+	}
+}
+
+// anyOrigin reports whether every origin may call the API.
+func (p CORSPolicy) anyOrigin() bool {
+	return slices.Contains(p.AllowedOrigins, "*")
+}
+
+// credentials reports whether responses allow credentials. Browsers reject credentials with a `*`
+// origin, and echoing every origin instead would let any site make credentialed requests.
+func (p CORSPolicy) credentials() bool {
+	return p.AllowCredentials && !p.anyOrigin()
+}
+
+// addVary adds value to the Vary header of headers, keeping the values already listed.
+func addVary(headers map[string]string, value string) {
+	for _, existing := range strings.Split(headers["Vary"], ",") {
+		if strings.EqualFold(strings.TrimSpace(existing), value) {
+			return
+		}
+	}
+	if headers["Vary"] == "" {
+		headers["Vary"] = value
+		return
+	}
+	headers["Vary"] += ", " + value
+}
+
+// allowsMethod reports whether a preflight for method may pass.
+func (p CORSPolicy) allowsMethod(method string) bool {
+	return slices.ContainsFunc(p.AllowedMethods, func(allowed string) bool {
+		return strings.EqualFold(allowed, method)
+	})
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugLogging(t *testing.T) {
+	admin := auth.Policy{Roles: []string{"Admin"}}
+
+	tests := map[string]struct {
+		roles         []string
+		headers       map[string]string
+		expectedDebug bool
+	}{
+		"admin with header": {
+			roles:         []string{"Admin"},
+			headers:       map[string]string{"x-debug-log": "true"},
+			expectedDebug: true,
+		},
+		"admin without header": {
+			roles: []string{"Admin"},
+		},
+		"admin with false header": {
+			roles:   []string{"Admin"},
+			headers: map[string]string{"X-Debug-Log": "0"},
+		},
+		"not an admin": {
+			roles:   []string{"Employee"},
+			headers: map[string]string{"X-Debug-Log": "1"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var debug bool
+			handler := AddToHandler(
+				func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+					debug = logging.Debug(ctx)
+					return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+				},
+				DebugLogging[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](DebugHeader("X-Debug-Log", admin)),
+			)
+
+			ctx := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "user-1", Roles: tc.roles})
+			_, err := handler(ctx, events.APIGatewayProxyRequest{Headers: tc.headers})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedDebug, debug)
+		})
+	}
+}
+
+func TestDebugBodies(t *testing.T) {
+	tests := map[string]struct {
+		debug         bool
+		expectedLines []map[string]any
+	}{
+		"debug": {
+			debug: true,
+			expectedLines: []map[string]any{
+				{"msg": "Request body", "body": `{"first_name":"[REDACTED]","id":1}`},
+				{"msg": "Response body", "body": `{"first_name":"[REDACTED]","id":1}`},
+			},
+		},
+		"not debug": {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			handler, err := logging.NewHandler(&out, logging.Options{Format: "json", Level: slog.LevelInfo})
+			assert.NoError(t, err)
+			logger := slog.New(redact.NewHandler(handler, redact.NewPolicy([]string{"first_name"}, nil)))
+
+			ctx := logging.WithLogger(context.Background(), logger)
+			if tc.debug {
+				ctx = logging.WithDebug(ctx)
+			}
+			response, err := DebugBodies()(func(_ context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: request.Body}, nil
+			})(ctx, events.APIGatewayProxyRequest{HTTPMethod: http.MethodPut, Path: "/v1/user/1", Body: `{"id":1,"first_name":"Ada"}`})
+
+			assert.NoError(t, err)
+			assert.Equal(t, `{"id":1,"first_name":"Ada"}`, response.Body)
+
+			var lines []map[string]any
+			decoder := json.NewDecoder(&out)
+			for decoder.More() {
+				var line map[string]any
+				if err := decoder.Decode(&line); err != nil {
+					t.Fatalf("decoding log line: %v", err)
+				}
+				lines = append(lines, map[string]any{"msg": line["msg"], "body": line["body"]})
+			}
+			assert.Equal(t, tc.expectedLines, lines)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&out, nil))
+
+	handler := AddToHandler(
+		func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			logging.FromContext(ctx).InfoContext(ctx, "Handled")
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+		},
+		Logger[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](logger),
+		Tenant(TenantFromHeader("X-Tenant-ID")),
+	)
+
+	_, err := handler(context.Background(), events.APIGatewayProxyRequest{
+		Headers: map[string]string{"X-Tenant-ID": "acme"},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "msg=Handled")
+	assert.Contains(t, out.String(), "tenant_id=acme")
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/metrics"
+)
+
+// panicsMetric counts the invocations that panicked. Recovery records it, as panics it recovers
+// from never reach Metrics.
+const panicsMetric = "Panics"
+
+// Metrics writes the metrics of every invocation to emitter: `Invocations`, `Duration`,
+// `ColdStart`, `Errors` and `Panics`. observe, if not nil, records metrics derived from the event
+// and response, see StatusClasses. The recorder of the invocation is carried in the context, so
+// handlers can add their own metrics with metrics.Put.
+//
+// Panics are counted whether Metrics comes before or after Recovery. To see the response
+// Recovery returns for a panic, Metrics must come before it.
+func Metrics[E any, R any](emitter *metrics.Emitter, observe func(recorder *metrics.Recorder, event E, response R)) LambdaMiddlewareT[E, R] {
+	// the execution environment is reused between invocations, only its first one is a cold start
+	var invoked atomic.Bool
+
+	return func(next HandlerFuncT[E, R]) HandlerFuncT[E, R] {
+		return func(ctx context.Context, event E) (response R, err error) {
+			recorder := metrics.NewRecorder()
+			ctx = metrics.NewContext(ctx, recorder)
+			start := time.Now()
+
+			defer func() {
+				recovered := recover()
+
+				recorder.Put("Invocations", 1, metrics.Count)
+				recorder.Put("Duration", float64(time.Since(start).Microseconds())/1000, metrics.Milliseconds)
+				recorder.Put("ColdStart", boolValue(!invoked.Swap(true)), metrics.Count)
+				recorder.Put("Errors", boolValue(err != nil), metrics.Count)
+				if _, ok := recorder.Value(panicsMetric); !ok || recovered != nil {
+					recorder.Put(panicsMetric, boolValue(recovered != nil), metrics.Count)
+				}
+				if observe != nil && recovered == nil {
+					observe(recorder, event, response)
+				}
+
+				// metrics are written to stdout like the logs, so a failed write cannot be reported
+				_ = emitter.Emit(recorder)
+
+				if recovered != nil {
+					panic(recovered)
+				}
+			}()
+
+			return next(ctx, event)
+		}
+	}
+}
+
+// StatusClasses is an observe function for Metrics that counts API Gateway responses by status
+// class: `Status2xx`, `Status3xx`, `Status4xx` and `Status5xx`.
+func StatusClasses(recorder *metrics.Recorder, _ events.APIGatewayProxyRequest, response events.APIGatewayProxyResponse) {
+	for class := 2; class <= 5; class++ {
+		recorder.Put(fmt.Sprintf("Status%dxx", class), boolValue(response.StatusCode/100 == class), metrics.Count)
+	}
+}
+
+// boolValue returns 1 if b is true and 0 otherwise, so flags can be summed and averaged.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -7,8 +7,17 @@ import (
 )
 
 type (
-	HandlerFunc      func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
-	LambdaMiddleware func(next HandlerFunc) HandlerFunc
+	// HandlerFuncT is a lambda handler type where E is the event type and R is the response type.
+	HandlerFuncT[E any, R any] func(context.Context, E) (R, error)
+
+	// LambdaMiddlewareT is a lambda middleware type where E is the event type and R is the response type.
+	LambdaMiddlewareT[E any, R any] func(next HandlerFuncT[E, R]) HandlerFuncT[E, R]
+
+	// HandlerFunc is a lambda handler for API Gateway requests.
+	HandlerFunc = HandlerFuncT[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse]
+
+	// LambdaMiddleware is a lambda middleware for API Gateway requests.
+	LambdaMiddleware = LambdaMiddlewareT[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse]
 )
 
 func AddToHandler(handler HandlerFunc, middlewares ...LambdaMiddleware) HandlerFunc {
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+)
+
+// Principal stores the caller resolved by the API Gateway authorizer, see cmd/authorizer, in the
+// request context, where handlers read it with auth.PrincipalFromContext. It replaces
+// Authenticate when authentication happens at the gateway. Requests that did not pass through
+// the authorizer are rejected with a 401.
+func Principal() LambdaMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			logger := logging.FromContext(ctx)
+			principal, err := auth.PrincipalFromAuthorizer(request.RequestContext.Authorizer)
+			if err != nil {
+				logger.WarnContext(ctx, "Request rejected, no authorizer principal", "err", err, "method", request.HTTPMethod, "path", request.Path)
+				return events.APIGatewayProxyResponse{
+					Headers:    map[string]string{"Content-Type": "application/json"},
+					StatusCode: http.StatusUnauthorized,
+					Body:       `{"error": "unauthenticated"}`,
+				}, nil
+			}
+
+			return next(auth.WithPrincipal(ctx, principal), request)
+		}
+	}
+}
+
+// TenantFromPrincipal returns a TenantSource that reads the tenant ID of the authenticated
+// principal, as resolved by the API Gateway authorizer or an API key. It must run after Principal
+// or APIKey.
+func TenantFromPrincipal() TenantSource {
+	return func(ctx context.Context, _ events.APIGatewayProxyRequest) string {
+		principal, ok := auth.PrincipalFromContext(ctx)
+		if !ok {
+			return ""
+		}
+		return principal.Tenant
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/ratelimit"
+)
+
+// problem is an RFC 9457 problem details body.
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// RateLimit limits each client to the limit of the route it calls, see ratelimit.Limits. Routes
+// are named by HTTP method and API Gateway resource, e.g. `GET /lambda/user/{ID}`.
+// Authenticated clients are identified by their principal, so every API key owner and token
+// subject has its own buckets; other clients by their source IP from
+// `RequestContext.Identity`. Every response carries `RateLimit-*` headers and requests over the
+// limit are rejected with a 429. If the store fails, requests are let through. It must run after
+// authentication.
+func RateLimit(store ratelimit.Store, limits ratelimit.Limits) LambdaMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			resource := request.Resource
+			if resource == "" {
+				resource = request.Path
+			}
+			route := request.HTTPMethod + " " + resource
+
+			return takeToken(ctx, request, next, store, route+" "+rateLimitClient(ctx, request), limits.For(route), route)
+		}
+	}
+}
+
+// RateLimitIP limits each source IP to limit across all routes. It must run before
+// authentication, so requests with invalid credentials are limited too and cannot exhaust the
+// database with API key lookups; RateLimit then limits authenticated clients per route. Responses
+// are like those of RateLimit, whose headers take precedence on requests it limits.
+func RateLimitIP(store ratelimit.Store, limit ratelimit.Limit) LambdaMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			return takeToken(ctx, request, next, store, "ip "+request.RequestContext.Identity.SourceIP, limit, "ip")
+		}
+	}
+}
+
+// takeToken takes a token from the bucket key for the request and calls next, adding the
+// `RateLimit-*` headers to its response unless a later limit set them. It responds with a 429 if
+// the bucket is empty. name identifies the limit in the logs.
+func takeToken(
+	ctx context.Context,
+	request events.APIGatewayProxyRequest,
+	next HandlerFunc,
+	store ratelimit.Store,
+	key string,
+	limit ratelimit.Limit,
+	name string,
+) (events.APIGatewayProxyResponse, error) {
+	logger := logging.FromContext(ctx)
+
+	result, err := store.Take(ctx, key, limit)
+	if err != nil {
+		logger.ErrorContext(ctx, "Rate limit not enforced", "err", err, "method", request.HTTPMethod, "path", request.Path)
+		return next(ctx, request)
+	}
+
+	headers := result.Headers()
+	if !result.Allowed {
+		logger.WarnContext(ctx, "Request rejected, rate limit exceeded", "limit_route", name)
+		body, _ := json.Marshal(problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusTooManyRequests),
+			Status: http.StatusTooManyRequests,
+			Detail: "rate limit exceeded, retry after " + headers["Retry-After"] + "s",
+		})
+		headers["Content-Type"] = "application/problem+json"
+		return events.APIGatewayProxyResponse{
+			Headers:    headers,
+			StatusCode: http.StatusTooManyRequests,
+			Body:       string(body),
+		}, nil
+	}
+
+	response, err := next(ctx, request)
+	if response.Headers == nil {
+		response.Headers = make(map[string]string, len(headers))
+	}
+	if _, ok := response.Headers["RateLimit-Limit"]; !ok {
+		for key, value := range headers {
+			response.Headers[key] = value
+		}
+	}
+	return response, err
+}
+
+// rateLimitClient identifies the client of the request for rate limiting. API keys are limited
+// each on their own, as their owners are free text and may be shared between keys and tenants.
+func rateLimitClient(ctx context.Context, request events.APIGatewayProxyRequest) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		if principal.Method == auth.MethodAPIKey {
+			return principal.Method + ":" + principal.Tenant + ":" + principal.KeyPrefix
+		}
+		return principal.Method + ":" + principal.Subject
+	}
+	return "ip:" + request.RequestContext.Identity.SourceIP
+}
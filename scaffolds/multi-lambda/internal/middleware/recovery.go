@@ -2,19 +2,28 @@ package middleware
 
 import (
 	"context"
-	"log/slog"
+	"errors"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/metrics"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/reporting"
 )
 
-func Recovery(logger *slog.Logger) LambdaMiddleware {
+// Recovery recovers panics in later middleware and the handler, reports them to reporter with the
+// stack of the panicking goroutine and the method and path of the request, and responds with a
+// 500.
+func Recovery(reporter reporting.ErrorReporter) LambdaMiddleware {
 	return func(next HandlerFunc) HandlerFunc {
-		return func(ctx context.Context, request events.APIGatewayProxyRequest) (event events.APIGatewayProxyResponse, err error) {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (response events.APIGatewayProxyResponse, err error) {
 			defer func() {
-				if err := recover(); err != nil {
-					logger.Error("Recovered from panic", "err", err)
-					event = events.APIGatewayProxyResponse{
+				if v := recover(); v != nil {
+					reporter.Report(ctx, reporting.Recovered(v, map[string]string{
+						"method": request.HTTPMethod,
+						"path":   request.Path,
+					}))
+					metrics.Put(ctx, panicsMetric, 1, metrics.Count)
+					response = events.APIGatewayProxyResponse{
 						Headers:    map[string]string{"Content-Type": "application/json"},
 						StatusCode: http.StatusInternalServerError,
 						Body:       `{"error": "Internal server error"}`,
@@ -26,3 +35,25 @@ func Recovery(logger *slog.Logger) LambdaMiddleware {
 		}
 	}
 }
+
+// AuthorizerRecovery recovers panics in later middleware and the authorizer handler like Recovery,
+// and denies the request: the "Unauthorized" error it returns is mapped to a 401 by API Gateway,
+// where any other error would be a 500.
+func AuthorizerRecovery(reporter reporting.ErrorReporter) LambdaMiddlewareT[events.APIGatewayCustomAuthorizerRequestTypeRequest, events.APIGatewayCustomAuthorizerResponse] {
+	return func(next HandlerFuncT[events.APIGatewayCustomAuthorizerRequestTypeRequest, events.APIGatewayCustomAuthorizerResponse]) HandlerFuncT[events.APIGatewayCustomAuthorizerRequestTypeRequest, events.APIGatewayCustomAuthorizerResponse] {
+		return func(ctx context.Context, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (response events.APIGatewayCustomAuthorizerResponse, err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					reporter.Report(ctx, reporting.Recovered(v, map[string]string{
+						"method": request.HTTPMethod,
+						"path":   request.Path,
+					}))
+					metrics.Put(ctx, panicsMetric, 1, metrics.Count)
+					response, err = events.APIGatewayCustomAuthorizerResponse{}, errors.New("Unauthorized")
+				}
+			}()
+
+			return next(ctx, request)
+		}
+	}
+}
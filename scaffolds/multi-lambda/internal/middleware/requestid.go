@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+)
+
+// RequestIDHeader is the response header the request ID is returned in.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID carries the API Gateway request ID, or a generated one if there is none, and the route
+// of every request in the context, so they are added to every log line by logging.ContextHandler.
+// The request ID is returned in the `X-Request-ID` header, so callers can quote it.
+func RequestID() LambdaMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			requestID := request.RequestContext.RequestID
+			if requestID == "" {
+				requestID = logging.NewRequestID()
+			}
+			ctx = logging.WithRequestID(ctx, requestID)
+			ctx = logging.WithRoute(ctx, request.Resource)
+
+			response, err := next(ctx, request)
+			if response.Headers == nil {
+				response.Headers = make(map[string]string, 1)
+			}
+			response.Headers[RequestIDHeader] = requestID
+			return response, err
+		}
+	}
+}
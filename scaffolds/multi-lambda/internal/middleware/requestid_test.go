@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := map[string]struct {
+		request           events.APIGatewayProxyRequest
+		expectedGenerated bool
+		expectedID        string
+	}{
+		"api gateway request ID": {
+			request: events.APIGatewayProxyRequest{
+				Resource:       "/lambda/user/{ID}",
+				RequestContext: events.APIGatewayProxyRequestContext{RequestID: "c6af9ac6-7b61-11e6-9a41-93e8deadbeef"},
+			},
+			expectedID: "c6af9ac6-7b61-11e6-9a41-93e8deadbeef",
+		},
+		"generated request ID": {
+			request:           events.APIGatewayProxyRequest{Resource: "/lambda/user/{ID}"},
+			expectedGenerated: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var handlerID, handlerRoute string
+			handler := RequestID()(func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				handlerID, _ = logging.RequestID(ctx)
+				handlerRoute, _ = logging.Route(ctx)
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+			})
+
+			response, err := handler(context.Background(), tc.request)
+			assert.NoError(t, err)
+
+			if tc.expectedGenerated {
+				assert.Len(t, handlerID, 32)
+			} else {
+				assert.Equal(t, tc.expectedID, handlerID)
+			}
+			assert.Equal(t, handlerID, response.Headers[RequestIDHeader])
+			assert.Equal(t, "/lambda/user/{ID}", handlerRoute)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurity(t *testing.T) {
+	tests := map[string]struct {
+		headers         SecurityHeaders
+		responseHeaders map[string]string
+		expectedHeaders map[string]string
+	}{
+		"all headers": {
+			headers: SecurityHeaders{
+				HSTSMaxAge:            365 * 24 * time.Hour,
+				ContentSecurityPolicy: "default-src 'none'",
+				ReferrerPolicy:        "no-referrer",
+			},
+			responseHeaders: map[string]string{"Content-Type": "application/json"},
+			expectedHeaders: map[string]string{
+				"Content-Type":              "application/json",
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+				"X-Content-Type-Options":    "nosniff",
+				"Content-Security-Policy":   "default-src 'none'",
+				"Referrer-Policy":           "no-referrer",
+			},
+		},
+		"empty values omitted": {
+			headers:         SecurityHeaders{},
+			expectedHeaders: map[string]string{"X-Content-Type-Options": "nosniff"},
+		},
+		"handler headers kept": {
+			headers:         SecurityHeaders{ReferrerPolicy: "no-referrer"},
+			responseHeaders: map[string]string{"Referrer-Policy": "same-origin"},
+			expectedHeaders: map[string]string{
+				"X-Content-Type-Options": "nosniff",
+				"Referrer-Policy":        "same-origin",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Headers: tt.responseHeaders}, nil
+			}
+
+			response, err := Security(tt.headers)(handler)(context.Background(), events.APIGatewayProxyRequest{})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedHeaders, response.Headers)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/tenant"
+)
+
+// TenantSource extracts a tenant ID from an API Gateway request. An empty string means the source
+// could not resolve a tenant.
+type TenantSource func(ctx context.Context, request events.APIGatewayProxyRequest) string
+
+// TenantFromHeader returns a TenantSource that reads the tenant ID from the named header. Header
+// names are matched case-insensitively.
+func TenantFromHeader(name string) TenantSource {
+	return func(_ context.Context, request events.APIGatewayProxyRequest) string {
+		return header(request, name)
+	}
+}
+
+// TenantFromClaim returns a TenantSource that reads the tenant ID from the named claim of the
+// verified token. It must run after Authenticate.
+func TenantFromClaim(name string) TenantSource {
+	return func(ctx context.Context, _ events.APIGatewayProxyRequest) string {
+		claims, ok := auth.ClaimsFromContext(ctx)
+		if !ok {
+			return ""
+		}
+		return claims.String(name)
+	}
+}
+
+// Tenant resolves the tenant for each request from the given sources, in order, and stores the
+// first match in the request context. Requests without a tenant are rejected with a 400.
+func Tenant(sources ...TenantSource) LambdaMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			logger := logging.FromContext(ctx)
+			for _, source := range sources {
+				if ID := source(ctx, request); ID != "" {
+					ctx = logging.WithLogger(tenant.WithID(ctx, ID), logger.With("tenant_id", ID))
+					return next(ctx, request)
+				}
+			}
+
+			logger.WarnContext(ctx, "Request rejected, no tenant resolved", "method", request.HTTPMethod, "path", request.Path)
+			return events.APIGatewayProxyResponse{
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				StatusCode: http.StatusBadRequest,
+				Body:       `{"error": "missing tenant"}`,
+			}, nil
+		}
+	}
+}
+
+// header returns the first value of the named header from an API Gateway request, matching the
+// name case-insensitively as API Gateway does not normalize header casing.
+func header(request events.APIGatewayProxyRequest, name string) string {
+	for key, value := range request.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	for key, values := range request.MultiValueHeaders {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
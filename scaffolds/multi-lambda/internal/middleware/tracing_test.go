@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	tests := map[string]struct {
+		headers        map[string]string
+		status         int
+		err            error
+		expectedParent string
+		expectedStatus codes.Code
+	}{
+		"new trace": {
+			status:         http.StatusOK,
+			expectedStatus: codes.Unset,
+		},
+		"w3c trace context": {
+			headers:        map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			status:         http.StatusOK,
+			expectedParent: "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedStatus: codes.Unset,
+		},
+		"x-ray trace header": {
+			headers:        map[string]string{"X-Amzn-Trace-Id": "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"},
+			status:         http.StatusOK,
+			expectedParent: "5759e988bd862e3fe1be46a994272793",
+			expectedStatus: codes.Unset,
+		},
+		"server error": {
+			status:         http.StatusInternalServerError,
+			expectedStatus: codes.Error,
+		},
+		"handler error": {
+			status:         http.StatusOK,
+			err:            errors.New("test"),
+			expectedStatus: codes.Error,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			var handlerSpan trace.SpanContext
+			handler := Tracing(provider)(func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				handlerSpan = trace.SpanContextFromContext(ctx)
+				return events.APIGatewayProxyResponse{StatusCode: tc.status}, tc.err
+			})
+
+			_, err := handler(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodGet,
+				Resource:   "/lambda/user/{ID}",
+				Path:       "/lambda/user/1",
+				Headers:    tc.headers,
+			})
+			assert.Equal(t, tc.err, err)
+
+			spans := recorder.Ended()
+			if !assert.Len(t, spans, 1) {
+				return
+			}
+			span := spans[0]
+			assert.Equal(t, "GET /lambda/user/{ID}", span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Equal(t, handlerSpan, span.SpanContext())
+			assert.Contains(t, span.Attributes(), semconv.HTTPResponseStatusCode(tc.status))
+			assert.Equal(t, tc.expectedStatus, span.Status().Code)
+			if tc.expectedParent != "" {
+				assert.Equal(t, tc.expectedParent, span.SpanContext().TraceID().String())
+				assert.True(t, span.Parent().IsRemote())
+			} else {
+				assert.False(t, span.Parent().IsValid())
+			}
+		})
+	}
+}
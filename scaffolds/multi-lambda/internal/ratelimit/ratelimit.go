@@ -0,0 +1,150 @@
+// Package ratelimit implements token bucket rate limiting with in-memory and Redis backed stores.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests requests per Period. Unused capacity accumulates up to Requests, so a
+// client may burst up to Requests requests at once and is then refilled at an even rate.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses a limit of the form `<requests>/<period>`, e.g. `100/1m`. The period is a
+// time.ParseDuration string.
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("[in ratelimit.ParseLimit] limit %q must have the form <requests>/<period>", s)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("[in ratelimit.ParseLimit] limit %q must allow at least one request", s)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("[in ratelimit.ParseLimit] limit %q must have a positive period", s)
+	}
+
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// rate returns the number of tokens the limit refills per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Limits holds the limit of each route and the limit of all other routes. Routes are named
+// `<METHOD> <pattern>`, e.g. `GET /lambda/user/{ID}`.
+type Limits struct {
+	Default Limit
+	Routes  map[string]Limit
+}
+
+// ParseLimits parses the default limit and a map of route names to limits, see ParseLimit.
+func ParseLimits(defaultLimit string, routes map[string]string) (Limits, error) {
+	limits := Limits{Routes: make(map[string]Limit, len(routes))}
+
+	var err error
+	if limits.Default, err = ParseLimit(defaultLimit); err != nil {
+		return Limits{}, err
+	}
+	for route, limit := range routes {
+		if limits.Routes[strings.TrimSpace(route)], err = ParseLimit(limit); err != nil {
+			return Limits{}, fmt.Errorf("route %q: %w", route, err)
+		}
+	}
+
+	return limits, nil
+}
+
+// For returns the limit of the route.
+func (l Limits) For(route string) Limit {
+	if limit, ok := l.Routes[route]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	// Allowed reports whether a token was available.
+	Allowed bool
+	// Limit is the bucket's capacity.
+	Limit int
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token is available. It is zero if Allowed is true.
+	RetryAfter time.Duration
+}
+
+// Headers returns the `RateLimit-Limit`, `RateLimit-Remaining` and `RateLimit-Reset` headers
+// describing the result, plus `Retry-After` if the request was not allowed. Durations are in
+// whole seconds, rounded up.
+func (r Result) Headers() map[string]string {
+	headers := map[string]string{
+		"RateLimit-Limit":     strconv.Itoa(r.Limit),
+		"RateLimit-Remaining": strconv.Itoa(r.Remaining),
+		"RateLimit-Reset":     strconv.Itoa(ceilSeconds(r.Reset)),
+	}
+	if !r.Allowed {
+		headers["Retry-After"] = strconv.Itoa(ceilSeconds(r.RetryAfter))
+	}
+	return headers
+}
+
+// Store keeps token buckets.
+type Store interface {
+	// Take takes a token from the bucket identified by key, which holds limit.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// take applies a token bucket step to a bucket holding tokens that were last updated elapsed ago.
+// It returns the result and the bucket's new token count.
+func take(tokens float64, elapsed time.Duration, limit Limit) (Result, float64) {
+	tokens = math.Min(float64(limit.Requests), tokens+elapsed.Seconds()*limit.rate())
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	return newResult(allowed, tokens, limit), tokens
+}
+
+// newResult describes a bucket holding tokens after a token was, or was not, taken from it.
+func newResult(allowed bool, tokens float64, limit Limit) Result {
+	rate := limit.rate()
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: int(tokens),
+		Reset:     seconds((float64(limit.Requests) - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+
+	return result
+}
+
+// ceilSeconds returns d in seconds, rounded up.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// seconds converts a number of seconds to a time.Duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
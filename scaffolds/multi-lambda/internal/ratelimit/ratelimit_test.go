@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := map[string]struct {
+		input         string
+		expectedLimit Limit
+		expectedError bool
+	}{
+		"per minute": {
+			input:         "100/1m",
+			expectedLimit: Limit{Requests: 100, Period: time.Minute},
+		},
+		"with spaces": {
+			input:         " 5 / 10s ",
+			expectedLimit: Limit{Requests: 5, Period: 10 * time.Second},
+		},
+		"missing period": {
+			input:         "100",
+			expectedError: true,
+		},
+		"zero requests": {
+			input:         "0/1m",
+			expectedError: true,
+		},
+		"invalid period": {
+			input:         "10/minute",
+			expectedError: true,
+		},
+		"negative period": {
+			input:         "10/-1s",
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			limit, err := ParseLimit(tc.input)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedLimit, limit)
+		})
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits("100/1m", map[string]string{"GET /lambda/user": "10/1s"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, Limit{Requests: 10, Period: time.Second}, limits.For("GET /lambda/user"))
+	assert.Equal(t, Limit{Requests: 100, Period: time.Minute}, limits.For("GET /lambda/user/{ID}"))
+
+	_, err = ParseLimits("100/1m", map[string]string{"GET /lambda/user": "fast"})
+	assert.ErrorContains(t, err, `route "GET /lambda/user"`)
+}
+
+func TestResultHeaders(t *testing.T) {
+	allowed := Result{Allowed: true, Limit: 10, Remaining: 9, Reset: 100 * time.Millisecond}
+	assert.Equal(t, map[string]string{
+		"RateLimit-Limit":     "10",
+		"RateLimit-Remaining": "9",
+		"RateLimit-Reset":     "1",
+	}, allowed.Headers())
+
+	denied := Result{Allowed: false, Limit: 10, Remaining: 0, Reset: 60 * time.Second, RetryAfter: 5500 * time.Millisecond}
+	assert.Equal(t, map[string]string{
+		"RateLimit-Limit":     "10",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "60",
+		"Retry-After":         "6",
+	}, denied.Headers())
+}
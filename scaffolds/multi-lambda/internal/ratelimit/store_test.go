@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStore runs a sequence of takes against store. setNow moves the store's clock.
+func testStore(t *testing.T, store Store, setNow func(time.Time)) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 2, Period: time.Second}
+
+	steps := []struct {
+		at       time.Duration
+		key      string
+		expected Result
+	}{
+		{
+			at:       0,
+			key:      "client-a",
+			expected: Result{Allowed: true, Limit: 2, Remaining: 1, Reset: 500 * time.Millisecond},
+		},
+		{
+			at:       0,
+			key:      "client-a",
+			expected: Result{Allowed: true, Limit: 2, Remaining: 0, Reset: time.Second},
+		},
+		{
+			at:       0,
+			key:      "client-a",
+			expected: Result{Allowed: false, Limit: 2, Remaining: 0, Reset: time.Second, RetryAfter: 500 * time.Millisecond},
+		},
+		{
+			at:       0,
+			key:      "client-b",
+			expected: Result{Allowed: true, Limit: 2, Remaining: 1, Reset: 500 * time.Millisecond},
+		},
+		{
+			at:       250 * time.Millisecond,
+			key:      "client-a",
+			expected: Result{Allowed: false, Limit: 2, Remaining: 0, Reset: 750 * time.Millisecond, RetryAfter: 250 * time.Millisecond},
+		},
+		{
+			at:       500 * time.Millisecond,
+			key:      "client-a",
+			expected: Result{Allowed: true, Limit: 2, Remaining: 0, Reset: time.Second},
+		},
+		{
+			at:       10 * time.Second,
+			key:      "client-a",
+			expected: Result{Allowed: true, Limit: 2, Remaining: 1, Reset: 500 * time.Millisecond},
+		},
+	}
+
+	for i, step := range steps {
+		setNow(start.Add(step.at))
+
+		result, err := store.Take(ctx, step.key, limit)
+
+		assert.NoError(t, err, "step %d", i)
+		assert.Equal(t, step.expected, result, "step %d", i)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+
+	testStore(t, store, func(now time.Time) {
+		store.now = func() time.Time { return now }
+	})
+}
+
+func TestMemoryStoreStaysBounded(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	for i := range maxMemoryBuckets + 10 {
+		_, err := store.Take(context.Background(), string(rune(i)), Limit{Requests: 10, Period: time.Second})
+		assert.NoError(t, err)
+		now = now.Add(time.Second)
+	}
+
+	assert.LessOrEqual(t, len(store.buckets), maxMemoryBuckets)
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	store := NewRedisStore(client)
+
+	testStore(t, store, func(now time.Time) {
+		store.now = func() time.Time { return now }
+	})
+
+	assert.True(t, server.Exists("ratelimit:client-a"))
+	assert.Greater(t, server.TTL("ratelimit:client-a"), time.Duration(0), "buckets must expire")
+}
+
+func TestRedisStoreUnavailable(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	server.Close()
+
+	_, err := NewRedisStore(client).Take(context.Background(), "client-a", Limit{Requests: 1, Period: time.Second})
+
+	assert.Error(t, err)
+}
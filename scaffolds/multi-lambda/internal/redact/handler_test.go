@@ -0,0 +1,36 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	policy := NewPolicy([]string{"password"}, nil)
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), policy))
+
+	logger.With("password", "hunter2").
+		WithGroup("request").
+		Error("Error while marshaling data", "data", customer{ID: 1, FirstName: "Ada", Role: "Customer"})
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decoding log entry: %v", err)
+	}
+
+	assert.Equal(t, Mask, entry["password"])
+	assert.Equal(t, map[string]any{
+		"data": map[string]any{
+			"id":         float64(1),
+			"first_name": Mask,
+			"last_name":  Mask,
+			"email":      "",
+			"Role":       "Customer",
+		},
+	}, entry["request"])
+}
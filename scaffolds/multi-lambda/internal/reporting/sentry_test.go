@@ -0,0 +1,108 @@
+package reporting
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSentryReporter(t *testing.T) {
+	tests := map[string]struct {
+		dsn              string
+		expectedEndpoint string
+		expectErr        bool
+	}{
+		"sentry": {
+			dsn:              "https://public@o1.ingest.sentry.io/42",
+			expectedEndpoint: "https://o1.ingest.sentry.io/api/42/envelope/",
+		},
+		"self-hosted under a path": {
+			dsn:              "http://public@localhost:9000/sentry/42",
+			expectedEndpoint: "http://localhost:9000/sentry/api/42/envelope/",
+		},
+		"missing key": {
+			dsn:       "https://o1.ingest.sentry.io/42",
+			expectErr: true,
+		},
+		"missing project": {
+			dsn:       "https://public@o1.ingest.sentry.io/",
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			reporter, err := NewSentryReporter(http.DefaultClient, tc.dsn, "dev")
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.Equal(t, tc.expectedEndpoint, reporter.endpoint)
+		})
+	}
+}
+
+func TestSentryReporter(t *testing.T) {
+	var (
+		auth  string
+		lines []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("X-Sentry-Auth")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "://", "://public@", 1) + "/42"
+	reporter, err := NewSentryReporter(server.Client(), dsn, "dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reporter.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	ctx := tenant.WithID(logging.WithRequestID(context.Background(), "request-1"), "tenant-a")
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	reporter.Report(ctx, panicking(errors.New("db down")))
+
+	assert.Equal(t, "Sentry sentry_version=7, sentry_key=public, sentry_client="+sentryClient, auth)
+	if len(lines) != 3 {
+		t.Fatalf("expected an envelope of 3 lines, got %q", lines)
+	}
+	assert.Contains(t, lines[0], `"sent_at":"2024-01-02T03:04:05Z"`)
+	assert.Contains(t, lines[1], `"type":"event"`)
+
+	var event sentryEvent
+	if err := json.Unmarshal([]byte(lines[2]), &event); err != nil {
+		t.Fatalf("decoding event: %v", err)
+	}
+	assert.Len(t, event.EventID, 32)
+	assert.Equal(t, "fatal", event.Level)
+	assert.Equal(t, "dev", event.Environment)
+	assert.Equal(t, map[string]string{"path": "/lambda/user", "request_id": "request-1", "tenant_id": "tenant-a"}, event.Tags)
+
+	exception := event.Exception.Values[0]
+	assert.Equal(t, "*errors.errorString", exception.Type)
+	assert.Equal(t, "panic: db down", exception.Value)
+	assert.Equal(t, sentryMechanism{Type: "recovery", Handled: false}, exception.Mechanism)
+	frames := exception.Stacktrace.Frames
+	assert.Equal(t, "github.com/captechconsulting/go-microservice-templates/lambda/internal/reporting.panicking", frames[len(frames)-1].Function)
+	assert.True(t, frames[len(frames)-1].InApp)
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/auth"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/lambda/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type apiKeyTestSuit struct {
+	suite.Suite
+	service *APIKeyService
+	dbMock  sqlmock.Sqlmock
+}
+
+func TestAPIKeyTestSuit(t *testing.T) {
+	suite.Run(t, new(apiKeyTestSuit))
+}
+
+func (s *apiKeyTestSuit) SetupSuite() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(s.T(), err)
+
+	s.dbMock = mock
+	s.service = NewAPIKeyService(db)
+}
+
+func (s *apiKeyTestSuit) TearDownSuite() {
+	_ = s.service.database.Close()
+}
+
+func (s *apiKeyTestSuit) TestCreateAPIKey() {
+	t := s.T()
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	keyIn := models.APIKey{
+		Owner:     "billing-batch",
+		Roles:     []string{"Employee"},
+		Scopes:    []string{"users:read", "users:write"},
+		ExpiresAt: expiresAt,
+	}
+
+	testCases := map[string]struct {
+		mockCalled    bool
+		mockReturnErr error
+		ctx           context.Context
+		expectedError error
+	}{
+		"api key created": {
+			mockCalled: true,
+			ctx:        tenant.WithID(context.Background(), "tenant-a"),
+		},
+		"Error creating api key": {
+			mockCalled:    true,
+			mockReturnErr: errors.New("test"),
+			ctx:           tenant.WithID(context.Background(), "tenant-a"),
+			expectedError: fmt.Errorf("[in services.CreateAPIKey] failed to create api key: %w", errors.New("test")),
+		},
+		"Missing tenant": {
+			mockCalled:    false,
+			ctx:           context.Background(),
+			expectedError: fmt.Errorf("[in services.CreateAPIKey]: %w", tenant.ErrMissing),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			exp := `
+				INSERT INTO "api_keys" (
+					"tenant_id",
+					"prefix",
+					"hash",
+					"owner",
+					"roles",
+					"scopes",
+					"expires_at"
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+				RETURNING
+					"id",
+					"created_at"
+			`
+			if tc.mockCalled {
+				s.dbMock.
+					ExpectQuery(regexp.QuoteMeta(exp)).
+					WithArgs("tenant-a", sqlmock.AnyArg(), sqlmock.AnyArg(), "billing-batch", "Employee", "users:read users:write", expiresAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, createdAt)).
+					WillReturnError(tc.mockReturnErr)
+			}
+
+			key, plain, err := s.service.CreateAPIKey(tc.ctx, keyIn)
+
+			assert.Equal(t, tc.expectedError, err, "errors did not match")
+			if tc.expectedError == nil {
+				prefix, _, err := auth.ParseAPIKey(plain)
+				assert.NoError(t, err)
+				assert.Equal(t, prefix, key.Prefix)
+				assert.Equal(t, uint(1), key.ID)
+				assert.Equal(t, createdAt, key.CreatedAt)
+				assert.Equal(t, keyIn.Owner, key.Owner)
+			} else {
+				assert.Equal(t, models.APIKey{}, key)
+				assert.Empty(t, plain)
+			}
+
+			err = s.dbMock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func (s *apiKeyTestSuit) TestListAPIKeys() {
+	t := s.T()
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	revokedAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	keys := []models.APIKey{
+		{ID: 1, Prefix: "0a1b2c3d", Owner: "billing-batch", Roles: []string{"Employee"}, Scopes: []string{"users:read"}, CreatedAt: createdAt},
+		{ID: 2, Prefix: "4e5f6a7b", Owner: "reporting", Roles: []string{}, Scopes: []string{"users:read"}, RevokedAt: revokedAt, CreatedAt: createdAt},
+	}
+	columns := []string{"id", "prefix", "owner", "roles", "scopes", "expires_at", "last_used_at", "revoked_at", "created_at"}
+
+	testCases := map[string]struct {
+		mockCalled     bool
+		mockReturn     *sqlmock.Rows
+		mockReturnErr  error
+		ctx            context.Context
+		expectedReturn []models.APIKey
+		expectedError  error
+	}{
+		"Return slice of api keys": {
+			mockCalled: true,
+			mockReturn: sqlmock.NewRows(columns).
+				AddRow(1, "0a1b2c3d", "billing-batch", "Employee", "users:read", nil, nil, nil, createdAt).
+				AddRow(2, "4e5f6a7b", "reporting", "", "users:read", nil, nil, revokedAt, createdAt),
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			expectedReturn: keys,
+		},
+		"Error getting api keys": {
+			mockCalled:     true,
+			mockReturn:     &sqlmock.Rows{},
+			mockReturnErr:  errors.New("test"),
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			expectedReturn: []models.APIKey{},
+			expectedError:  fmt.Errorf("[in services.ListAPIKeys] failed to get api keys: %w", errors.New("test")),
+		},
+		"Missing tenant": {
+			mockCalled:     false,
+			ctx:            context.Background(),
+			expectedReturn: []models.APIKey{},
+			expectedError:  fmt.Errorf("[in services.ListAPIKeys]: %w", tenant.ErrMissing),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			exp := `
+				SELECT
+					"id",
+					"prefix",
+					"owner",
+					"roles",
+					"scopes",
+					"expires_at",
+					"last_used_at",
+					"revoked_at",
+					"created_at"
+				FROM
+					"api_keys"
+				WHERE
+					"tenant_id" = $1
+				ORDER BY
+					"id"
+			`
+			if tc.mockCalled {
+				s.dbMock.
+					ExpectQuery(regexp.QuoteMeta(exp)).
+					WithArgs("tenant-a").
+					WillReturnRows(tc.mockReturn).
+					WillReturnError(tc.mockReturnErr)
+			}
+
+			actualReturn, err := s.service.ListAPIKeys(tc.ctx)
+
+			assert.Equal(t, tc.expectedError, err, "errors did not match")
+			assert.Equal(t, tc.expectedReturn, actualReturn, "returned data does not match")
+
+			err = s.dbMock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func (s *apiKeyTestSuit) TestRevokeAPIKey() {
+	t := s.T()
+
+	testCases := map[string]struct {
+		mockCalled    bool
+		mockReturn    driver.Result
+		mockReturnErr error
+		ctx           context.Context
+		expectedError error
+	}{
+		"api key revoked": {
+			mockCalled: true,
+			mockReturn: sqlmock.NewResult(0, 1),
+			ctx:        tenant.WithID(context.Background(), "tenant-a"),
+		},
+		"api key not found": {
+			mockCalled:    true,
+			mockReturn:    sqlmock.NewResult(0, 0),
+			ctx:           tenant.WithID(context.Background(), "tenant-a"),
+			expectedError: fmt.Errorf("[in services.RevokeAPIKey] api key 1: %w", ErrNotFound),
+		},
+		"Error revoking api key": {
+			mockCalled:    true,
+			mockReturnErr: errors.New("test"),
+			ctx:           tenant.WithID(context.Background(), "tenant-a"),
+			expectedError: fmt.Errorf("[in services.RevokeAPIKey] failed to revoke api key: %w", errors.New("test")),
+		},
+		"Missing tenant": {
+			mockCalled:    false,
+			ctx:           context.Background(),
+			expectedError: fmt.Errorf("[in services.RevokeAPIKey]: %w", tenant.ErrMissing),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			exp := `
+				UPDATE
+					"api_keys"
+				SET
+					"revoked_at" = NOW()
+				WHERE
+					"id" = $1
+					AND "tenant_id" = $2
+					AND "revoked_at" IS NULL
+			`
+			if tc.mockCalled {
+				s.dbMock.
+					ExpectExec(regexp.QuoteMeta(exp)).
+					WithArgs(1, "tenant-a").
+					WillReturnResult(tc.mockReturn).
+					WillReturnError(tc.mockReturnErr)
+			}
+
+			err := s.service.RevokeAPIKey(tc.ctx, 1)
+
+			assert.Equal(t, tc.expectedError, err, "errors did not match")
+
+			err = s.dbMock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func (s *apiKeyTestSuit) TestValidateAPIKey() {
+	t := s.T()
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	assert.NoError(t, err)
+	_, _, otherHash, err := auth.GenerateAPIKey()
+	assert.NoError(t, err)
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "tenant_id", "hash", "owner", "roles", "scopes", "expires_at"}
+	principal := auth.Principal{
+		Subject:   "billing-batch",
+		Tenant:    "tenant-a",
+		Roles:     []string{"Employee"},
+		Scopes:    []string{"users:read", "users:write"},
+		Method:    auth.MethodAPIKey,
+		KeyPrefix: prefix,
+		ExpiresAt: expiresAt,
+	}
+
+	testCases := map[string]struct {
+		key             string
+		queryCalled     bool
+		queryReturn     *sqlmock.Rows
+		queryReturnErr  error
+		updateCalled    bool
+		updateReturnErr error
+		expectedReturn  auth.Principal
+		expectedError   error
+	}{
+		"valid key": {
+			key:            key,
+			queryCalled:    true,
+			queryReturn:    sqlmock.NewRows(columns).AddRow(7, "tenant-a", hash, "billing-batch", "Employee", "users:read users:write", expiresAt),
+			updateCalled:   true,
+			expectedReturn: principal,
+		},
+		"malformed key": {
+			key:           "not-a-key",
+			expectedError: auth.ErrInvalidAPIKey,
+		},
+		"unknown, revoked or expired key": {
+			key:           key,
+			queryCalled:   true,
+			queryReturn:   sqlmock.NewRows(columns),
+			expectedError: auth.ErrInvalidAPIKey,
+		},
+		"secret mismatch": {
+			key:           key,
+			queryCalled:   true,
+			queryReturn:   sqlmock.NewRows(columns).AddRow(7, "tenant-a", otherHash, "billing-batch", "Employee", "users:read", nil),
+			expectedError: auth.ErrInvalidAPIKey,
+		},
+		"Error getting api key": {
+			key:            key,
+			queryCalled:    true,
+			queryReturn:    &sqlmock.Rows{},
+			queryReturnErr: errors.New("test"),
+			expectedError:  fmt.Errorf("[in services.ValidateAPIKey] failed to get api key: %w", errors.New("test")),
+		},
+		"Error recording use": {
+			key:             key,
+			queryCalled:     true,
+			queryReturn:     sqlmock.NewRows(columns).AddRow(7, "tenant-a", hash, "billing-batch", "Employee", "users:read", nil),
+			updateCalled:    true,
+			updateReturnErr: errors.New("test"),
+			expectedError:   fmt.Errorf("[in services.ValidateAPIKey] failed to record use: %w", errors.New("test")),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			query := `
+				SELECT
+					"id",
+					"tenant_id",
+					"hash",
+					"owner",
+					"roles",
+					"scopes",
+					"expires_at"
+				FROM
+					"api_keys"
+				WHERE
+					"prefix" = $1
+					AND "revoked_at" IS NULL
+					AND ("expires_at" IS NULL OR "expires_at" > NOW())
+			`
+			update := `
+				UPDATE
+					"api_keys"
+				SET
+					"last_used_at" = NOW()
+				WHERE
+					"id" = $1
+			`
+			if tc.queryCalled {
+				s.dbMock.
+					ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(prefix).
+					WillReturnRows(tc.queryReturn).
+					WillReturnError(tc.queryReturnErr)
+			}
+			if tc.updateCalled {
+				s.dbMock.
+					ExpectExec(regexp.QuoteMeta(update)).
+					WithArgs(7).
+					WillReturnResult(sqlmock.NewResult(0, 1)).
+					WillReturnError(tc.updateReturnErr)
+			}
+
+			actualReturn, err := s.service.ValidateAPIKey(context.Background(), tc.key)
+
+			if errors.Is(tc.expectedError, auth.ErrInvalidAPIKey) {
+				assert.True(t, errors.Is(err, auth.ErrInvalidAPIKey), "expected %v, got %v", tc.expectedError, err)
+			} else {
+				assert.Equal(t, tc.expectedError, err, "errors did not match")
+			}
+			assert.Equal(t, tc.expectedReturn, actualReturn, "returned data does not match")
+
+			err = s.dbMock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrMissing is returned when no tenant has been resolved for the current context.
+var ErrMissing = errors.New("tenant not found in context")
+
+type contextKey struct{}
+
+// WithID returns a copy of ctx that carries the given tenant ID.
+func WithID(ctx context.Context, ID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, ID)
+}
+
+// FromContext returns the tenant ID stored in ctx. The boolean is false if no tenant, or an empty
+// tenant, was stored.
+func FromContext(ctx context.Context) (string, bool) {
+	ID, ok := ctx.Value(contextKey{}).(string)
+	return ID, ok && ID != ""
+}
@@ -0,0 +1,41 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	tests := map[string]struct {
+		ctx        context.Context
+		expectedID string
+		expectedOK bool
+	}{
+		"tenant present": {
+			ctx:        WithID(context.Background(), "tenant-a"),
+			expectedID: "tenant-a",
+			expectedOK: true,
+		},
+		"tenant missing": {
+			ctx:        context.Background(),
+			expectedID: "",
+			expectedOK: false,
+		},
+		"tenant empty": {
+			ctx:        WithID(context.Background(), ""),
+			expectedID: "",
+			expectedOK: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ID, ok := FromContext(tc.ctx)
+
+			assert.Equal(t, tc.expectedID, ID)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
@@ -5,16 +5,25 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/config"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/database"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/encryption"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/handlers"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/metrics"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/middleware"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/redact"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/reporting"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/services"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/telemetry"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 func main() {
@@ -33,22 +42,72 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("[in main.run] failed to load config: %w", err)
 	}
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: cfg.LogLevel,
-	}))
+	// sensitive attributes are masked before they reach CloudWatch, and every line logged with a
+	// record's context is tagged with the Lambda request ID and the trace ID of the record
+	logHandler, err := logging.NewHandler(os.Stdout, logging.Options{
+		Format:    cfg.LogFormat,
+		Level:     cfg.LogLevel,
+		AddSource: cfg.LogAddSource,
+	})
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
+	logger := slog.New(redact.NewHandler(logHandler, redact.NewPolicy(cfg.LogRedactKeys, cfg.LogRedactAllow)))
+
+	// handlers and middleware log with logging.FromContext, which falls back to the default logger
+	slog.SetDefault(logger)
+
+	// spans are exported as configured by TRACING_EXPORTER, see telemetry.NewTracerProvider
+	tracerProvider, err := telemetry.NewTracerProvider(ctx, cfg.TracingExporter, cfg.TracingServiceName)
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
+	defer func() {
+		if err = tracerProvider.Shutdown(ctx); err != nil {
+			logger.Error("Error shutting down tracer provider", "err", err)
+		}
+	}()
+
+	// panics are logged with their stack, and sent to Sentry when ERROR_REPORTING_DSN is set
+	var reporter reporting.ErrorReporter = reporting.NewLogReporter()
+	if cfg.ErrorReportingDSN != "" {
+		sentry, err := reporting.NewSentryReporter(
+			&http.Client{Timeout: time.Duration(cfg.ErrorReportingTimeout) * time.Second},
+			cfg.ErrorReportingDSN,
+			cfg.Env,
+		)
+		if err != nil {
+			return fmt.Errorf("[in main.run]: %w", err)
+		}
+		reporter = reporting.Multi(reporter, sentry)
+	}
+
+	// metrics are written to stdout in CloudWatch Embedded Metric Format, see metrics.Emitter
+	emitter := metrics.NewEmitter(os.Stdout, cfg.MetricsNamespace, map[string]string{
+		"FunctionName": lambdacontext.FunctionName,
+	})
+
+	// with IAM auth, connections authenticate with short-lived tokens instead of DATABASE_PASSWORD
+	var dbCredentials database.Credentials = cfg.DBCredentials
+	if cfg.DBIAMAuth {
+		if dbCredentials, err = database.NewIAMCredentials(ctx, cfg.DBHost, cfg.DBPort, cfg.DBUser); err != nil {
+			return fmt.Errorf("[in main.run]: %w", err)
+		}
+	}
 
 	db, err := database.New(
 		ctx,
-		fmt.Sprintf(
-			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-			cfg.DBHost,
-			cfg.DBUser,
-			cfg.DBPassword,
-			cfg.DBName,
-			cfg.DBPort,
-		),
+		database.DSN{
+			Host:        cfg.DBHost,
+			Port:        cfg.DBPort,
+			Name:        cfg.DBName,
+			SSLMode:     cfg.DBSSLMode,
+			SSLRootCert: cfg.DBSSLRootCert,
+		},
+		dbCredentials,
 		logger,
 		time.Duration(cfg.DBRetryDuration)*time.Second,
+		database.WithSlowQueryThreshold(time.Duration(cfg.DBSlowQueryThreshold)*time.Millisecond),
 	)
 	if err != nil {
 		return fmt.Errorf("[in main.run]: %w", err)
@@ -60,19 +119,49 @@ func run(ctx context.Context) error {
 		}
 	}()
 
-	service := services.NewUserService(db)
+	// user names are encrypted at rest under data keys wrapped by the configured key provider
+	keys, err := encryption.NewKeyProvider(ctx, cfg.EncryptionKeyProvider, cfg.EncryptionKeyFile, cfg.EncryptionKMSKeyID)
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
+	envelope, err := encryption.NewEnvelope(keys, cfg.EncryptionIndexKey)
+	if err != nil {
+		return fmt.Errorf("[in main.run]: %w", err)
+	}
 
-	handler := handlers.HandleCreateUsers(logger, service)
+	service := services.NewUserService(db, envelope)
 
-	handler = middleware.AddToHandler[events.SQSEvent, handlers.ReturnFailures](
-		handler,
-		middleware.Recovery[events.SQSEvent, handlers.ReturnFailures](logger),
-		middleware.RecoveryReturn[events.SQSEvent, handlers.ReturnFailures](logger, func() handlers.ReturnFailures {
-			return handlers.ReturnFailures{}
-		}),
-	)
+	handler := handlers.HandleCreateUsers(service, cfg.TenantAttribute)
+	handler = withMiddleware(handler, logger, emitter, tracerProvider, reporter, cfg.LogDebugAttribute)
 
 	lambda.Start(handler)
 
 	return nil
 }
+
+// withMiddleware wraps handler with the middleware of the function. debugAttribute names the
+// message attribute that enables logging at every level, see handlers.DebugAttribute.
+func withMiddleware(
+	handler handlers.HandlerFunc,
+	logger *slog.Logger,
+	emitter *metrics.Emitter,
+	tracerProvider *sdktrace.TracerProvider,
+	reporter reporting.ErrorReporter,
+	debugAttribute string,
+) handlers.HandlerFunc {
+	return middleware.AddToHandler[events.SQSEvent, handlers.ReturnFailures](
+		handler,
+		middleware.Logger[events.SQSEvent, handlers.ReturnFailures](logger),
+		// metrics come first, so they cover the whole batch rather than each record
+		middleware.Metrics(emitter, handlers.BatchMetrics),
+		// before tracing, which handles each record separately: a panic leaves it unknown which
+		// records of the batch were processed, so all of them are reported as failed
+		middleware.Recovery(reporter, handlers.FailBatch),
+		// each record is handled in its own span, the failures of all records are reported together
+		middleware.Tracing(tracerProvider, handlers.ReturnFailures.Merge, handlers.FailBatch),
+		// after tracing, so only records carrying LOG_DEBUG_ATTRIBUTE are logged at every level
+		middleware.DebugLogging[events.SQSEvent, handlers.ReturnFailures](handlers.DebugAttribute(debugAttribute)),
+		// bodies of flagged records are logged too, with their sensitive fields masked
+		middleware.DebugBodies[handlers.ReturnFailures](),
+	)
+}
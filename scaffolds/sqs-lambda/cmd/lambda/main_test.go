@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/handlers"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/metrics"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/reporting"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithMiddlewarePanic(t *testing.T) {
+	var out bytes.Buffer
+	emitter := metrics.NewEmitter(&out, "Users", nil)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// the handler is called once per record, and panics on the second
+	handler := withMiddleware(
+		func(ctx context.Context, sqsEvent events.SQSEvent) (handlers.ReturnFailures, error) {
+			if sqsEvent.Records[0].MessageId == "2" {
+				panic("something went wrong")
+			}
+			return handlers.ReturnFailures{}, nil
+		},
+		logger, emitter, provider, reporting.NewLogReporter(), "debug",
+	)
+
+	response, err := handler(context.Background(), events.SQSEvent{Records: []events.SQSMessage{
+		{MessageId: "1"}, {MessageId: "2"}, {MessageId: "3"},
+	}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, handlers.ReturnFailures{BatchItemFailures: []handlers.FailedItems{
+		{ItemIdentifier: "1"}, {ItemIdentifier: "2"}, {ItemIdentifier: "3"},
+	}}, response)
+
+	var line map[string]any
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("decoding metrics %q: %v", out.String(), err)
+	}
+	assert.Equal(t, 1.0, line["Panics"])
+	assert.Equal(t, 3.0, line["RecordsFailed"])
+	assert.Equal(t, 0.0, line["RecordsProcessed"])
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+	}
+}
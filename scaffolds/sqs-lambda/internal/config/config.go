@@ -1,9 +1,16 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"time"
 
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
 )
@@ -11,25 +18,102 @@ import (
 // Configuration holds the application configuration settings. The configuration is loaded from
 // environment variables.
 type Configuration struct {
-	Env             string     `env:"ENV,required,required"`
-	LogLevel        slog.Level `env:"LOG_LEVEL,required,required"`
-	DBName          string     `env:"DATABASE_NAME,required"`
-	DBUser          string     `env:"DATABASE_USER,required"`
-	DBPassword      string     `env:"DATABASE_PASSWORD,required"`
-	DBHost          string     `env:"DATABASE_HOST,required"`
-	DBPort          string     `env:"DATABASE_PORT,required"`
-	DBRetryDuration int        `env:"DATABASE_RETRY_DURATION_SECONDS,required"`
+	Env                   string     `env:"ENV,required,required"`
+	LogLevel              slog.Level `env:"LOG_LEVEL,required,required"`
+	LogFormat             string     `env:"LOG_FORMAT" envDefault:"json"`
+	LogAddSource          bool       `env:"LOG_ADD_SOURCE" envDefault:"false"`
+	LogDebugAttribute     string     `env:"LOG_DEBUG_ATTRIBUTE" envDefault:"debug"`
+	LogRedactKeys         []string   `env:"LOG_REDACT_KEYS" envDefault:"password,secret,token,authorization,cookie,api_key,first_name,last_name"`
+	LogRedactAllow        []string   `env:"LOG_REDACT_ALLOW"`
+	TracingExporter       string     `env:"TRACING_EXPORTER" envDefault:"none"`
+	TracingServiceName    string     `env:"TRACING_SERVICE_NAME" envDefault:"user-microservice"`
+	ErrorReportingDSN     string     `env:"ERROR_REPORTING_DSN" log:"sensitive"`
+	ErrorReportingTimeout int        `env:"ERROR_REPORTING_TIMEOUT_SECONDS" envDefault:"2"`
+	MetricsNamespace      string     `env:"METRICS_NAMESPACE" envDefault:"UserMicroservice"`
+	DBName                string     `env:"DATABASE_NAME,required"`
+	DBUser                string     `env:"DATABASE_USER,required"`
+	DBPassword            string     `env:"DATABASE_PASSWORD" log:"sensitive"`
+	DBHost                string     `env:"DATABASE_HOST,required"`
+	DBPort                string     `env:"DATABASE_PORT,required"`
+	DBRetryDuration       int        `env:"DATABASE_RETRY_DURATION_SECONDS,required"`
+	DBSlowQueryThreshold  int        `env:"DATABASE_SLOW_QUERY_MILLISECONDS" envDefault:"200"`
+	DBSSLMode             string     `env:"DATABASE_SSL_MODE" envDefault:"disable"`
+	DBSSLRootCert         string     `env:"DATABASE_SSL_ROOT_CERT"`
+	DBIAMAuth             bool       `env:"DATABASE_IAM_AUTH"`
+	TenantAttribute       string     `env:"TENANT_MESSAGE_ATTRIBUTE" envDefault:"tenant_id"`
+	EncryptionKeyProvider string     `env:"ENCRYPTION_KEY_PROVIDER" envDefault:"file"`
+	EncryptionKeyFile     string     `env:"ENCRYPTION_KEY_FILE" envDefault:"keys.local.json"`
+	EncryptionKMSKeyID    string     `env:"ENCRYPTION_KMS_KEY_ID"`
+	EncryptionIndexKey    string     `env:"ENCRYPTION_INDEX_KEY" log:"sensitive"`
+
+	// DBCredentials resolves DBUser and DBPassword again when the database rejects them, so
+	// rotated secrets are picked up. It is set by New.
+	DBCredentials *DBCredentials `env:"-"`
+}
+
+// secretSettings selects the SecretProvider secret references are resolved with. They are read
+// before, and cannot themselves be, secret references.
+type secretSettings struct {
+	Provider string `env:"SECRETS_PROVIDER"`
+	File     string `env:"SECRETS_FILE" envDefault:"secrets.local.json"`
+	CacheTTL int    `env:"SECRETS_CACHE_TTL_SECONDS" envDefault:"300"`
 }
 
 // New loads the configuration settings from environment variables and .env file, and returns a
-// Configuration struct.
+// Configuration struct. Values of the form `secret://<name>#<key>` are resolved, see
+// ResolveSecret, with the provider named by `SECRETS_PROVIDER`: `secretsmanager`, `ssm` or `file`.
 func New() (Configuration, error) {
 	_ = godotenv.Load()
+	ctx := context.Background()
+
+	settings, err := env.ParseAs[secretSettings]()
+	if err != nil {
+		return Configuration{}, fmt.Errorf("[in config.New] failed to parse secret settings: %w", err)
+	}
+	provider, err := newSecretProvider(ctx, settings)
+	if err != nil {
+		return Configuration{}, fmt.Errorf("[in config.New]: %w", err)
+	}
+	rawEnvironment := env.ToMap(os.Environ())
+	environment, err := resolveSecrets(ctx, provider, rawEnvironment)
+	if err != nil {
+		return Configuration{}, fmt.Errorf("[in config.New] failed to resolve secrets: %w", err)
+	}
 
-	cfg, err := env.ParseAs[Configuration]()
+	cfg, err := env.ParseAsWithOptions[Configuration](env.Options{Environment: environment})
 	if err != nil {
 		return Configuration{}, fmt.Errorf("[in config.New] failed to parse config: %w", err)
 	}
 
+	if cfg.DBPassword == "" && !cfg.DBIAMAuth {
+		return Configuration{}, errors.New("[in config.New] DATABASE_PASSWORD is required unless DATABASE_IAM_AUTH is set")
+	}
+	cfg.DBCredentials = NewDBCredentials(provider, rawEnvironment["DATABASE_USER"], rawEnvironment["DATABASE_PASSWORD"])
+
 	return cfg, nil
 }
+
+// newSecretProvider returns the cached provider selected by settings, or nil if none is selected.
+func newSecretProvider(ctx context.Context, settings secretSettings) (SecretProvider, error) {
+	var provider SecretProvider
+	switch settings.Provider {
+	case "":
+		return nil, nil
+	case "file":
+		provider = NewFileSecretProvider(settings.File)
+	case "secretsmanager", "ssm":
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("[in config.newSecretProvider] failed to load AWS config: %w", err)
+		}
+		if settings.Provider == "ssm" {
+			provider = NewSSMProvider(ssm.NewFromConfig(awsCfg))
+		} else {
+			provider = NewSecretsManagerProvider(secretsmanager.NewFromConfig(awsCfg))
+		}
+	default:
+		return nil, fmt.Errorf("[in config.newSecretProvider] unknown secret provider %q", settings.Provider)
+	}
+
+	return NewSecretCache(provider, time.Duration(settings.CacheTTL)*time.Second), nil
+}
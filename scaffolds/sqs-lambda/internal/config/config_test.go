@@ -3,12 +3,19 @@ package config
 import (
 	"log/slog"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNewConfiguration(t *testing.T) {
+	secretsFile := filepath.Join(t.TempDir(), "secrets.json")
+	err := os.WriteFile(secretsFile, []byte(`{"test/db": {"username": "test_user", "password": "test_password"}}`), 0o600)
+	if err != nil {
+		t.Fatalf("writing secrets file: %v", err)
+	}
+
 	tests := map[string]struct {
 		envVars       map[string]string
 		expectedCfg   Configuration
@@ -26,17 +33,131 @@ func TestNewConfiguration(t *testing.T) {
 				"DATABASE_RETRY_DURATION_SECONDS": "10",
 			},
 			expectedCfg: Configuration{
-				Env:             "development",
-				LogLevel:        slog.LevelInfo,
-				DBName:          "test_db",
-				DBUser:          "test_user",
-				DBPassword:      "test_password",
-				DBHost:          "localhost",
-				DBPort:          "5432",
-				DBRetryDuration: 10,
+				Env:                   "development",
+				LogLevel:              slog.LevelInfo,
+				LogFormat:             "json",
+				LogDebugAttribute:     "debug",
+				LogRedactKeys:         []string{"password", "secret", "token", "authorization", "cookie", "api_key", "first_name", "last_name"},
+				TracingExporter:       "none",
+				TracingServiceName:    "user-microservice",
+				ErrorReportingTimeout: 2,
+				MetricsNamespace:      "UserMicroservice",
+				DBName:                "test_db",
+				DBUser:                "test_user",
+				DBPassword:            "test_password",
+				DBHost:                "localhost",
+				DBPort:                "5432",
+				DBRetryDuration:       10,
+				DBSlowQueryThreshold:  200,
+				DBSSLMode:             "disable",
+				TenantAttribute:       "tenant_id",
+				EncryptionKeyProvider: "file",
+				EncryptionKeyFile:     "keys.local.json",
+			},
+			expectedError: false,
+		},
+		"secret references resolved": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "secret://test/db#username",
+				"DATABASE_PASSWORD":               "secret://test/db#password",
+				"SECRETS_PROVIDER":                "file",
+				"SECRETS_FILE":                    secretsFile,
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+			},
+			expectedCfg: Configuration{
+				Env:                   "development",
+				LogLevel:              slog.LevelInfo,
+				LogFormat:             "json",
+				LogDebugAttribute:     "debug",
+				LogRedactKeys:         []string{"password", "secret", "token", "authorization", "cookie", "api_key", "first_name", "last_name"},
+				TracingExporter:       "none",
+				TracingServiceName:    "user-microservice",
+				ErrorReportingTimeout: 2,
+				MetricsNamespace:      "UserMicroservice",
+				DBName:                "test_db",
+				DBUser:                "test_user",
+				DBPassword:            "test_password",
+				DBHost:                "localhost",
+				DBPort:                "5432",
+				DBRetryDuration:       10,
+				DBSlowQueryThreshold:  200,
+				DBSSLMode:             "disable",
+				TenantAttribute:       "tenant_id",
+				EncryptionKeyProvider: "file",
+				EncryptionKeyFile:     "keys.local.json",
+			},
+			expectedError: false,
+		},
+		"unknown secret": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_PASSWORD":               "secret://test/missing#password",
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"SECRETS_PROVIDER":                "file",
+				"SECRETS_FILE":                    secretsFile,
+			},
+			expectedCfg:   Configuration{},
+			expectedError: true,
+		},
+		"iam authentication": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_IAM_AUTH":               "true",
+				"DATABASE_HOST":                   "proxy.example.com",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+				"DATABASE_SSL_MODE":               "verify-full",
+			},
+			expectedCfg: Configuration{
+				Env:                   "development",
+				LogLevel:              slog.LevelInfo,
+				LogFormat:             "json",
+				LogDebugAttribute:     "debug",
+				LogRedactKeys:         []string{"password", "secret", "token", "authorization", "cookie", "api_key", "first_name", "last_name"},
+				TracingExporter:       "none",
+				TracingServiceName:    "user-microservice",
+				ErrorReportingTimeout: 2,
+				MetricsNamespace:      "UserMicroservice",
+				DBName:                "test_db",
+				DBUser:                "test_user",
+				DBHost:                "proxy.example.com",
+				DBPort:                "5432",
+				DBRetryDuration:       10,
+				DBSlowQueryThreshold:  200,
+				DBSSLMode:             "verify-full",
+				DBIAMAuth:             true,
+				TenantAttribute:       "tenant_id",
+				EncryptionKeyProvider: "file",
+				EncryptionKeyFile:     "keys.local.json",
 			},
 			expectedError: false,
 		},
+		"missing password": {
+			envVars: map[string]string{
+				"ENV":                             "development",
+				"LOG_LEVEL":                       "info",
+				"DATABASE_NAME":                   "test_db",
+				"DATABASE_USER":                   "test_user",
+				"DATABASE_HOST":                   "localhost",
+				"DATABASE_PORT":                   "5432",
+				"DATABASE_RETRY_DURATION_SECONDS": "10",
+			},
+			expectedCfg:   Configuration{},
+			expectedError: true,
+		},
 		"missing required env": {
 			envVars: map[string]string{
 				"DATABASE_NAME":                   "test_db",
@@ -87,6 +208,8 @@ func TestNewConfiguration(t *testing.T) {
 				assert.Equal(t, tc.expectedCfg, cfg)
 			} else {
 				assert.NoError(t, err)
+				assert.NotNil(t, cfg.DBCredentials)
+				cfg.DBCredentials = nil
 				assert.Equal(t, tc.expectedCfg, cfg)
 			}
 		})
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+// invalidPasswordCode is the SQLSTATE Postgres rejects a login with when the password is wrong.
+const invalidPasswordCode = "28P01"
+
+// Credentials provides the user and password new connections authenticate with.
+type Credentials interface {
+	// Get returns the current user and password.
+	Get(ctx context.Context) (user string, password string, err error)
+	// Refresh fetches the credentials again, bypassing any cache.
+	Refresh(ctx context.Context) (user string, password string, err error)
+}
+
+// connector opens connections with the current credentials. When Postgres rejects them, it
+// refreshes the credentials and tries again once, so a rotated password is picked up without a
+// restart. Open connections keep working, so in-flight queries are not interrupted; the pool fills
+// with connections using the new credentials as old ones are closed.
+type connector struct {
+	dsn         DSN
+	credentials Credentials
+	logger      *slog.Logger
+	open        func(ctx context.Context, connectionString string) (driver.Conn, error)
+
+	// queries observes the queries of every connection, if set
+	queries *queryLog
+
+	// mu serializes refreshes, so concurrent connections rejected for the same stale password
+	// trigger a single refresh.
+	mu       sync.Mutex
+	password string
+}
+
+func newConnector(dsn DSN, credentials Credentials, logger *slog.Logger) *connector {
+	return &connector{
+		dsn:         dsn,
+		credentials: credentials,
+		logger:      logger,
+		open: func(ctx context.Context, connectionString string) (driver.Conn, error) {
+			c, err := pq.NewConnector(connectionString)
+			if err != nil {
+				return nil, err
+			}
+			return c.Connect(ctx)
+		},
+	}
+}
+
+// Connect opens a connection, refreshing the credentials once if they are rejected.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	user, password, err := c.credentials.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in database.connector.Connect]: %w", err)
+	}
+
+	conn, err := c.connect(ctx, user, password)
+	if !isInvalidPassword(err) {
+		return conn, err
+	}
+
+	if user, password, err = c.refresh(ctx, password); err != nil {
+		return nil, fmt.Errorf("[in database.connector.Connect]: %w", err)
+	}
+	return c.connect(ctx, user, password)
+}
+
+// Driver returns the lib/pq driver.
+func (c *connector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+func (c *connector) connect(ctx context.Context, user string, password string) (driver.Conn, error) {
+	dsn := c.dsn
+	dsn.User = user
+	dsn.Password = password
+	conn, err := c.open(ctx, dsn.ConnectionString())
+	if err != nil || c.queries == nil {
+		return conn, err
+	}
+	return c.queries.wrap(conn), nil
+}
+
+// refresh returns refreshed credentials after rejected was refused. If another connection already
+// refreshed them since, the credentials are not fetched again.
+func (c *connector) refresh(ctx context.Context, rejected string) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.password != "" && c.password != rejected {
+		return c.credentials.Get(ctx)
+	}
+
+	c.logger.WarnContext(ctx, "Database rejected credentials, refreshing them")
+	user, password, err := c.credentials.Refresh(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	c.password = password
+
+	return user, password, nil
+}
+
+// isInvalidPassword reports whether err is Postgres rejecting the password.
+func isInvalidPassword(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == invalidPasswordCode
+}
+
+// staticCredentials are credentials that never change.
+type staticCredentials struct {
+	user     string
+	password string
+}
+
+func (s staticCredentials) Get(context.Context) (string, string, error) {
+	return s.user, s.password, nil
+}
+
+func (s staticCredentials) Refresh(context.Context) (string, string, error) {
+	return s.user, s.password, nil
+}
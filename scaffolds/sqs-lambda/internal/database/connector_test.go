@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// rotatingCredentials serve password until refreshed, then rotated.
+type rotatingCredentials struct {
+	mu        sync.Mutex
+	password  string
+	rotated   string
+	refreshes int
+}
+
+func (c *rotatingCredentials) Get(context.Context) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return "db-user", c.password, nil
+}
+
+func (c *rotatingCredentials) Refresh(context.Context) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshes++
+	c.password = c.rotated
+	return "db-user", c.password, nil
+}
+
+type fakeConn struct {
+	driver.Conn
+}
+
+// fakeServer accepts connections authenticating with password.
+func fakeServer(password string, err error) func(context.Context, string) (driver.Conn, error) {
+	return func(_ context.Context, connectionString string) (driver.Conn, error) {
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(connectionString, "password='"+password+"'") {
+			return nil, &pq.Error{Code: invalidPasswordCode, Message: "password authentication failed"}
+		}
+		return fakeConn{}, nil
+	}
+}
+
+func TestConnector(t *testing.T) {
+	dsn := DSN{Host: "localhost", Port: "5432", Name: "db-name", SSLMode: "disable"}
+
+	tests := map[string]struct {
+		credentials       *rotatingCredentials
+		serverPassword    string
+		serverErr         error
+		expectedErr       bool
+		expectedRefreshes int
+	}{
+		"valid credentials": {
+			credentials:       &rotatingCredentials{password: "current", rotated: "next"},
+			serverPassword:    "current",
+			expectedRefreshes: 0,
+		},
+		"rotated password": {
+			credentials:       &rotatingCredentials{password: "old", rotated: "new"},
+			serverPassword:    "new",
+			expectedRefreshes: 1,
+		},
+		"rejected after refresh": {
+			credentials:       &rotatingCredentials{password: "old", rotated: "still-wrong"},
+			serverPassword:    "new",
+			expectedErr:       true,
+			expectedRefreshes: 1,
+		},
+		"other errors not retried": {
+			credentials:       &rotatingCredentials{password: "old", rotated: "new"},
+			serverErr:         errors.New("connection refused"),
+			expectedErr:       true,
+			expectedRefreshes: 0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newConnector(dsn, tc.credentials, slog.Default())
+			c.open = fakeServer(tc.serverPassword, tc.serverErr)
+
+			conn, err := c.Connect(context.Background())
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, conn)
+			}
+			assert.Equal(t, tc.expectedRefreshes, tc.credentials.refreshes)
+		})
+	}
+}
+
+func TestConnectorRefreshesOnce(t *testing.T) {
+	credentials := &rotatingCredentials{password: "old", rotated: "new"}
+	c := newConnector(DSN{Host: "localhost"}, credentials, slog.Default())
+
+	// every connection reaches the server with the stale password before any refresh happens
+	var rejected atomic.Int32
+	var wg sync.WaitGroup
+	ready := make(chan struct{})
+	server := fakeServer("new", nil)
+	c.open = func(ctx context.Context, connectionString string) (driver.Conn, error) {
+		conn, err := server(ctx, connectionString)
+		if err != nil && rejected.Add(1) == 10 {
+			close(ready)
+		}
+		if err != nil {
+			<-ready
+		}
+		return conn, err
+	}
+
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Connect(context.Background())
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, credentials.refreshes)
+}
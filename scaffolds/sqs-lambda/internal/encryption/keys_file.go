@@ -0,0 +1,127 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// keyFile is the format of a local keyfile: base64 encoded AES-256 master keys by ID, and the ID
+// of the key new data keys are wrapped with.
+type keyFile struct {
+	Current string            `json:"current"`
+	Keys    map[string][]byte `json:"keys"`
+}
+
+// LocalKeyProvider wraps data keys with master keys held in memory, read from a keyfile for local
+// development. Data keys are wrapped with AES-GCM.
+type LocalKeyProvider struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewLocalKeyProvider returns a LocalKeyProvider for the AES-256 master keys in keys, wrapping new
+// data keys with the key current.
+func NewLocalKeyProvider(current string, keys map[string][]byte) (*LocalKeyProvider, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("[in encryption.NewLocalKeyProvider] current key %q: %w", current, ErrUnknownKey)
+	}
+
+	p := &LocalKeyProvider{current: current, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("[in encryption.NewLocalKeyProvider] key %q must be %d bytes", id, dataKeySize)
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, fmt.Errorf("[in encryption.NewLocalKeyProvider] key %q: %w", id, err)
+		}
+		p.keys[id] = aead
+	}
+
+	return p, nil
+}
+
+// LoadKeyFile returns a LocalKeyProvider for the keyfile at path, a JSON object of the form
+// `{"current": "<id>", "keys": {"<id>": "<base64 key>"}}`.
+func LoadKeyFile(path string) (*LocalKeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("[in encryption.LoadKeyFile] failed to read keyfile: %w", err)
+	}
+
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("[in encryption.LoadKeyFile] failed to parse keyfile: %w", err)
+	}
+
+	return NewLocalKeyProvider(file.Current, file.Keys)
+}
+
+// GenerateDataKey returns a random data key wrapped by the current master key.
+func (p *LocalKeyProvider) GenerateDataKey(_ context.Context) (DataKey, error) {
+	plaintext := make([]byte, dataKeySize)
+	if _, err := rand.Read(plaintext); err != nil {
+		return DataKey{}, fmt.Errorf("[in encryption.LocalKeyProvider.GenerateDataKey] failed to generate key: %w", err)
+	}
+
+	wrapped, err := seal(p.keys[p.current], plaintext, []byte(p.current))
+	if err != nil {
+		return DataKey{}, fmt.Errorf("[in encryption.LocalKeyProvider.GenerateDataKey]: %w", err)
+	}
+
+	return DataKey{KeyID: p.current, Plaintext: plaintext, Wrapped: wrapped}, nil
+}
+
+// DecryptDataKey unwraps a data key wrapped by the master key keyID.
+func (p *LocalKeyProvider) DecryptDataKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("[in encryption.LocalKeyProvider.DecryptDataKey] %q: %w", keyID, ErrUnknownKey)
+	}
+
+	plaintext, err := open(aead, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("[in encryption.LocalKeyProvider.DecryptDataKey]: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with a random nonce, which is prepended to the ciphertext.
+func seal(aead cipher.AEAD, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts ciphertext produced by seal.
+func open(aead cipher.AEAD, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	return plaintext, nil
+}
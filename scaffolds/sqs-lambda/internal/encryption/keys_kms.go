@@ -0,0 +1,56 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// KMSClient is the part of the KMS client KMSKeyProvider uses.
+type KMSClient interface {
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KMSKeyProvider generates and unwraps data keys with AWS KMS. The master key never leaves KMS.
+// KMS rotates key material transparently; switching to a new key is done by configuring its ID,
+// data keys wrapped by the previous key are unwrapped with the key ID stored alongside them.
+type KMSKeyProvider struct {
+	client KMSClient
+	keyID  string
+}
+
+// NewKMSKeyProvider returns a KMSKeyProvider wrapping new data keys with the KMS key keyID, a key
+// ID, ARN or alias.
+func NewKMSKeyProvider(client KMSClient, keyID string) *KMSKeyProvider {
+	return &KMSKeyProvider{client: client, keyID: keyID}
+}
+
+// GenerateDataKey returns a new data key from KMS. Its KeyID is the ARN of the wrapping key.
+func (p *KMSKeyProvider) GenerateDataKey(ctx context.Context) (DataKey, error) {
+	out, err := p.client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(p.keyID),
+		KeySpec: types.DataKeySpecAes256,
+	})
+	if err != nil {
+		return DataKey{}, fmt.Errorf("[in encryption.KMSKeyProvider.GenerateDataKey] failed to generate data key: %w", err)
+	}
+
+	return DataKey{KeyID: aws.ToString(out.KeyId), Plaintext: out.Plaintext, Wrapped: out.CiphertextBlob}, nil
+}
+
+// DecryptDataKey unwraps a data key with KMS.
+func (p *KMSKeyProvider) DecryptDataKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	out, err := p.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(keyID),
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[in encryption.KMSKeyProvider.DecryptDataKey] failed to decrypt data key: %w", err)
+	}
+
+	return out.Plaintext, nil
+}
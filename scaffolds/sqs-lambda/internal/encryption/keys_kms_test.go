@@ -0,0 +1,92 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/stretchr/testify/assert"
+)
+
+// newKMSStandIn starts a server answering KMS GenerateDataKey and Decrypt requests. Data keys are
+// "wrapped" by prefixing them with the key ARN.
+func newKMSStandIn(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	const keyARN = "arn:aws:kms:us-east-1:123456789012:key/test"
+	dataKey := bytes.Repeat([]byte{9}, 32)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		status, response := http.StatusOK, map[string]any{}
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GenerateDataKey":
+			response = map[string]any{
+				"KeyId":          keyARN,
+				"Plaintext":      dataKey,
+				"CiphertextBlob": append([]byte(keyARN), dataKey...),
+			}
+		case "TrentService.Decrypt":
+			blob, _ := base64.StdEncoding.DecodeString(body["CiphertextBlob"].(string))
+			if body["KeyId"] != keyARN || !bytes.HasPrefix(blob, []byte(keyARN)) {
+				status, response = http.StatusBadRequest, map[string]any{
+					"__type":  "IncorrectKeyException",
+					"message": "The key ID in the request does not identify a CMK that can perform this operation.",
+				}
+				break
+			}
+			response = map[string]any{"KeyId": keyARN, "Plaintext": blob[len(keyARN):]}
+		default:
+			status = http.StatusBadRequest
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestKMSKeyProvider(t *testing.T) {
+	server := newKMSStandIn(t)
+	keys := NewKMSKeyProvider(kms.New(kms.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	}), "alias/user-microservice")
+	ctx := context.Background()
+
+	key, err := keys.GenerateDataKey(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:kms:us-east-1:123456789012:key/test", key.KeyID)
+	assert.Len(t, key.Plaintext, dataKeySize)
+
+	unwrapped, err := keys.DecryptDataKey(ctx, key.KeyID, key.Wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Plaintext, unwrapped)
+
+	_, err = keys.DecryptDataKey(ctx, "arn:aws:kms:us-east-1:123456789012:key/other", key.Wrapped)
+	assert.Error(t, err)
+
+	// values encrypted through KMS round trip
+	envelope, err := NewEnvelope(keys, testIndexKey)
+	assert.NoError(t, err)
+	encrypted, err := envelope.Encrypt(ctx, "Ada", "first_name")
+	assert.NoError(t, err)
+	decrypted, err := envelope.Decrypt(ctx, encrypted, "first_name")
+	assert.NoError(t, err)
+	assert.Equal(t, "Ada", decrypted)
+}
@@ -3,20 +3,20 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/handlers/mock"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/metrics"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/tenant"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestHandleCreateUsers(t *testing.T) {
 	mockService := new(mock.MockUserCreator)
-	logger := slog.Default()
-	handler := HandleCreateUsers(logger, mockService)
+	handler := HandleCreateUsers(mockService, "tenant_id")
 
 	users := []models.User{
 		{ID: 0, FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001},
@@ -29,6 +29,12 @@ func TestHandleCreateUsers(t *testing.T) {
 	}
 
 	ctx := context.TODO()
+	tenantID := "tenant-a"
+	tenantCtx := tenant.WithID(ctx, tenantID)
+
+	tenantAttributes := map[string]events.SQSMessageAttribute{
+		"tenant_id": {DataType: "String", StringValue: &tenantID},
+	}
 
 	type mockDetail struct {
 		mockCalled bool
@@ -40,35 +46,37 @@ func TestHandleCreateUsers(t *testing.T) {
 		mockCalled       bool
 		mockDetails      []mockDetail
 		request          events.SQSEvent
-		expectedResponse returnFailures
+		expectedResponse ReturnFailures
 		expectedError    error
 	}{
 		"no issues - users created": {
 			mockDetails: []mockDetail{
 				{
 					mockCalled: true,
-					mockInput:  []any{ctx, users[0]},
+					mockInput:  []any{tenantCtx, users[0]},
 					mockOutput: []any{1, nil},
 				},
 				{
 					mockCalled: true,
-					mockInput:  []any{ctx, users[1]},
+					mockInput:  []any{tenantCtx, users[1]},
 					mockOutput: []any{2, nil},
 				},
 			},
 			request: events.SQSEvent{
 				Records: []events.SQSMessage{
 					{
-						MessageId: "1",
-						Body:      testutil.ToJSONString(usersIn[0]),
+						MessageId:         "1",
+						Body:              testutil.ToJSONString(usersIn[0]),
+						MessageAttributes: tenantAttributes,
 					},
 					{
-						MessageId: "2",
-						Body:      testutil.ToJSONString(usersIn[1]),
+						MessageId:         "2",
+						Body:              testutil.ToJSONString(usersIn[1]),
+						MessageAttributes: tenantAttributes,
 					},
 				},
 			},
-			expectedResponse: returnFailures{BatchItemFailures: []failedItems(nil)},
+			expectedResponse: ReturnFailures{BatchItemFailures: []FailedItems(nil)},
 			expectedError:    nil,
 		},
 		"one validation issue": {
@@ -80,7 +88,7 @@ func TestHandleCreateUsers(t *testing.T) {
 				},
 				{
 					mockCalled: true,
-					mockInput:  []any{ctx, users[1]},
+					mockInput:  []any{tenantCtx, users[1]},
 					mockOutput: []any{2, nil},
 				},
 			},
@@ -91,14 +99,47 @@ func TestHandleCreateUsers(t *testing.T) {
 						Body: testutil.ToJSONString(
 							inputUser{FirstName: "John", LastName: "Doe", Role: "Person", UserID: 1001},
 						),
+						MessageAttributes: tenantAttributes,
+					},
+					{
+						MessageId:         "2",
+						Body:              testutil.ToJSONString(usersIn[1]),
+						MessageAttributes: tenantAttributes,
+					},
+				},
+			},
+			expectedResponse: ReturnFailures{BatchItemFailures: []FailedItems{{
+				ItemIdentifier: "1",
+			}}},
+			expectedError: nil,
+		},
+		"one missing tenant": {
+			mockDetails: []mockDetail{
+				{
+					mockCalled: false,
+					mockInput:  nil,
+					mockOutput: nil,
+				},
+				{
+					mockCalled: true,
+					mockInput:  []any{tenantCtx, users[1]},
+					mockOutput: []any{2, nil},
+				},
+			},
+			request: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						MessageId: "1",
+						Body:      testutil.ToJSONString(usersIn[0]),
 					},
 					{
-						MessageId: "2",
-						Body:      testutil.ToJSONString(usersIn[1]),
+						MessageId:         "2",
+						Body:              testutil.ToJSONString(usersIn[1]),
+						MessageAttributes: tenantAttributes,
 					},
 				},
 			},
-			expectedResponse: returnFailures{BatchItemFailures: []failedItems{{
+			expectedResponse: ReturnFailures{BatchItemFailures: []FailedItems{{
 				ItemIdentifier: "1",
 			}}},
 			expectedError: nil,
@@ -137,3 +178,76 @@ func TestHandleCreateUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestBatchMetrics(t *testing.T) {
+	tests := map[string]struct {
+		records           int
+		failures          ReturnFailures
+		expectedProcessed float64
+		expectedFailed    float64
+	}{
+		"all processed": {
+			records:           3,
+			failures:          ReturnFailures{},
+			expectedProcessed: 3,
+			expectedFailed:    0,
+		},
+		"some failed": {
+			records:           3,
+			failures:          ReturnFailures{BatchItemFailures: []FailedItems{{ItemIdentifier: "1"}, {ItemIdentifier: "2"}}},
+			expectedProcessed: 1,
+			expectedFailed:    2,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := metrics.NewRecorder()
+			BatchMetrics(recorder, events.SQSEvent{Records: make([]events.SQSMessage, tc.records)}, tc.failures)
+
+			processed, _ := recorder.Value("RecordsProcessed")
+			failed, _ := recorder.Value("RecordsFailed")
+			assert.Equal(t, tc.expectedProcessed, processed)
+			assert.Equal(t, tc.expectedFailed, failed)
+		})
+	}
+}
+
+func TestDebugAttribute(t *testing.T) {
+	record := func(value string) events.SQSMessage {
+		return events.SQSMessage{MessageAttributes: map[string]events.SQSMessageAttribute{
+			"debug": {DataType: "String", StringValue: &value},
+		}}
+	}
+
+	tests := map[string]struct {
+		records  []events.SQSMessage
+		expected bool
+	}{
+		"no attribute": {
+			records:  []events.SQSMessage{{MessageId: "1"}},
+			expected: false,
+		},
+		"false attribute": {
+			records:  []events.SQSMessage{record("false"), record("yes")},
+			expected: false,
+		},
+		"one record flagged": {
+			records:  []events.SQSMessage{{MessageId: "1"}, record("true")},
+			expected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			enabled := DebugAttribute("debug")(context.Background(), events.SQSEvent{Records: tc.records})
+			assert.Equal(t, tc.expected, enabled)
+		})
+	}
+}
+
+func TestFailBatch(t *testing.T) {
+	failures := FailBatch(events.SQSEvent{Records: []events.SQSMessage{{MessageId: "1"}, {MessageId: "2"}}})
+
+	assert.Equal(t, ReturnFailures{BatchItemFailures: []FailedItems{{ItemIdentifier: "1"}, {ItemIdentifier: "2"}}}, failures)
+}
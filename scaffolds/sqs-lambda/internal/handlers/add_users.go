@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"context"
-	"log/slog"
+	"strconv"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/metrics"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/tenant"
 )
 
 type FailedItems struct {
@@ -15,20 +18,80 @@ type FailedItems struct {
 type ReturnFailures struct {
 	BatchItemFailures []FailedItems `json:"batchItemFailures"`
 }
+
+// Merge returns the failures of r and other.
+func (r ReturnFailures) Merge(other ReturnFailures) ReturnFailures {
+	return ReturnFailures{BatchItemFailures: append(r.BatchItemFailures, other.BatchItemFailures...)}
+}
+
+// BatchMetrics is an observe function for middleware.Metrics that counts the records of a batch
+// that were processed and failed: `RecordsProcessed` and `RecordsFailed`.
+func BatchMetrics(recorder *metrics.Recorder, sqsEvent events.SQSEvent, failures ReturnFailures) {
+	recorder.Put("RecordsProcessed", float64(len(sqsEvent.Records)-len(failures.BatchItemFailures)), metrics.Count)
+	recorder.Put("RecordsFailed", float64(len(failures.BatchItemFailures)), metrics.Count)
+}
+
+// FailBatch returns failures for every record of sqsEvent, so SQS delivers them again. It is the
+// response of middleware.Recovery, as it is unknown which records were processed before a panic,
+// and of middleware.Tracing for a record whose handler returned an error.
+func FailBatch(sqsEvent events.SQSEvent) ReturnFailures {
+	failures := make([]FailedItems, 0, len(sqsEvent.Records))
+	for _, record := range sqsEvent.Records {
+		failures = append(failures, FailedItems{ItemIdentifier: record.MessageId})
+	}
+	return ReturnFailures{BatchItemFailures: failures}
+}
+
+// DebugAttribute is an enabled function for middleware.DebugLogging that flags batches in which a
+// record carries the message attribute named name set to a true value, e.g. `true` or `1`.
+func DebugAttribute(name string) func(ctx context.Context, sqsEvent events.SQSEvent) bool {
+	return func(_ context.Context, sqsEvent events.SQSEvent) bool {
+		for _, record := range sqsEvent.Records {
+			value := record.MessageAttributes[name].StringValue
+			if value == nil {
+				continue
+			}
+			if enabled, err := strconv.ParseBool(*value); err == nil && enabled {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 type userCreator interface {
 	CreateUser(ctx context.Context, user models.User) (int, error)
 }
 
-// HandleCreateUsers adds users from an SQS event,
-func HandleCreateUsers(logger *slog.Logger, service userCreator) HandlerFunc {
+// HandleCreateUsers adds users from an SQS event. The tenant for each record is read from the
+// message attribute named by tenantAttribute, records without one are reported as failures.
+func HandleCreateUsers(service userCreator, tenantAttribute string) HandlerFunc {
 	return func(ctx context.Context, sqsEvent events.SQSEvent) (ReturnFailures, error) {
 		var batchItemFailures []FailedItems
 
 		for _, record := range sqsEvent.Records {
+			logger := logging.FromContext(ctx).With("messageId", record.MessageId)
+
+			// resolve tenant
+			tenantID := record.MessageAttributes[tenantAttribute].StringValue
+			if tenantID == nil || *tenantID == "" {
+				logger.ErrorContext(
+					ctx,
+					"Message rejected, no tenant attribute",
+					"attribute", tenantAttribute,
+				)
+				batchItemFailures = append(batchItemFailures, FailedItems{
+					ItemIdentifier: record.MessageId,
+				})
+				continue
+			}
+			logger = logger.With("tenant_id", *tenantID)
+			recordCtx := tenant.WithID(ctx, *tenantID)
+
 			// unmarshal and validate
 			user, problems, err := decodeValidateBody[inputUser, models.User](record.Body)
 			if err != nil {
-				logger.Error("Failed to decode validate body", "error", err, "problems", problems)
+				logger.ErrorContext(recordCtx, "Failed to decode validate body", "error", err, "problems", problems)
 				batchItemFailures = append(batchItemFailures, FailedItems{
 					ItemIdentifier: record.MessageId,
 				})
@@ -36,12 +99,14 @@ func HandleCreateUsers(logger *slog.Logger, service userCreator) HandlerFunc {
 			}
 
 			// process
-			if _, err = service.CreateUser(ctx, user); err != nil {
-				logger.Error("Failed to create user", "error", err)
+			if _, err = service.CreateUser(recordCtx, user); err != nil {
+				logger.ErrorContext(recordCtx, "Failed to create user", "error", err)
 				batchItemFailures = append(batchItemFailures, FailedItems{
 					ItemIdentifier: record.MessageId,
 				})
+				continue
 			}
+			metrics.Put(ctx, "UsersCreated", 1, metrics.Count)
 		}
 
 		// return response
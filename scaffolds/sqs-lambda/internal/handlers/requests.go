@@ -8,8 +8,8 @@ import (
 )
 
 type inputUser struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	FirstName string `json:"first_name" log:"sensitive"`
+	LastName  string `json:"last_name" log:"sensitive"`
 	Role      string `json:"role"`
 	UserID    int    `json:"user_id"`
 }
@@ -0,0 +1,136 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestContextHandler(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+
+	tests := map[string]struct {
+		ctx      context.Context
+		expected map[string]any
+	}{
+		"no request": {
+			ctx:      context.Background(),
+			expected: map[string]any{},
+		},
+		"lambda invocation and span": {
+			ctx: trace.ContextWithSpanContext(
+				lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "aws-1"}),
+				trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}),
+			),
+			expected: map[string]any{
+				"aws_request_id": "aws-1",
+				"trace_id":       "4bf92f3577b34da6a3ce929d0e0e4736",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := slog.New(NewContextHandler(slog.NewJSONHandler(&out, nil))).With("service", "users")
+
+			logger.InfoContext(tc.ctx, "test")
+
+			var line map[string]any
+			if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+				t.Fatalf("decoding log line %q: %v", out.String(), err)
+			}
+			for _, key := range []string{"time", "level", "msg", "service"} {
+				assert.Contains(t, line, key)
+				delete(line, key)
+			}
+			assert.Equal(t, tc.expected, line)
+		})
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	first, second := NewRequestID(), NewRequestID()
+
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, second)
+}
+
+func TestNewHandler(t *testing.T) {
+	tests := map[string]struct {
+		options       Options
+		expectedLines []string
+		expectErr     bool
+	}{
+		"json": {
+			options:       Options{Format: "json", Level: slog.LevelInfo},
+			expectedLines: []string{`"msg":"info"`},
+		},
+		"text with source": {
+			options:       Options{Format: "text", Level: slog.LevelDebug, AddSource: true},
+			expectedLines: []string{"msg=debug", "msg=info", "source="},
+		},
+		"unknown format": {
+			options:   Options{Format: "xml"},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			handler, err := NewHandler(&out, tc.options)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			logger := slog.New(handler)
+			logger.Debug("debug")
+			logger.Info("info")
+
+			for _, line := range tc.expectedLines {
+				assert.Contains(t, out.String(), line)
+			}
+			if tc.options.Level == slog.LevelInfo {
+				assert.NotContains(t, out.String(), "debug")
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, slog.Default(), FromContext(context.Background()))
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)).With("tenant_id", "acme")
+	assert.Equal(t, logger, FromContext(WithLogger(context.Background(), logger)))
+}
+
+func TestWithDebug(t *testing.T) {
+	var out bytes.Buffer
+	handler, err := NewHandler(&out, Options{Format: "text", Level: slog.LevelWarn})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logger := slog.New(handler)
+
+	assert.False(t, Debug(context.Background()))
+	logger.DebugContext(context.Background(), "dropped")
+
+	ctx := WithDebug(context.Background())
+	assert.True(t, Debug(ctx))
+	logger.DebugContext(ctx, "debugging")
+
+	assert.NotContains(t, out.String(), "dropped")
+	assert.Contains(t, out.String(), "msg=debugging")
+}
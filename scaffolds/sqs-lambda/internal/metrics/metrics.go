@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Unit is the CloudWatch unit of a metric.
+type Unit string
+
+const (
+	Count        Unit = "Count"
+	Milliseconds Unit = "Milliseconds"
+	None         Unit = "None"
+)
+
+// Emitter writes metrics as CloudWatch Embedded Metric Format (EMF) log lines. CloudWatch Logs
+// extracts the metrics from the lines, so no agent or API call is needed.
+type Emitter struct {
+	mu         sync.Mutex
+	out        io.Writer
+	namespace  string
+	dimensions map[string]string
+	now        func() time.Time
+}
+
+// NewEmitter returns an Emitter writing to out. Metrics are published to the CloudWatch
+// namespace, with the given dimensions, e.g. the function name.
+func NewEmitter(out io.Writer, namespace string, dimensions map[string]string) *Emitter {
+	return &Emitter{
+		out:        out,
+		namespace:  namespace,
+		dimensions: dimensions,
+		now:        time.Now,
+	}
+}
+
+// Recorder collects the metrics of a single invocation. It is safe for concurrent use.
+type Recorder struct {
+	mu         sync.Mutex
+	names      []string
+	units      map[string]Unit
+	values     map[string][]float64
+	properties map[string]any
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		units:      map[string]Unit{},
+		values:     map[string][]float64{},
+		properties: map[string]any{},
+	}
+}
+
+// Put records value for the metric name. A metric can be recorded several times, CloudWatch
+// aggregates all of its values. The unit of the first value is used.
+func (r *Recorder) Put(name string, value float64, unit Unit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.units[name]; !ok {
+		r.names = append(r.names, name)
+		r.units[name] = unit
+	}
+	r.values[name] = append(r.values[name], value)
+}
+
+// Value returns the sum of the values recorded for the metric name, and whether it was recorded.
+func (r *Recorder) Value(name string) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	values, ok := r.values[name]
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum, ok
+}
+
+// SetProperty adds a property to the log line. Properties are searchable in CloudWatch Logs but
+// are not metrics. Keys must not clash with metric names or dimensions.
+func (r *Recorder) SetProperty(key string, value any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.properties[key] = value
+}
+
+// metricDefinition, metricDirective and metadata make up the `_aws` member of an EMF line.
+type (
+	metricDefinition struct {
+		Name string `json:"Name"`
+		Unit Unit   `json:"Unit"`
+	}
+
+	metricDirective struct {
+		Namespace  string             `json:"Namespace"`
+		Dimensions [][]string         `json:"Dimensions"`
+		Metrics    []metricDefinition `json:"Metrics"`
+	}
+
+	metadata struct {
+		Timestamp         int64             `json:"Timestamp"`
+		CloudWatchMetrics []metricDirective `json:"CloudWatchMetrics"`
+	}
+)
+
+// Emit writes the metrics recorded by r as a single EMF line. Nothing is written if r recorded
+// no metrics.
+func (e *Emitter) Emit(r *Recorder) error {
+	r.mu.Lock()
+	if len(r.names) == 0 {
+		r.mu.Unlock()
+		return nil
+	}
+
+	line := make(map[string]any, len(r.properties)+len(e.dimensions)+len(r.names)+1)
+	maps.Copy(line, r.properties)
+	dimensions := make([]string, 0, len(e.dimensions))
+	for key, value := range e.dimensions {
+		line[key] = value
+		dimensions = append(dimensions, key)
+	}
+	slices.Sort(dimensions)
+
+	definitions := make([]metricDefinition, 0, len(r.names))
+	for _, name := range r.names {
+		definitions = append(definitions, metricDefinition{Name: name, Unit: r.units[name]})
+		if values := r.values[name]; len(values) == 1 {
+			line[name] = values[0]
+		} else {
+			line[name] = values
+		}
+	}
+	r.mu.Unlock()
+
+	line["_aws"] = metadata{
+		Timestamp: e.now().UnixMilli(),
+		CloudWatchMetrics: []metricDirective{{
+			Namespace:  e.namespace,
+			Dimensions: [][]string{dimensions},
+			Metrics:    definitions,
+		}},
+	}
+
+	encoded, err := json.Marshal(line)
+	if err != nil {
+		return fmt.Errorf("[in metrics.Emit] failed to encode metrics: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err = e.out.Write(append(encoded, '\n')); err != nil {
+		return fmt.Errorf("[in metrics.Emit] failed to write metrics: %w", err)
+	}
+
+	return nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying r.
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the Recorder carried by ctx, if any.
+func FromContext(ctx context.Context) (*Recorder, bool) {
+	r, ok := ctx.Value(contextKey{}).(*Recorder)
+	return r, ok
+}
+
+// Put records value for the metric name on the Recorder in ctx, so handlers and services can
+// record business metrics. It does nothing if ctx carries no Recorder.
+func Put(ctx context.Context, name string, value float64, unit Unit) {
+	if r, ok := FromContext(ctx); ok {
+		r.Put(name, value, unit)
+	}
+}
+
+// SetProperty adds a property to the log line of the Recorder in ctx. It does nothing if ctx
+// carries no Recorder.
+func SetProperty(ctx context.Context, key string, value any) {
+	if r, ok := FromContext(ctx); ok {
+		r.SetProperty(key, value)
+	}
+}
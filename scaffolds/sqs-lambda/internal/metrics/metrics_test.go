@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmit(t *testing.T) {
+	tests := map[string]struct {
+		record   func(r *Recorder)
+		expected string
+	}{
+		"no metrics": {
+			record:   func(r *Recorder) {},
+			expected: "",
+		},
+		"single values": {
+			record: func(r *Recorder) {
+				r.Put("Invocations", 1, Count)
+				r.Put("Duration", 12.5, Milliseconds)
+			},
+			expected: `{"Duration":12.5,"FunctionName":"list-users","Invocations":1,` +
+				`"_aws":{"Timestamp":1704164645000,"CloudWatchMetrics":[{"Namespace":"Users",` +
+				`"Dimensions":[["FunctionName"]],` +
+				`"Metrics":[{"Name":"Invocations","Unit":"Count"},{"Name":"Duration","Unit":"Milliseconds"}]}]}}` + "\n",
+		},
+		"repeated values and properties": {
+			record: func(r *Recorder) {
+				r.Put("UsersCreated", 1, Count)
+				r.Put("UsersCreated", 1, None)
+				r.SetProperty("batchSize", 2)
+			},
+			expected: `{"FunctionName":"list-users","UsersCreated":[1,1],` +
+				`"_aws":{"Timestamp":1704164645000,"CloudWatchMetrics":[{"Namespace":"Users",` +
+				`"Dimensions":[["FunctionName"]],` +
+				`"Metrics":[{"Name":"UsersCreated","Unit":"Count"}]}]},"batchSize":2}` + "\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			emitter := NewEmitter(&out, "Users", map[string]string{"FunctionName": "list-users"})
+			emitter.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+			recorder := NewRecorder()
+			tc.record(recorder)
+
+			assert.NoError(t, emitter.Emit(recorder))
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	// without a recorder, metrics are dropped
+	Put(context.Background(), "UsersCreated", 1, Count)
+	SetProperty(context.Background(), "batchSize", 1)
+
+	recorder := NewRecorder()
+	ctx := NewContext(context.Background(), recorder)
+	Put(ctx, "UsersCreated", 1, Count)
+	Put(ctx, "UsersCreated", 2, Count)
+
+	value, ok := recorder.Value("UsersCreated")
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, value)
+
+	_, ok = recorder.Value("UsersUpdated")
+	assert.False(t, ok)
+}
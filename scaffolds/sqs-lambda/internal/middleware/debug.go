@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/logging"
+)
+
+// DebugLogging writes records of every level for invocations flagged by enabled, see
+// logging.WithDebug, so a single batch can be debugged without redeploying the function with a
+// lower LOG_LEVEL.
+func DebugLogging[E any, R any](enabled func(ctx context.Context, event E) bool) LambdaMiddlewareT[E, R] {
+	return func(next HandlerFuncT[E, R]) HandlerFuncT[E, R] {
+		return func(ctx context.Context, event E) (R, error) {
+			if enabled(ctx, event) {
+				logging.FromContext(ctx).InfoContext(ctx, "Debug logging enabled for invocation")
+				ctx = logging.WithDebug(ctx)
+			}
+			return next(ctx, event)
+		}
+	}
+}
+
+// DebugBodies logs the body of every message at debug level under `body`, so the redacting handler
+// masks their sensitive fields before they are written. Bodies are only logged while the logger
+// writes debug records, e.g. for invocations flagged by DebugLogging, so it must run after it.
+func DebugBodies[R any]() LambdaMiddlewareT[events.SQSEvent, R] {
+	return func(next HandlerFuncT[events.SQSEvent, R]) HandlerFuncT[events.SQSEvent, R] {
+		return func(ctx context.Context, event events.SQSEvent) (R, error) {
+			logger := logging.FromContext(ctx)
+			if logger.Enabled(ctx, slog.LevelDebug) {
+				for _, record := range event.Records {
+					logger.DebugContext(ctx, "Message body", "messageId", record.MessageId, "body", record.Body)
+				}
+			}
+			return next(ctx, event)
+		}
+	}
+}
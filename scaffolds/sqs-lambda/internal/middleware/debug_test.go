@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugBodies(t *testing.T) {
+	tests := map[string]struct {
+		debug         bool
+		expectedLines []map[string]any
+	}{
+		"debug": {
+			debug: true,
+			expectedLines: []map[string]any{
+				{"msg": "Message body", "messageId": "m-1", "body": `{"first_name":"[REDACTED]","id":1}`},
+			},
+		},
+		"not debug": {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			handler, err := logging.NewHandler(&out, logging.Options{Format: "json", Level: slog.LevelInfo})
+			assert.NoError(t, err)
+			logger := slog.New(redact.NewHandler(handler, redact.NewPolicy([]string{"first_name"}, nil)))
+
+			ctx := logging.WithLogger(context.Background(), logger)
+			if tc.debug {
+				ctx = logging.WithDebug(ctx)
+			}
+			var received events.SQSEvent
+			event := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "m-1", Body: `{"id":1,"first_name":"Ada"}`}}}
+			_, err = DebugBodies[string]()(func(_ context.Context, event events.SQSEvent) (string, error) {
+				received = event
+				return "", nil
+			})(ctx, event)
+
+			assert.NoError(t, err)
+			assert.Equal(t, event, received)
+
+			var lines []map[string]any
+			decoder := json.NewDecoder(&out)
+			for decoder.More() {
+				var line map[string]any
+				if err := decoder.Decode(&line); err != nil {
+					t.Fatalf("decoding log line: %v", err)
+				}
+				lines = append(lines, map[string]any{"msg": line["msg"], "messageId": line["messageId"], "body": line["body"]})
+			}
+			assert.Equal(t, tc.expectedLines, lines)
+		})
+	}
+}
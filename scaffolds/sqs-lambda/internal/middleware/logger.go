@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/logging"
+)
+
+// Logger carries logger in the context of every invocation, so handlers and middleware log with
+// logging.FromContext instead of being given a logger. Handlers can derive loggers with more
+// attributes from it, e.g. HandleCreateUsers logs each record with its message and tenant IDs.
+func Logger[E any, R any](logger *slog.Logger) LambdaMiddlewareT[E, R] {
+	return func(next HandlerFuncT[E, R]) HandlerFuncT[E, R] {
+		return func(ctx context.Context, event E) (R, error) {
+			return next(logging.WithLogger(ctx, logger), event)
+		}
+	}
+}
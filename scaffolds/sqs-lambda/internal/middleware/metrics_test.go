@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/metrics"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/reporting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	// failed counts the failed records reported by a handler
+	failed := func(recorder *metrics.Recorder, event events.SQSEvent, response int) {
+		recorder.Put("RecordsFailed", float64(response), metrics.Count)
+	}
+
+	tests := map[string]struct {
+		handler     HandlerFuncT[events.SQSEvent, int]
+		recover     bool
+		expectPanic bool
+		expected    map[string]any
+	}{
+		"handled batch": {
+			handler: func(ctx context.Context, _ events.SQSEvent) (int, error) {
+				metrics.Put(ctx, "UsersCreated", 1, metrics.Count)
+				metrics.Put(ctx, "UsersCreated", 1, metrics.Count)
+				return 1, nil
+			},
+			expected: map[string]any{
+				"Invocations": 1.0, "Errors": 0.0, "Panics": 0.0, "RecordsFailed": 1.0,
+				"UsersCreated": []any{1.0, 1.0},
+			},
+		},
+		"handler error": {
+			handler: func(ctx context.Context, _ events.SQSEvent) (int, error) {
+				return 0, errors.New("test")
+			},
+			expected: map[string]any{
+				"Invocations": 1.0, "Errors": 1.0, "Panics": 0.0, "RecordsFailed": 0.0,
+			},
+		},
+		"recovered panic": {
+			handler: func(ctx context.Context, _ events.SQSEvent) (int, error) {
+				panic("test")
+			},
+			recover: true,
+			expected: map[string]any{
+				"Invocations": 1.0, "Errors": 0.0, "Panics": 1.0, "RecordsFailed": 2.0,
+			},
+		},
+		"unrecovered panic": {
+			handler: func(ctx context.Context, _ events.SQSEvent) (int, error) {
+				panic("test")
+			},
+			expectPanic: true,
+			expected: map[string]any{
+				"Invocations": 1.0, "Errors": 0.0, "Panics": 1.0, "RecordsFailed": nil,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			emitter := metrics.NewEmitter(&out, "Users", map[string]string{"FunctionName": "sqs"})
+
+			middlewares := []LambdaMiddlewareT[events.SQSEvent, int]{Metrics(emitter, failed)}
+			if tc.recover {
+				middlewares = append(middlewares, Recovery(reporting.NewLogReporter(), func(events.SQSEvent) int { return 2 }))
+			}
+			handler := AddToHandler(tc.handler, middlewares...)
+
+			invoke := func() { _, _ = handler(context.Background(), events.SQSEvent{}) }
+			if tc.expectPanic {
+				assert.Panics(t, invoke)
+			} else {
+				assert.NotPanics(t, invoke)
+			}
+
+			var line map[string]any
+			if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+				t.Fatalf("decoding metrics %q: %v", out.String(), err)
+			}
+			for metric, expected := range tc.expected {
+				assert.Equal(t, expected, line[metric], metric)
+			}
+			assert.Equal(t, 1.0, line["ColdStart"])
+			assert.Contains(t, line, "Duration")
+			assert.Equal(t, "sqs", line["FunctionName"])
+		})
+	}
+}
+
+func TestMetricsColdStart(t *testing.T) {
+	var out bytes.Buffer
+	emitter := metrics.NewEmitter(&out, "Users", nil)
+	handler := Metrics[events.SQSEvent, int](emitter, nil)(func(ctx context.Context, _ events.SQSEvent) (int, error) {
+		return 0, nil
+	})
+
+	var coldStarts []any
+	for range 3 {
+		out.Reset()
+		_, _ = handler(context.Background(), events.SQSEvent{})
+
+		var line map[string]any
+		if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+			t.Fatalf("decoding metrics %q: %v", out.String(), err)
+		}
+		coldStarts = append(coldStarts, line["ColdStart"])
+	}
+
+	assert.Equal(t, []any{1.0, 0.0, 0.0}, coldStarts)
+}
@@ -2,31 +2,24 @@ package middleware
 
 import (
 	"context"
-	"log/slog"
-)
-
-func Recovery[E any, R any](logger *slog.Logger) LambdaMiddlewareT[E, R] {
-	return func(next HandlerFuncT[E, R]) HandlerFuncT[E, R] {
-		return func(ctx context.Context, event E) (response R, err error) {
-			defer func() {
-				if errAny := recover(); errAny != nil {
-					logger.Error("Recovered from panic", "err", errAny)
-				}
-			}()
 
-			return next(ctx, event)
-		}
-	}
-}
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/metrics"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/reporting"
+)
 
-func RecoveryReturn[E any, R any](logger *slog.Logger, returnFn func() R) LambdaMiddlewareT[E, R] {
+// Recovery recovers panics in later middleware and the handler, reports them to reporter with the
+// stack of the panicking goroutine, and returns the response recovered returns for the event. For
+// SQS batches, recovered must report every record of the event as failed, so none are deleted from
+// the queue, see handlers.FailBatch.
+func Recovery[E any, R any](reporter reporting.ErrorReporter, recovered func(event E) R) LambdaMiddlewareT[E, R] {
 	return func(next HandlerFuncT[E, R]) HandlerFuncT[E, R] {
 		return func(ctx context.Context, event E) (response R, err error) {
 			defer func() {
-				if errAny := recover(); errAny != nil {
-					logger.Error("Recovered from panic", "err", errAny)
+				if v := recover(); v != nil {
+					reporter.Report(ctx, reporting.Recovered(v, nil))
+					metrics.Put(ctx, panicsMetric, 1, metrics.Count)
 
-					response = returnFn()
+					response = recovered(event)
 				}
 			}()
 
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/reporting"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingReporter keeps the events reported to it.
+type recordingReporter struct {
+	events []reporting.Event
+}
+
+func (r *recordingReporter) Report(_ context.Context, event reporting.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestRecovery(t *testing.T) {
+	// failed returns the message IDs of every record of the event
+	failed := func(event events.SQSEvent) []string {
+		var IDs []string
+		for _, record := range event.Records {
+			IDs = append(IDs, record.MessageId)
+		}
+		return IDs
+	}
+	event := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "1"}, {MessageId: "2"}}}
+
+	tests := map[string]struct {
+		handler          HandlerFuncT[events.SQSEvent, []string]
+		expectedResponse []string
+		expectedErr      string
+	}{
+		"handler does not panic": {
+			handler: func(ctx context.Context, _ events.SQSEvent) ([]string, error) {
+				return nil, nil
+			},
+		},
+		"handler panics": {
+			handler: func(ctx context.Context, _ events.SQSEvent) ([]string, error) {
+				panic("something went wrong")
+			},
+			expectedResponse: []string{"1", "2"},
+			expectedErr:      "panic: something went wrong",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			reporter := &recordingReporter{}
+			handler := AddToHandler(tc.handler, Recovery(reporter, failed))
+
+			response, err := handler(context.Background(), event)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedResponse, response)
+			if tc.expectedErr == "" {
+				assert.Empty(t, reporter.events)
+				return
+			}
+			if len(reporter.events) != 1 {
+				t.Fatalf("expected one reported event, got %d", len(reporter.events))
+			}
+			assert.EqualError(t, reporter.events[0].Err, tc.expectedErr)
+			assert.Contains(t, reporting.FormatStack(reporter.events[0].Stack), "middleware.TestRecovery")
+		})
+	}
+}
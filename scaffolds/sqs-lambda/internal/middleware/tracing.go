@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/logging"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/telemetry"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing calls next once per record of the SQS event, each in its own consumer span, and
+// combines the responses with merge. A span continues the trace of the producer when the message
+// carries a W3C `traceparent` message attribute or an `AWSTraceHeader` system attribute. If next
+// returns an error for a record, the error is recorded on its span and the record is reported as
+// failed with the response of failed, so the records that succeeded are not delivered again. The
+// execution environment is frozen between invocations, so the spans of provider are flushed
+// before the response is returned.
+func Tracing[R any](provider *sdktrace.TracerProvider, merge func(R, R) R, failed func(events.SQSEvent) R) LambdaMiddlewareT[events.SQSEvent, R] {
+	tracer := provider.Tracer(telemetry.TracerName)
+	propagator := telemetry.Propagator()
+
+	return func(next HandlerFuncT[events.SQSEvent, R]) HandlerFuncT[events.SQSEvent, R] {
+		return func(ctx context.Context, event events.SQSEvent) (response R, _ error) {
+			defer func() {
+				_ = provider.ForceFlush(context.WithoutCancel(ctx))
+			}()
+
+			for _, record := range event.Records {
+				recordCtx := propagator.Extract(ctx, recordCarrier(record))
+
+				queue := record.EventSourceARN[strings.LastIndex(record.EventSourceARN, ":")+1:]
+				recordCtx, span := tracer.Start(recordCtx, queue+" process",
+					trace.WithSpanKind(trace.SpanKindConsumer),
+					trace.WithAttributes(
+						semconv.MessagingSystemAWSSqs,
+						semconv.MessagingOperationTypeDeliver,
+						semconv.MessagingDestinationName(queue),
+						semconv.MessagingMessageID(record.MessageId),
+					),
+				)
+
+				recordEvent := events.SQSEvent{Records: []events.SQSMessage{record}}
+				recordResponse, err := processRecord(recordCtx, span, next, recordEvent)
+				if err != nil {
+					logging.FromContext(recordCtx).ErrorContext(recordCtx, "Failed to process record",
+						"messageId", record.MessageId, "err", err)
+					recordResponse = failed(recordEvent)
+				}
+				response = merge(response, recordResponse)
+			}
+
+			return response, nil
+		}
+	}
+}
+
+// processRecord calls next with the event of a single record and ends span. If next panics, span is ended as failed
+// before the panic continues to Recovery.
+func processRecord[R any](ctx context.Context, span trace.Span, next HandlerFuncT[events.SQSEvent, R], event events.SQSEvent) (response R, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			telemetry.End(span, fmt.Errorf("panic: %v", v))
+			panic(v)
+		}
+		telemetry.End(span, err)
+	}()
+
+	return next(ctx, event)
+}
+
+// recordCarrier returns the trace context headers of record.
+func recordCarrier(record events.SQSMessage) propagation.HeaderCarrier {
+	headers := make(http.Header, len(record.MessageAttributes)+1)
+	for key, attribute := range record.MessageAttributes {
+		if attribute.StringValue != nil {
+			headers.Set(key, *attribute.StringValue)
+		}
+	}
+	if header, ok := record.Attributes["AWSTraceHeader"]; ok {
+		headers.Set("X-Amzn-Trace-Id", header)
+	}
+
+	return propagation.HeaderCarrier(headers)
+}
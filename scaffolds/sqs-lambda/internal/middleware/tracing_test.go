@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	xrayHeader := "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"
+	records := []events.SQSMessage{
+		{
+			MessageId:      "message-1",
+			EventSourceARN: "arn:aws:sqs:us-east-1:123456789012:users",
+			MessageAttributes: map[string]events.SQSMessageAttribute{
+				"traceparent": {StringValue: &traceparent, DataType: "String"},
+			},
+		},
+		{
+			MessageId:      "message-2",
+			EventSourceARN: "arn:aws:sqs:us-east-1:123456789012:users",
+			Attributes:     map[string]string{"AWSTraceHeader": xrayHeader},
+		},
+		{
+			MessageId:      "message-3",
+			EventSourceARN: "arn:aws:sqs:us-east-1:123456789012:users",
+		},
+	}
+
+	tests := map[string]struct {
+		failOn           string
+		expectedResponse []string
+		expectedParents  []string
+	}{
+		"span per record": {
+			expectedResponse: []string{"message-1", "message-2", "message-3"},
+			expectedParents:  []string{"4bf92f3577b34da6a3ce929d0e0e4736", "5759e988bd862e3fe1be46a994272793", ""},
+		},
+		"handler error": {
+			failOn:           "message-2",
+			expectedResponse: []string{"message-1", "failed message-2", "message-3"},
+			expectedParents:  []string{"4bf92f3577b34da6a3ce929d0e0e4736", "5759e988bd862e3fe1be46a994272793", ""},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			// the handler responds with the IDs of the messages it was called with, failed records
+			// are prefixed with failed
+			failed := func(event events.SQSEvent) []string { return []string{"failed " + event.Records[0].MessageId} }
+			handler := Tracing(provider, func(a, b []string) []string { return append(a, b...) }, failed)(
+				func(ctx context.Context, event events.SQSEvent) ([]string, error) {
+					assert.Len(t, event.Records, 1)
+					assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+					if event.Records[0].MessageId == tc.failOn {
+						return nil, errors.New("test")
+					}
+					return []string{event.Records[0].MessageId}, nil
+				},
+			)
+
+			response, err := handler(context.Background(), events.SQSEvent{Records: records})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedResponse, response)
+
+			spans := recorder.Ended()
+			if !assert.Len(t, spans, len(tc.expectedParents)) {
+				return
+			}
+			for i, span := range spans {
+				assert.Equal(t, "users process", span.Name())
+				assert.Equal(t, trace.SpanKindConsumer, span.SpanKind())
+				if tc.expectedParents[i] != "" {
+					assert.Equal(t, tc.expectedParents[i], span.SpanContext().TraceID().String())
+					assert.True(t, span.Parent().IsRemote())
+				} else {
+					assert.False(t, span.Parent().IsValid())
+				}
+				if records[i].MessageId == tc.failOn {
+					assert.Equal(t, codes.Error, span.Status().Code)
+				} else {
+					assert.Equal(t, codes.Unset, span.Status().Code)
+				}
+			}
+		})
+	}
+}
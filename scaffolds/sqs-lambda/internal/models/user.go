@@ -2,8 +2,8 @@ package models
 
 type User struct {
 	ID        uint
-	FirstName string
-	LastName  string
+	FirstName string `log:"sensitive" encrypt:"first_name"`
+	LastName  string `log:"sensitive" encrypt:"last_name"`
 	Role      string
 	UserID    uint
 }
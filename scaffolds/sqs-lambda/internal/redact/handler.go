@@ -0,0 +1,49 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler is a slog.Handler that masks sensitive attributes, see Policy, before passing records to
+// the handler it wraps.
+type Handler struct {
+	next   slog.Handler
+	policy *Policy
+}
+
+// NewHandler returns a Handler redacting records with policy before they reach next.
+func NewHandler(next slog.Handler, policy *Policy) *Handler {
+	return &Handler{next: next, policy: policy}
+}
+
+// Enabled reports whether the wrapped handler handles records at level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle redacts the attributes of record and passes it on.
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.policy.Attr(a))
+		return true
+	})
+
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs returns a Handler whose attributes, redacted, are added to every record.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.policy.Attr(a)
+	}
+
+	return &Handler{next: h.next.WithAttrs(redacted), policy: h.policy}
+}
+
+// WithGroup returns a Handler that qualifies later attributes with name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), policy: h.policy}
+}
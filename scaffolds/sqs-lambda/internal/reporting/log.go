@@ -0,0 +1,31 @@
+package reporting
+
+import (
+	"context"
+
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/logging"
+)
+
+// LogReporter logs events at the error level with their stack, using the logger carried by the
+// context of the event, see logging.FromContext.
+type LogReporter struct{}
+
+// NewLogReporter returns a LogReporter.
+func NewLogReporter() LogReporter {
+	return LogReporter{}
+}
+
+// Report logs event.
+func (LogReporter) Report(ctx context.Context, event Event) {
+	attrs := make([]any, 0, 4+2*len(event.Tags))
+	attrs = append(attrs, "err", event.Err, "stack", FormatStack(event.Stack))
+	for key, value := range event.Tags {
+		attrs = append(attrs, key, value)
+	}
+
+	msg := "Error reported"
+	if event.Panic {
+		msg = "Recovered from panic"
+	}
+	logging.FromContext(ctx).ErrorContext(ctx, msg, attrs...)
+}
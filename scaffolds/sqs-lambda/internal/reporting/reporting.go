@@ -0,0 +1,97 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// maxFrames bounds the number of stack frames captured for an event.
+const maxFrames = 64
+
+// ErrorReporter sends errors, e.g. panics recovered by the Recovery middleware, to wherever they
+// are tracked. Report must not panic and should return quickly, as it runs on the request path.
+type ErrorReporter interface {
+	Report(ctx context.Context, event Event)
+}
+
+// Event is an error reported to an ErrorReporter.
+type Event struct {
+	Err error
+	// Panic reports whether Err was recovered from a panic.
+	Panic bool
+	// Stack is the stack of the goroutine that failed, innermost frame first.
+	Stack []runtime.Frame
+	// Tags describe what was being handled, e.g. the message ID of the record. Reporters add
+	// the request ID, trace ID and tenant carried by the context.
+	Tags map[string]string
+}
+
+// Recovered returns an Event for the value v recovered from a panic, with the stack of the
+// panicking goroutine from the function that panicked. It must be called by the deferred function
+// that recovered v, which is left out of the stack.
+func Recovered(v any, tags map[string]string) Event {
+	err, ok := v.(error)
+	if ok {
+		err = fmt.Errorf("panic: %w", err)
+	} else {
+		err = fmt.Errorf("panic: %v", v)
+	}
+
+	return Event{Err: err, Panic: true, Stack: Stack(2), Tags: tags}
+}
+
+// Stack returns the stack of the calling goroutine, skipping skip frames above the caller of
+// Stack. Frames of the Go runtime, like those of the panic itself, are left out.
+func Stack(skip int) []runtime.Frame {
+	pcs := make([]uintptr, maxFrames)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []runtime.Frame
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			stack = append(stack, frame)
+		}
+		if !more {
+			return stack
+		}
+	}
+}
+
+// FormatStack formats stack like the stack traces the Go runtime prints.
+func FormatStack(stack []runtime.Frame) string {
+	var b strings.Builder
+	for _, frame := range stack {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return b.String()
+}
+
+// Multi returns an ErrorReporter that reports every event to each of reporters, in order.
+func Multi(reporters ...ErrorReporter) ErrorReporter {
+	return multiReporter(reporters)
+}
+
+type multiReporter []ErrorReporter
+
+func (m multiReporter) Report(ctx context.Context, event Event) {
+	for _, reporter := range m {
+		reporter.Report(ctx, event)
+	}
+}
+
+// errorType returns the type of the innermost error wrapped by err, e.g. `*pq.Error`, to group
+// events by.
+func errorType(err error) string {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return fmt.Sprintf("%T", err)
+		}
+		err = next
+	}
+}
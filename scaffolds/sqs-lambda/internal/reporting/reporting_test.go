@@ -0,0 +1,67 @@
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+// panicking panics with v and returns the event recovered from it.
+func panicking(v any) (event Event) {
+	defer func() {
+		event = Recovered(recover(), map[string]string{"message_id": "1"})
+	}()
+	panic(v)
+}
+
+func TestRecovered(t *testing.T) {
+	cause := errors.New("db down")
+
+	tests := map[string]struct {
+		value       any
+		expectedErr string
+	}{
+		"string": {
+			value:       "something went wrong",
+			expectedErr: "panic: something went wrong",
+		},
+		"error": {
+			value:       cause,
+			expectedErr: "panic: db down",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			event := panicking(tc.value)
+
+			assert.EqualError(t, event.Err, tc.expectedErr)
+			assert.True(t, event.Panic)
+			assert.Equal(t, map[string]string{"message_id": "1"}, event.Tags)
+			if len(event.Stack) == 0 {
+				t.Fatal("expected a stack")
+			}
+			// the innermost frame is the function that panicked, not the runtime
+			assert.Equal(t, "github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/reporting.panicking", event.Stack[0].Function)
+		})
+	}
+
+	assert.ErrorIs(t, panicking(cause).Err, cause)
+}
+
+func TestLogReporter(t *testing.T) {
+	var out bytes.Buffer
+	ctx := logging.WithLogger(context.Background(), slog.New(slog.NewTextHandler(&out, nil)))
+
+	Multi(NewLogReporter()).Report(ctx, panicking("something went wrong"))
+
+	assert.Contains(t, out.String(), `msg="Recovered from panic"`)
+	assert.Contains(t, out.String(), `err="panic: something went wrong"`)
+	assert.Contains(t, out.String(), `message_id=1`)
+	assert.Contains(t, out.String(), `stack="github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/reporting.panicking`)
+}
@@ -5,32 +5,54 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/encryption"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/telemetry"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/tenant"
 )
 
 type UserService struct {
 	database *sql.DB
+	envelope *encryption.Envelope
 }
 
-// NewUserService returns a new UserService struct.
-func NewUserService(db *sql.DB) *UserService {
+// NewUserService returns a new UserService struct. The fields of models.User tagged `encrypt` are
+// stored encrypted with envelope.
+func NewUserService(db *sql.DB, envelope *encryption.Envelope) *UserService {
 	return &UserService{
 		database: db,
+		envelope: envelope,
 	}
 }
 
-// CreateUser creates am User objects in the database.
-func (s UserService) CreateUser(ctx context.Context, user models.User) (int, error) {
+// CreateUser creates am User objects in the database for the tenant in ctx.
+func (s UserService) CreateUser(ctx context.Context, user models.User) (_ int, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "UserService.CreateUser")
+	defer func() { telemetry.End(span, err) }()
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return 0, fmt.Errorf("[in services.CreateUser]: %w", tenant.ErrMissing)
+	}
+
+	stored := user
+	if err = s.envelope.EncryptFields(ctx, &stored, tenantID); err != nil {
+		return 0, fmt.Errorf("[in services.CreateUser] failed to encrypt user: %w", err)
+	}
+
+	// last names are stored encrypted, the blind index keeps them searchable by exact match
 	var ID int
-	err := s.database.QueryRowContext(
+	err = s.database.QueryRowContext(
 		ctx,
 		`
-		INSERT INTO "users" ("first_name", "last_name", "role", "user_id")
-			VALUES ($1, $2, $3, $4)
+		INSERT INTO "users" ("tenant_id", "first_name", "last_name", "last_name_index", "role", "user_id")
+			VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING "id"
 		`,
-		user.FirstName,
-		user.LastName,
+		tenantID,
+		stored.FirstName,
+		stored.LastName,
+		s.envelope.BlindIndex(user.LastName, tenantID+"/last_name"),
 		user.Role,
 		user.UserID,
 	).Scan(&ID)
@@ -1,24 +1,30 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"database/sql/driver"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"regexp"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/encryption"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/models"
+	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/tenant"
 	"github.com/captechconsulting/go-microservice-templates/sqs-lambda/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type testSuit struct {
 	suite.Suite
-	service *UserService
-	dbMock  sqlmock.Sqlmock
+	service  *UserService
+	envelope *encryption.Envelope
+	dbMock   sqlmock.Sqlmock
 }
 
 func TestTestSuit(t *testing.T) {
@@ -29,114 +35,95 @@ func (s *testSuit) SetupSuite() {
 	db, mock, err := sqlmock.New()
 	assert.NoError(s.T(), err)
 
+	keys, err := encryption.NewLocalKeyProvider("key-1", map[string][]byte{"key-1": bytes.Repeat([]byte{1}, 32)})
+	assert.NoError(s.T(), err)
+	s.envelope, err = encryption.NewEnvelope(keys, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32)))
+	assert.NoError(s.T(), err)
+
 	s.dbMock = mock
-	s.service = NewUserService(db)
+	s.service = NewUserService(db, s.envelope)
 }
 
-func (s *testSuit) TearDownSuite() {
-	_ = s.service.database.Close()
+// decryptsTo matches a query argument that decrypts to value in scope.
+type decryptsTo struct {
+	envelope *encryption.Envelope
+	scope    string
+	value    string
 }
 
-func (s *testSuit) TestListUsers() {
-	t := s.T()
-
-	users := []models.User{
-		{ID: 1, FirstName: "John", LastName: "Doe", Role: "Admin", UserID: 1001},
-		{ID: 2, FirstName: "Jane", LastName: "Smith", Role: "User", UserID: 1002},
-	}
-
-	testCases := map[string]struct {
-		mockReturn     *sqlmock.Rows
-		mockReturnErr  error
-		expectedReturn []models.User
-		expectedError  error
-	}{
-		"Return slice of users": {
-			mockReturn:     testutil.MustStructsToRows(users),
-			mockReturnErr:  nil,
-			expectedReturn: users,
-			expectedError:  nil,
-		},
-		"Error getting users": {
-			mockReturn:     &sqlmock.Rows{},
-			mockReturnErr:  errors.New("test"),
-			expectedReturn: []models.User{},
-			expectedError:  fmt.Errorf("[in services.ListUsers] failed to get users: %w", errors.New("test")),
-		},
+func (d decryptsTo) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	if !ok || s == d.value {
+		return false
 	}
-	for name, tc := range testCases {
-		t.Run(name, func(t *testing.T) {
-			exp := `SELECT * FROM "users"`
-			s.dbMock.
-				ExpectQuery(regexp.QuoteMeta(exp)).
-				WillReturnRows(tc.mockReturn).
-				WillReturnError(tc.mockReturnErr)
-
-			actualReturn, err := s.service.ListUsers(context.Background())
-
-			assert.Equal(t, tc.expectedError, err, "errors did not match")
-			assert.Equal(t, tc.expectedReturn, actualReturn, "returned data does not match")
+	decrypted, err := d.envelope.Decrypt(context.Background(), s, d.scope)
+	return err == nil && decrypted == d.value
+}
 
-			err = s.dbMock.ExpectationsWereMet()
-			assert.NoError(t, err)
-		})
-	}
+func (s *testSuit) TearDownSuite() {
+	_ = s.service.database.Close()
 }
 
-func (s *testSuit) TestUpdateUser() {
+func (s *testSuit) TestCreateUser() {
 	t := s.T()
 
-	userIn := models.User{ID: 0, FirstName: "John", LastName: "Doe", Role: "Admin", UserID: 1001}
-	userOut := models.User{ID: 1, FirstName: "John", LastName: "Doe", Role: "Admin", UserID: 1001}
+	user := models.User{ID: 0, FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
+
+	// names are stored encrypted, the last name also by blind index
+	firstName := decryptsTo{envelope: s.envelope, scope: "tenant-a/first_name", value: user.FirstName}
+	lastName := decryptsTo{envelope: s.envelope, scope: "tenant-a/last_name", value: user.LastName}
+	lastNameIndex := s.envelope.BlindIndex(user.LastName, "tenant-a/last_name")
 
 	testCases := map[string]struct {
+		mockCalled     bool
 		mockInputArgs  []driver.Value
-		mockReturn     driver.Result
+		mockReturn     *sqlmock.Rows
 		mockReturnErr  error
-		inputID        int
-		inputUser      models.User
-		expectedReturn models.User
+		ctx            context.Context
+		expectedReturn int
 		expectedError  error
 	}{
-		"user updated by ID": {
-			mockInputArgs:  []driver.Value{userIn.FirstName, userIn.LastName, userIn.Role, userIn.UserID, int(userOut.ID)},
-			mockReturn:     sqlmock.NewResult(1, 1),
+		"user created": {
+			mockCalled:     true,
+			mockInputArgs:  []driver.Value{"tenant-a", firstName, lastName, lastNameIndex, user.Role, user.UserID},
+			mockReturn:     sqlmock.NewRows([]string{"id"}).AddRow(1),
 			mockReturnErr:  nil,
-			inputID:        int(userOut.ID),
-			inputUser:      userIn,
-			expectedReturn: userOut,
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			expectedReturn: 1,
 			expectedError:  nil,
 		},
-		"Error updating user": {
-			mockInputArgs:  []driver.Value{userIn.FirstName, userIn.LastName, userIn.Role, userIn.UserID, 0},
-			mockReturn:     nil,
+		"Error creating user": {
+			mockCalled:     true,
+			mockInputArgs:  []driver.Value{"tenant-a", firstName, lastName, lastNameIndex, user.Role, user.UserID},
+			mockReturn:     sqlmock.NewRows([]string{"id"}),
 			mockReturnErr:  errors.New("test"),
-			inputID:        0,
-			inputUser:      userIn,
-			expectedReturn: models.User{},
-			expectedError:  fmt.Errorf("[in services.UpdateUser] failed to update user: %w", errors.New("test")),
+			ctx:            tenant.WithID(context.Background(), "tenant-a"),
+			expectedReturn: 0,
+			expectedError:  fmt.Errorf("[in services.CreateUser]: %w", errors.New("test")),
+		},
+		"Missing tenant": {
+			mockCalled:     false,
+			ctx:            context.Background(),
+			expectedReturn: 0,
+			expectedError:  fmt.Errorf("[in services.CreateUser]: %w", tenant.ErrMissing),
 		},
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
 			exp := `
-				UPDATE
-					"users"
-				SET
-					"first_name" = $1,
-					"last_name" = $2,
-					"role" = $3,
-					"user_id" = $4
-				WHERE
-					"id" = $5
+				INSERT INTO "users" ("tenant_id", "first_name", "last_name", "last_name_index", "role", "user_id")
+					VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING "id"
 			`
-			s.dbMock.
-				ExpectExec(regexp.QuoteMeta(exp)).
-				WithArgs(tc.mockInputArgs...).
-				WillReturnResult(tc.mockReturn).
-				WillReturnError(tc.mockReturnErr)
+			if tc.mockCalled {
+				s.dbMock.
+					ExpectQuery(regexp.QuoteMeta(exp)).
+					WithArgs(tc.mockInputArgs...).
+					WillReturnRows(tc.mockReturn).
+					WillReturnError(tc.mockReturnErr)
+			}
 
-			actualReturn, err := s.service.UpdateUser(context.Background(), tc.inputID, tc.inputUser)
+			actualReturn, err := s.service.CreateUser(tc.ctx, user)
 
 			assert.Equal(t, tc.expectedError, err, "errors did not match")
 			assert.Equal(t, tc.expectedReturn, actualReturn, "returned data does not match")
@@ -146,3 +133,27 @@ func (s *testSuit) TestUpdateUser() {
 		})
 	}
 }
+
+func (s *testSuit) TestUserSpans() {
+	t := s.T()
+	recorder := testutil.NewSpanRecorder(t)
+
+	user := models.User{FirstName: "John", LastName: "Doe", Role: "Customer", UserID: 1001}
+	s.dbMock.
+		ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	_, err := s.service.CreateUser(tenant.WithID(context.Background(), "tenant-a"), user)
+	assert.NoError(t, err)
+	_, err = s.service.CreateUser(context.Background(), user)
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "UserService.CreateUser", spans[0].Name())
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+		assert.Equal(t, "UserService.CreateUser", spans[1].Name())
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+	}
+	assert.NoError(t, s.dbMock.ExpectationsWereMet())
+}
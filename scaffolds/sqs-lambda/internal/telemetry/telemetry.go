@@ -0,0 +1,80 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of the spans created by this application.
+const TracerName = "github.com/captechconsulting/go-microservice-templates/sqs-lambda"
+
+// NewTracerProvider returns a TracerProvider exporting spans with exporter: `otlp`, configured by
+// the standard `OTEL_EXPORTER_OTLP_*` environment variables, `stdout` or `none`. The provider and
+// Propagator are installed globally, so Tracer and instrumented libraries use them. The provider
+// must be shut down to flush spans that have not been exported yet.
+func NewTracerProvider(ctx context.Context, exporter string, serviceName string) (*sdktrace.TracerProvider, error) {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	}
+
+	switch exporter {
+	case "otlp":
+		spanExporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("[in telemetry.NewTracerProvider] failed to create OTLP exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(spanExporter))
+	case "stdout":
+		spanExporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("[in telemetry.NewTracerProvider] failed to create stdout exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(spanExporter))
+	case "none":
+		// spans are still created, so trace context is propagated to downstream services
+	default:
+		return nil, fmt.Errorf("[in telemetry.NewTracerProvider] unknown exporter %q", exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(Propagator())
+
+	return provider, nil
+}
+
+// Propagator returns the propagator trace context is read from and written to headers and message
+// attributes with: W3C trace context and baggage, and the AWS X-Ray trace header.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+		xray.Propagator{},
+	)
+}
+
+// Tracer returns the tracer of the global TracerProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}